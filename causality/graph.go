@@ -0,0 +1,318 @@
+package causality
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"slices"
+
+	nostr "github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrCycle          = errors.New("causality: cycle detected")
+	ErrMissingParents = errors.New("causality: missing parents")
+)
+
+// Parents returns the ids referenced by the "parent" tags of an event, in tag order and
+// without duplicates. A single tag may list several parents.
+func Parents(evt *nostr.Event) []string {
+	var parents []string
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || tag[0] != "parent" {
+			continue
+		}
+		for _, id := range tag[1:] {
+			if id != "" && !slices.Contains(parents, id) {
+				parents = append(parents, id)
+			}
+		}
+	}
+	return parents
+}
+
+// Graph is a DAG of events linked by their "parent" tags. Parents may be inserted after
+// their children, links to ids that are not in the graph yet are reported by Missing.
+type Graph struct {
+	events   map[string]*nostr.Event
+	parents  map[string][]string
+	children map[string][]string
+}
+
+func NewGraph() *Graph {
+	return &Graph{
+		events:   make(map[string]*nostr.Event),
+		parents:  make(map[string][]string),
+		children: make(map[string][]string),
+	}
+}
+
+// Add inserts events into the graph and returns how many of them were not there already
+func (g *Graph) Add(events ...*nostr.Event) int {
+	added := 0
+	for _, evt := range events {
+		if evt == nil || evt.ID == "" {
+			continue
+		}
+		if _, ok := g.events[evt.ID]; ok {
+			continue
+		}
+
+		parents := Parents(evt)
+		g.events[evt.ID] = evt
+		g.parents[evt.ID] = parents
+		for _, parent := range parents {
+			g.children[parent] = append(g.children[parent], evt.ID)
+		}
+		added++
+	}
+	return added
+}
+
+// Len returns the number of events in the graph
+func (g *Graph) Len() int { return len(g.events) }
+
+// Has reports whether the event with the given id is in the graph
+func (g *Graph) Has(id string) bool {
+	_, ok := g.events[id]
+	return ok
+}
+
+// Get returns the event with the given id, or nil
+func (g *Graph) Get(id string) *nostr.Event { return g.events[id] }
+
+// ParentsOf returns the parent ids of an event, including the ones missing from the graph
+func (g *Graph) ParentsOf(id string) []string { return slices.Clone(g.parents[id]) }
+
+// ChildrenOf returns the sorted ids of the events in the graph that list id as a parent
+func (g *Graph) ChildrenOf(id string) []string {
+	children := slices.Clone(g.children[id])
+	slices.Sort(children)
+	return children
+}
+
+// Roots returns the sorted ids of the events that have no parents
+func (g *Graph) Roots() []string {
+	var roots []string
+	for id, parents := range g.parents {
+		if len(parents) == 0 {
+			roots = append(roots, id)
+		}
+	}
+	slices.Sort(roots)
+	return roots
+}
+
+// Heads returns the sorted ids of the events that no other event in the graph points to,
+// which are the parents a new event extending the whole graph should reference
+func (g *Graph) Heads() []string {
+	var heads []string
+	for id := range g.events {
+		if len(g.children[id]) == 0 {
+			heads = append(heads, id)
+		}
+	}
+	slices.Sort(heads)
+	return heads
+}
+
+// Missing returns the sorted ids that are referenced as parents but are not in the graph
+func (g *Graph) Missing() []string {
+	var missing []string
+	for id := range g.children {
+		if _, ok := g.events[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// IsComplete reports whether every referenced parent is in the graph
+func (g *Graph) IsComplete() bool {
+	for id := range g.children {
+		if _, ok := g.events[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// FindCycle returns the ids of a cycle as a path that starts and ends on the same event,
+// or nil if the graph is acyclic. Content addressed ids make cycles impossible unless
+// the ids were not checked, so a cycle means the events are forged.
+func (g *Graph) FindCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(g.events))
+	var path []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		path = append(path, id)
+		for _, parent := range g.parents[id] {
+			if _, ok := g.events[parent]; !ok {
+				continue
+			}
+			switch state[parent] {
+			case visiting:
+				start := slices.Index(path, parent)
+				return append(slices.Clone(path[start:]), parent)
+			case unvisited:
+				if cycle := visit(parent); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+
+	ids := make([]string, 0, len(g.events))
+	for id := range g.events {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// TopologicalOrder returns the events sorted so that parents come before their children.
+// Among the events whose parents were all emitted the oldest comes first, ties are broken
+// by id, so the order only depends on the set of events. Missing parents are ignored.
+func (g *Graph) TopologicalOrder() ([]*nostr.Event, error) {
+	pending := make(map[string]int, len(g.events))
+	ready := &eventQueue{}
+	for id, evt := range g.events {
+		n := 0
+		for _, parent := range g.parents[id] {
+			if _, ok := g.events[parent]; ok {
+				n++
+			}
+		}
+		pending[id] = n
+		if n == 0 {
+			ready.events = append(ready.events, evt)
+		}
+	}
+	heap.Init(ready)
+
+	order := make([]*nostr.Event, 0, len(g.events))
+	for ready.Len() > 0 {
+		evt := heap.Pop(ready).(*nostr.Event)
+		order = append(order, evt)
+		for _, child := range g.children[evt.ID] {
+			pending[child]--
+			if pending[child] == 0 {
+				heap.Push(ready, g.events[child])
+			}
+		}
+	}
+
+	if len(order) != len(g.events) {
+		return order, fmt.Errorf("%w: %v", ErrCycle, g.FindCycle())
+	}
+	return order, nil
+}
+
+// Ancestors returns the sorted ids of the events in the graph reachable through parent links
+func (g *Graph) Ancestors(id string) []string {
+	return sortedKeys(g.walk(id, g.parents))
+}
+
+// Descendants returns the sorted ids of the events in the graph that descend from id
+func (g *Graph) Descendants(id string) []string {
+	return sortedKeys(g.walk(id, g.children))
+}
+
+// IsAncestor reports whether ancestor is reachable from id through parent links
+func (g *Graph) IsAncestor(ancestor, id string) bool {
+	_, ok := g.walk(id, g.parents)[ancestor]
+	return ok
+}
+
+// CommonAncestors returns the sorted ids of the events that are ancestors of all the given ids
+func (g *Graph) CommonAncestors(ids ...string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	common := g.walk(ids[0], g.parents)
+	for _, id := range ids[1:] {
+		other := g.walk(id, g.parents)
+		for ancestor := range common {
+			if _, ok := other[ancestor]; !ok {
+				delete(common, ancestor)
+			}
+		}
+	}
+	return sortedKeys(common)
+}
+
+// walk collects the events in the graph reachable from id through the given links
+func (g *Graph) walk(id string, links map[string][]string) map[string]struct{} {
+	seen := make(map[string]struct{})
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range links[current] {
+			if _, ok := g.events[next]; !ok {
+				continue
+			}
+			if _, ok := seen[next]; ok {
+				continue
+			}
+			seen[next] = struct{}{}
+			queue = append(queue, next)
+		}
+	}
+	return seen
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// eventQueue is a min-heap of events ordered by created_at, then id
+type eventQueue struct {
+	events []*nostr.Event
+}
+
+func (q *eventQueue) Len() int { return len(q.events) }
+
+func (q *eventQueue) Less(i, j int) bool {
+	a, b := q.events[i], q.events[j]
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt < b.CreatedAt
+	}
+	return a.ID < b.ID
+}
+
+func (q *eventQueue) Swap(i, j int) { q.events[i], q.events[j] = q.events[j], q.events[i] }
+
+func (q *eventQueue) Push(x any) { q.events = append(q.events, x.(*nostr.Event)) }
+
+func (q *eventQueue) Pop() any {
+	last := q.events[len(q.events)-1]
+	q.events = q.events[:len(q.events)-1]
+	return last
+}
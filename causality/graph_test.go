@@ -0,0 +1,174 @@
+package causality
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	nostr "github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSK = nostr.GeneratePrivateKey()
+
+func signed(t *testing.T, createdAt nostr.Timestamp, content string, parents ...*nostr.Event) *nostr.Event {
+	evt := &nostr.Event{Kind: nostr.KindTextNote, CreatedAt: createdAt, Content: content, Tags: nostr.Tags{}}
+	for _, parent := range parents {
+		evt.Tags = append(evt.Tags, nostr.Tag{"parent", parent.ID})
+	}
+	assert.NoError(t, evt.Sign(testSK))
+	return evt
+}
+
+// diamond builds root <- (left, right) <- merge
+func diamond(t *testing.T) (root, left, right, merge *nostr.Event) {
+	root = signed(t, 1, "root")
+	left = signed(t, 2, "left", root)
+	right = signed(t, 2, "right", root)
+	merge = signed(t, 3, "merge", left, right)
+	return
+}
+
+func ids(events []*nostr.Event) []string {
+	res := make([]string, len(events))
+	for i, evt := range events {
+		res[i] = evt.ID
+	}
+	return res
+}
+
+func sorted(ids ...string) []string {
+	slices.Sort(ids)
+	return ids
+}
+
+func TestParents(t *testing.T) {
+	evt := &nostr.Event{Tags: nostr.Tags{
+		{"parent", "a", "b"},
+		{"e", "c"},
+		{"parent", "b"},
+		{"parent"},
+		{"parent", "d"},
+	}}
+	assert.Equal(t, []string{"a", "b", "d"}, Parents(evt))
+}
+
+func TestGraph(t *testing.T) {
+	root, left, right, merge := diamond(t)
+
+	g := NewGraph()
+	assert.Equal(t, 2, g.Add(merge, left))
+	assert.Equal(t, 0, g.Add(left))
+	assert.Equal(t, sorted(root.ID, right.ID), g.Missing())
+	assert.False(t, g.IsComplete())
+
+	g.Add(right, root)
+	assert.Equal(t, 4, g.Len())
+	assert.True(t, g.IsComplete())
+	assert.Empty(t, g.Missing())
+	assert.Nil(t, g.FindCycle())
+
+	assert.Equal(t, []string{root.ID}, g.Roots())
+	assert.Equal(t, []string{merge.ID}, g.Heads())
+	assert.Equal(t, sorted(left.ID, right.ID), g.ChildrenOf(root.ID))
+	assert.Equal(t, []string{left.ID, right.ID}, g.ParentsOf(merge.ID))
+
+	assert.Equal(t, sorted(root.ID, left.ID, right.ID), g.Ancestors(merge.ID))
+	assert.Equal(t, sorted(left.ID, right.ID, merge.ID), g.Descendants(root.ID))
+	assert.Empty(t, g.Ancestors(root.ID))
+	assert.True(t, g.IsAncestor(root.ID, merge.ID))
+	assert.False(t, g.IsAncestor(left.ID, right.ID))
+	assert.Equal(t, []string{root.ID}, g.CommonAncestors(left.ID, right.ID))
+	assert.Equal(t, []string{root.ID}, g.CommonAncestors(merge.ID, left.ID))
+	assert.Nil(t, g.CommonAncestors(root.ID, merge.ID))
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	root, left, right, merge := diamond(t)
+	late := signed(t, 0, "orphan with an older timestamp")
+
+	first, second := left, right
+	if second.ID < first.ID {
+		first, second = second, first
+	}
+	expected := []string{late.ID, root.ID, first.ID, second.ID, merge.ID}
+
+	// insertion order must not matter
+	for _, events := range [][]*nostr.Event{
+		{root, left, right, merge, late},
+		{merge, right, late, left, root},
+	} {
+		g := NewGraph()
+		g.Add(events...)
+		order, err := g.TopologicalOrder()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ids(order))
+	}
+
+	// missing parents don't block their children
+	g := NewGraph()
+	g.Add(left, merge)
+	order, err := g.TopologicalOrder()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{left.ID, merge.ID}, ids(order))
+}
+
+func TestCycle(t *testing.T) {
+	// ids are not checked by the graph, so forged events can loop
+	a := &nostr.Event{ID: "a", Tags: nostr.Tags{{"parent", "c"}}}
+	b := &nostr.Event{ID: "b", Tags: nostr.Tags{{"parent", "a"}}}
+	c := &nostr.Event{ID: "c", Tags: nostr.Tags{{"parent", "b"}}}
+	d := &nostr.Event{ID: "d", Tags: nostr.Tags{{"parent", "c"}}}
+
+	g := NewGraph()
+	g.Add(a, b, c, d)
+	assert.Equal(t, []string{"a", "c", "b", "a"}, g.FindCycle())
+
+	order, err := g.TopologicalOrder()
+	assert.ErrorIs(t, err, ErrCycle)
+	assert.Empty(t, order)
+}
+
+type memoryStore struct {
+	nostr.RelayStore
+	events []*nostr.Event
+}
+
+func (s *memoryStore) QuerySync(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var res []*nostr.Event
+	for _, evt := range s.events {
+		if filter.Matches(evt) {
+			res = append(res, evt)
+		}
+	}
+	return res, nil
+}
+
+func TestResolve(t *testing.T) {
+	root, left, right, merge := diamond(t)
+
+	forged := *right
+	forged.Content = "not what was signed"
+	store := &memoryStore{events: []*nostr.Event{&forged, left}}
+
+	g := NewGraph()
+	g.Add(merge)
+	err := g.Resolve(context.Background(), StoreFetcher(store))
+	assert.ErrorIs(t, err, ErrMissingParents)
+	assert.True(t, g.Has(left.ID))
+	assert.False(t, g.Has(right.ID), "event with a mismatching id should be dropped")
+	assert.Equal(t, sorted(root.ID, right.ID), g.Missing())
+
+	store.events = append(store.events, right, root)
+	assert.NoError(t, g.Resolve(context.Background(), StoreFetcher(store)))
+	assert.True(t, g.IsComplete())
+	assert.Equal(t, 4, g.Len())
+
+	failing := func(ctx context.Context, ids []string) ([]*nostr.Event, error) {
+		return nil, errors.New("offline")
+	}
+	g = NewGraph()
+	g.Add(merge)
+	assert.ErrorContains(t, g.Resolve(context.Background(), failing), "offline")
+}
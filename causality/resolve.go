@@ -0,0 +1,71 @@
+package causality
+
+import (
+	"context"
+	"fmt"
+
+	nostr "github.com/nbd-wtf/go-nostr"
+)
+
+// Fetcher loads events by id. It may return fewer events than asked for.
+type Fetcher func(ctx context.Context, ids []string) ([]*nostr.Event, error)
+
+// StoreFetcher fetches events from a RelayStore with a single ids query
+func StoreFetcher(store nostr.RelayStore) Fetcher {
+	return func(ctx context.Context, ids []string) ([]*nostr.Event, error) {
+		return store.QuerySync(ctx, nostr.Filter{IDs: ids})
+	}
+}
+
+// PoolFetcher fetches events one by one from the given relays, taking the first answer
+func PoolFetcher(pool *nostr.SimplePool, urls []string) Fetcher {
+	return func(ctx context.Context, ids []string) ([]*nostr.Event, error) {
+		events := make([]*nostr.Event, 0, len(ids))
+		for _, id := range ids {
+			if ie := pool.QuerySingle(ctx, urls, nostr.Filter{IDs: []string{id}}); ie != nil {
+				events = append(events, ie.Event)
+			}
+			if err := ctx.Err(); err != nil {
+				return events, err
+			}
+		}
+		return events, nil
+	}
+}
+
+// Resolve fetches missing parents until the graph is complete. Fetched events are only
+// inserted if they were asked for and their id matches their content, and their own
+// parents are fetched in the next round. It fails with ErrMissingParents when a round
+// brings nothing new.
+func (g *Graph) Resolve(ctx context.Context, fetch Fetcher) error {
+	for {
+		missing := g.Missing()
+		if len(missing) == 0 {
+			return nil
+		}
+
+		events, err := fetch(ctx, missing)
+		if err != nil {
+			return fmt.Errorf("causality: fetching %d parents: %w", len(missing), err)
+		}
+
+		wanted := make(map[string]struct{}, len(missing))
+		for _, id := range missing {
+			wanted[id] = struct{}{}
+		}
+		added := 0
+		for _, evt := range events {
+			if evt == nil {
+				continue
+			}
+			if _, ok := wanted[evt.ID]; !ok || !evt.CheckID() {
+				continue
+			}
+			added += g.Add(evt)
+		}
+
+		if added == 0 {
+			return fmt.Errorf("%w: %v", ErrMissingParents, missing)
+		}
+	}
+}
@@ -82,6 +82,18 @@ func (a AuthTag) HasPermission(action Action) bool {
 	return a.Action&action != 0
 }
 
+// Covers checks if other is an attenuation of the auth tag: it grants a subset of the actions,
+// on the same key, and expires no later. Key 0 stands for every key.
+func (a AuthTag) Covers(other AuthTag) bool {
+	if other.Action&^a.Action != 0 {
+		return false
+	}
+	if a.Key != 0 && other.Key != a.Key {
+		return false
+	}
+	return other.Exp <= a.Exp
+}
+
 // IsExpired checks if the auth tag is expired based on the current clock value
 func (a AuthTag) IsExpired(currentClock uint64) bool {
 	return a.Exp <= currentClock
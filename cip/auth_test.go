@@ -59,3 +59,16 @@ func TestParseAuthTag(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestAuthTagCovers(t *testing.T) {
+	parent := NewAuthTag(ActionRead|ActionWrite|ActionExecute, 30300, 1000)
+
+	assert.True(t, parent.Covers(parent))
+	assert.True(t, parent.Covers(NewAuthTag(ActionWrite, 30300, 500)))
+	assert.False(t, parent.Covers(NewAuthTag(ActionWrite, 30300, 1001)), "extends exp")
+	assert.False(t, parent.Covers(NewAuthTag(ActionWrite, 30301, 500)), "other key")
+
+	writer := NewAuthTag(ActionWrite, 0, 1000)
+	assert.True(t, writer.Covers(NewAuthTag(ActionWrite, 30301, 1000)), "key 0 covers every key")
+	assert.False(t, writer.Covers(NewAuthTag(ActionWrite|ActionRead, 30301, 1000)), "widens actions")
+}
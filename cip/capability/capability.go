@@ -0,0 +1,167 @@
+// Package capability implements delegated AuthTag capabilities.
+//
+// The creator of a subspace grants capabilities to member addresses with grant events. A grantee
+// holding the execute action may grant an attenuated part of its capability further, pointing
+// to its own grant with a delegation tag. Grants are withdrawn with revoke events.
+package capability
+
+import (
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// GrantEvent gives a capability to a member of the subspace
+type GrantEvent struct {
+	*nostr.SubspaceOpEvent
+	Grantee    string
+	Capability cip.AuthTag
+	Delegation string // id of the grant the issuer holds, empty when issued by the subspace creator
+}
+
+// SetGrant sets the grantee, the granted capability and the grant it is delegated from
+func (e *GrantEvent) SetGrant(grantee string, capability cip.AuthTag, delegation string) {
+	e.Grantee = grantee
+	e.Capability = capability
+	e.Delegation = delegation
+
+	e.Tags = append(e.Tags,
+		nostr.Tag{"grantee", grantee},
+		nostr.Tag{"cap", capability.String()},
+	)
+	if delegation != "" {
+		e.Tags = append(e.Tags, nostr.Tag{"delegation", delegation})
+	}
+}
+
+// RevokeEvent withdraws a grant, together with every grant delegated from it
+type RevokeEvent struct {
+	*nostr.SubspaceOpEvent
+	GrantID string
+}
+
+// SetGrantID sets the grant to revoke
+func (e *RevokeEvent) SetGrantID(grantID string) {
+	e.GrantID = grantID
+	e.Tags = append(e.Tags, nostr.Tag{"grant", grantID})
+}
+
+// ParseCapabilityEvent parses a Nostr event into a grant or revoke event
+func ParseCapabilityEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
+	// Extract common fields
+	subspaceID := ""
+	parents := []string{}
+	var authTag cip.AuthTag
+	var clock *cip.VLC
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "sid":
+			subspaceID = tag[1]
+		case "auth":
+			auth, err := cip.ParseAuthTag(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
+			}
+			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
+		case "parent":
+			parents = append(parents, tag[1:]...)
+		}
+	}
+
+	// Get operation from kind
+	operation, exists := cip.GetOpFromKind(evt.Kind)
+	if !exists {
+		return nil, fmt.Errorf("unknown kind value: %d", evt.Kind)
+	}
+
+	base := &nostr.SubspaceOpEvent{
+		SubspaceID: subspaceID,
+		Operation:  operation,
+		AuthTag:    authTag,
+		Event:      evt,
+		Parents:    parents,
+		Clock:      clock,
+	}
+
+	// Parse based on operation type
+	switch operation {
+	case cip.OpGrant:
+		return parseGrantEvent(evt, base)
+	case cip.OpRevoke:
+		return parseRevokeEvent(evt, base)
+	default:
+		return nil, fmt.Errorf("unknown operation type: %s", operation)
+	}
+}
+
+func parseGrantEvent(evt nostr.Event, base *nostr.SubspaceOpEvent) (*GrantEvent, error) {
+	grant := &GrantEvent{SubspaceOpEvent: base}
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "grantee":
+			grant.Grantee = tag[1]
+		case "cap":
+			capability, err := cip.ParseAuthTag(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse cap tag: %v", err)
+			}
+			grant.Capability = capability
+		case "delegation":
+			grant.Delegation = tag[1]
+		}
+	}
+
+	return grant, nil
+}
+
+func parseRevokeEvent(evt nostr.Event, base *nostr.SubspaceOpEvent) (*RevokeEvent, error) {
+	revoke := &RevokeEvent{SubspaceOpEvent: base}
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		if tag[0] == "grant" {
+			revoke.GrantID = tag[1]
+		}
+	}
+
+	return revoke, nil
+}
+
+// NewGrantEvent creates a new grant event
+func NewGrantEvent(subspaceID string) (*GrantEvent, error) {
+	baseEvent, err := nostr.NewSubspaceOpEvent(subspaceID, cip.KindSubspaceGrant)
+	if err != nil {
+		return nil, err
+	}
+	return &GrantEvent{
+		SubspaceOpEvent: baseEvent,
+	}, nil
+}
+
+// NewRevokeEvent creates a new revoke event
+func NewRevokeEvent(subspaceID string) (*RevokeEvent, error) {
+	baseEvent, err := nostr.NewSubspaceOpEvent(subspaceID, cip.KindSubspaceRevoke)
+	if err != nil {
+		return nil, err
+	}
+	return &RevokeEvent{
+		SubspaceOpEvent: baseEvent,
+	}, nil
+}
@@ -0,0 +1,27 @@
+package capability
+
+import (
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Validate checks the grantee and that the grant carries a capability
+func (e *GrantEvent) Validate() error {
+	v := e.Validator()
+	if v.Required("grantee", e.Grantee) {
+		v.Check(nostr.IsValidPublicKey(e.Grantee), "grantee", "not an address or public key: %q", e.Grantee)
+	}
+	v.Check(e.Capability.Action != 0, "cap", "grants no action")
+	if e.Delegation != "" {
+		v.Hash("delegation", e.Delegation)
+	}
+	return v.Err()
+}
+
+// Validate checks that the revocation points to a grant
+func (e *RevokeEvent) Validate() error {
+	v := e.Validator()
+	if v.Required("grant", e.GrantID) {
+		v.Hash("grant", e.GrantID)
+	}
+	return v.Err()
+}
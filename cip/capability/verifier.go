@@ -0,0 +1,212 @@
+package capability
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+var (
+	ErrWrongSubspace   = errors.New("capability: event belongs to another subspace")
+	ErrUnknownGrant    = errors.New("capability: unknown grant")
+	ErrUntrustedIssuer = errors.New("capability: root grant is not issued by the subspace creator")
+	ErrBrokenChain     = errors.New("capability: issuer does not hold the grant it delegates from")
+	ErrNotDelegable    = errors.New("capability: delegated grant lacks the execute action")
+	ErrWidened         = errors.New("capability: grant is wider than the grant it delegates from")
+	ErrRevoked         = errors.New("capability: grant was revoked")
+	ErrExpired         = errors.New("capability: grant is expired")
+	ErrUnauthorized    = errors.New("capability: no valid grant allows the operation")
+	ErrMissingClock    = errors.New("capability: operation has no clock to check grant expiry against")
+)
+
+// Verifier collects the grants and revocations of a subspace and checks delegation chains.
+// Events may be added in any order, revocations of grants that aren't known yet are kept.
+type Verifier struct {
+	SubspaceID string
+	Creator    string
+
+	grants    map[string]*GrantEvent
+	byGrantee map[string][]string       // lowercase grantee -> grant ids
+	revokes   map[string][]*RevokeEvent // grant id -> revocations
+}
+
+// NewVerifier creates a verifier for the subspace created by creator
+func NewVerifier(subspaceID, creator string) *Verifier {
+	return &Verifier{
+		SubspaceID: subspaceID,
+		Creator:    creator,
+		grants:     make(map[string]*GrantEvent),
+		byGrantee:  make(map[string][]string),
+		revokes:    make(map[string][]*RevokeEvent),
+	}
+}
+
+// Add records a grant or revoke event of the subspace. Grants already recorded are ignored.
+func (v *Verifier) Add(evt nostr.Event) error {
+	op, err := ParseCapabilityEvent(evt)
+	if err != nil {
+		return err
+	}
+	if err := op.Validate(); err != nil {
+		return err
+	}
+	if op.GetSubspaceID() != v.SubspaceID {
+		return ErrWrongSubspace
+	}
+
+	switch e := op.(type) {
+	case *GrantEvent:
+		if _, exists := v.grants[evt.ID]; exists {
+			return nil
+		}
+		v.grants[evt.ID] = e
+		grantee := strings.ToLower(e.Grantee)
+		v.byGrantee[grantee] = append(v.byGrantee[grantee], evt.ID)
+	case *RevokeEvent:
+		v.revokes[e.GrantID] = append(v.revokes[e.GrantID], e)
+	}
+	return nil
+}
+
+// Chain returns the grants leading from the subspace creator down to grantID.
+// Each grant must be issued by the grantee of the previous one, which must hold the execute
+// action, and may only attenuate it. A grant revoked by the creator or by the issuer of any
+// grant of its chain breaks the chain.
+func (v *Verifier) Chain(grantID string) ([]*GrantEvent, error) {
+	var chain []*GrantEvent
+	for id := grantID; ; {
+		grant, ok := v.grants[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownGrant, id)
+		}
+		if slices.Contains(chain, grant) {
+			return nil, fmt.Errorf("%w: delegation loop at %s", ErrBrokenChain, id)
+		}
+		chain = append(chain, grant)
+
+		if grant.Delegation == "" {
+			if !samePubKey(grant.PubKey, v.Creator) {
+				return nil, fmt.Errorf("%w: %s", ErrUntrustedIssuer, id)
+			}
+			break
+		}
+
+		parent, ok := v.grants[grant.Delegation]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownGrant, grant.Delegation)
+		case !samePubKey(parent.Grantee, grant.PubKey):
+			return nil, fmt.Errorf("%w: %s", ErrBrokenChain, id)
+		case !parent.Capability.HasPermission(cip.ActionExecute):
+			return nil, fmt.Errorf("%w: %s", ErrNotDelegable, grant.Delegation)
+		case !parent.Capability.Covers(grant.Capability):
+			return nil, fmt.Errorf("%w: %s grants %s out of %s", ErrWidened, id, grant.Capability, parent.Capability)
+		}
+		id = grant.Delegation
+	}
+	slices.Reverse(chain)
+
+	for i, grant := range chain {
+		if v.isRevoked(grant.ID, chain[:i+1]) {
+			return nil, fmt.Errorf("%w: %s", ErrRevoked, grant.ID)
+		}
+	}
+	return chain, nil
+}
+
+// isRevoked checks if a grant was revoked by someone entitled to, given its chain
+func (v *Verifier) isRevoked(grantID string, chain []*GrantEvent) bool {
+	for _, revoke := range v.revokes[grantID] {
+		if samePubKey(revoke.PubKey, v.Creator) {
+			return true
+		}
+		for _, grant := range chain {
+			if samePubKey(revoke.PubKey, grant.PubKey) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Verify checks the chain of a grant and that none of its grants is expired at the given
+// clock value, and returns the capability it grants
+func (v *Verifier) Verify(grantID string, clock uint64) (cip.AuthTag, error) {
+	chain, err := v.Chain(grantID)
+	if err != nil {
+		return cip.AuthTag{}, err
+	}
+	for _, grant := range chain {
+		if grant.Capability.IsExpired(clock) {
+			return cip.AuthTag{}, fmt.Errorf("%w: %s at clock %d", ErrExpired, grant.ID, clock)
+		}
+	}
+	return chain[len(chain)-1].Capability, nil
+}
+
+// Grants returns the ids of the valid grants held by pubkey at the given clock value
+func (v *Verifier) Grants(pubkey string, clock uint64) []string {
+	var valid []string
+	for _, id := range v.byGrantee[strings.ToLower(pubkey)] {
+		if _, err := v.Verify(id, clock); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	slices.Sort(valid)
+	return valid
+}
+
+// Authorize checks that pubkey may perform the action on the key at the given clock value.
+// The subspace creator holds every capability.
+func (v *Verifier) Authorize(pubkey string, action cip.Action, key uint32, clock uint64) error {
+	if samePubKey(pubkey, v.Creator) {
+		return nil
+	}
+	for _, id := range v.Grants(pubkey, clock) {
+		if v.grants[id].Capability.Covers(cip.NewAuthTag(action, key, 0)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s cannot perform action %d on key %d", ErrUnauthorized, pubkey, action, key)
+}
+
+// AuthorizeOp checks that the author of an operation may write it. The action and key come
+// from the auth tag of the operation, defaulting to write on the kind of the operation.
+//
+// Grants expire against trusted, a clock the caller maintains such as the merge of the clocks
+// of the subspace operations, since the author of the operation writes its clock: the clock
+// value is the greater of the counters of trusted and of the operation clock for that key.
+// Every grant has an expiry, so operations of anyone but the creator must carry a clock.
+func (v *Verifier) AuthorizeOp(op *nostr.SubspaceOpEvent, trusted *cip.VLC) error {
+	if op.SubspaceID != v.SubspaceID {
+		return ErrWrongSubspace
+	}
+	if samePubKey(op.PubKey, v.Creator) {
+		return nil
+	}
+	if op.Clock == nil {
+		return ErrMissingClock
+	}
+
+	action, key := op.AuthTag.Action, op.AuthTag.Key
+	if action == 0 {
+		action = cip.ActionWrite
+	}
+	if key == 0 {
+		key = uint32(op.Kind)
+	}
+	clock := op.Clock.Get(key)
+	if trusted != nil {
+		clock = max(clock, trusted.Get(key))
+	}
+	return v.Authorize(op.PubKey, action, key, clock)
+}
+
+// samePubKey compares pubkeys ignoring the case of addresses
+func samePubKey(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
@@ -0,0 +1,163 @@
+package capability
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSID = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+func grant(t *testing.T, v *Verifier, sk, grantee string, capability cip.AuthTag, delegation string) string {
+	grant, err := NewGrantEvent(testSID)
+	assert.NoError(t, err)
+	grant.SetGrant(grantee, capability, delegation)
+	assert.NoError(t, grant.Sign(sk))
+	assert.NoError(t, v.Add(grant.Event))
+	return grant.ID
+}
+
+func revoke(t *testing.T, v *Verifier, sk, grantID string) {
+	revoke, err := NewRevokeEvent(testSID)
+	assert.NoError(t, err)
+	revoke.SetGrantID(grantID)
+	assert.NoError(t, revoke.Sign(sk))
+	assert.NoError(t, v.Add(revoke.Event))
+}
+
+func TestDelegationChain(t *testing.T) {
+	owner, alice, bob, carol := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	ownerAddr, _ := nostr.GetAddress(owner)
+	aliceAddr, _ := nostr.GetAddress(alice)
+	bobAddr, _ := nostr.GetAddress(bob)
+	carolAddr, _ := nostr.GetAddress(carol)
+	v := NewVerifier(testSID, ownerAddr)
+
+	all := cip.ActionRead | cip.ActionWrite | cip.ActionExecute
+	toAlice := grant(t, v, owner, aliceAddr, cip.NewAuthTag(all, cip.KindGovernancePost, 100), "")
+	toBob := grant(t, v, alice, bobAddr, cip.NewAuthTag(cip.ActionWrite, cip.KindGovernancePost, 50), toAlice)
+
+	chain, err := v.Chain(toBob)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{toAlice, toBob}, []string{chain[0].ID, chain[1].ID})
+
+	capability, err := v.Verify(toBob, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, cip.ActionWrite, capability.Action)
+	_, err = v.Verify(toBob, 50)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	assert.NoError(t, v.Authorize(ownerAddr, cip.ActionWrite, cip.KindGovernanceVote, 0))
+	assert.NoError(t, v.Authorize(bobAddr, cip.ActionWrite, cip.KindGovernancePost, 10))
+	assert.ErrorIs(t, v.Authorize(bobAddr, cip.ActionWrite, cip.KindGovernanceVote, 10), ErrUnauthorized)
+	assert.ErrorIs(t, v.Authorize(bobAddr, cip.ActionRead, cip.KindGovernancePost, 10), ErrUnauthorized)
+	assert.ErrorIs(t, v.Authorize(carolAddr, cip.ActionWrite, cip.KindGovernancePost, 10), ErrUnauthorized)
+
+	// bob cannot delegate without the execute action
+	toCarol := grant(t, v, bob, carolAddr, cip.NewAuthTag(cip.ActionWrite, cip.KindGovernancePost, 50), toBob)
+	_, err = v.Chain(toCarol)
+	assert.ErrorIs(t, err, ErrNotDelegable)
+
+	// alice cannot widen the actions or extend the expiration she was given
+	wider := grant(t, v, alice, carolAddr, cip.NewAuthTag(all, cip.KindGovernancePost, 200), toAlice)
+	_, err = v.Chain(wider)
+	assert.ErrorIs(t, err, ErrWidened)
+
+	// carol cannot delegate from a grant she does not hold
+	stolen := grant(t, v, carol, carolAddr, cip.NewAuthTag(cip.ActionWrite, cip.KindGovernancePost, 50), toAlice)
+	_, err = v.Chain(stolen)
+	assert.ErrorIs(t, err, ErrBrokenChain)
+
+	// only the creator may issue root grants
+	rogue := grant(t, v, alice, carolAddr, cip.NewAuthTag(cip.ActionWrite, cip.KindGovernancePost, 50), "")
+	_, err = v.Chain(rogue)
+	assert.ErrorIs(t, err, ErrUntrustedIssuer)
+
+	_, err = v.Chain(nostr.GeneratePrivateKey())
+	assert.ErrorIs(t, err, ErrUnknownGrant)
+}
+
+func TestRevocation(t *testing.T) {
+	owner, alice, bob, carol := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	ownerAddr, _ := nostr.GetAddress(owner)
+	aliceAddr, _ := nostr.GetAddress(alice)
+	bobAddr, _ := nostr.GetAddress(bob)
+	carolAddr, _ := nostr.GetAddress(carol)
+	v := NewVerifier(testSID, ownerAddr)
+
+	all := cip.ActionRead | cip.ActionWrite | cip.ActionExecute
+	toAlice := grant(t, v, owner, aliceAddr, cip.NewAuthTag(all, 0, 100), "")
+	toBob := grant(t, v, alice, bobAddr, cip.NewAuthTag(cip.ActionWrite, 0, 100), toAlice)
+
+	// a bystander cannot revoke
+	revoke(t, v, carol, toBob)
+	_, err := v.Chain(toBob)
+	assert.NoError(t, err)
+
+	// the grantee cannot revoke its own grant to escape attenuation, but the issuer can
+	revoke(t, v, bob, toBob)
+	_, err = v.Chain(toBob)
+	assert.NoError(t, err)
+	revoke(t, v, alice, toBob)
+	_, err = v.Chain(toBob)
+	assert.ErrorIs(t, err, ErrRevoked)
+	assert.Empty(t, v.Grants(bobAddr, 0))
+
+	// revoking a grant invalidates everything delegated from it
+	toCarol := grant(t, v, alice, carolAddr, cip.NewAuthTag(cip.ActionWrite, 0, 100), toAlice)
+	assert.Equal(t, []string{toCarol}, v.Grants(carolAddr, 0))
+	revoke(t, v, owner, toAlice)
+	_, err = v.Chain(toCarol)
+	assert.ErrorIs(t, err, ErrRevoked)
+}
+
+func TestAuthorizeOp(t *testing.T) {
+	owner, alice := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	ownerAddr, _ := nostr.GetAddress(owner)
+	aliceAddr, _ := nostr.GetAddress(alice)
+	v := NewVerifier(testSID, ownerAddr)
+	grant(t, v, owner, aliceAddr, cip.NewAuthTag(cip.ActionWrite, cip.KindGovernancePost, 5), "")
+
+	post, _ := nostr.NewSubspaceOpEvent(testSID, cip.KindGovernancePost)
+	clock := cip.NewVLC()
+	clock.Set(cip.KindGovernancePost, 3)
+	post.SetClock(clock)
+	assert.NoError(t, post.Sign(alice))
+	assert.NoError(t, v.AuthorizeOp(post, nil))
+
+	clock.Set(cip.KindGovernancePost, 5)
+	post.SetClock(clock)
+	assert.ErrorIs(t, v.AuthorizeOp(post, nil), ErrUnauthorized)
+
+	// a low counter doesn't hide that the subspace clock is past the expiry
+	trusted := cip.NewVLC()
+	trusted.Set(cip.KindGovernancePost, 7)
+	clock.Set(cip.KindGovernancePost, 1)
+	post.SetClock(clock)
+	assert.ErrorIs(t, v.AuthorizeOp(post, trusted), ErrUnauthorized)
+	trusted.Set(cip.KindGovernancePost, 4)
+	assert.NoError(t, v.AuthorizeOp(post, trusted))
+
+	// nor does leaving the clock out
+	unclocked, _ := nostr.NewSubspaceOpEvent(testSID, cip.KindGovernancePost)
+	assert.NoError(t, unclocked.Sign(alice))
+	assert.ErrorIs(t, v.AuthorizeOp(unclocked, trusted), ErrMissingClock)
+	creatorPost, _ := nostr.NewSubspaceOpEvent(testSID, cip.KindGovernancePost)
+	assert.NoError(t, creatorPost.Sign(owner))
+	assert.NoError(t, v.AuthorizeOp(creatorPost, trusted))
+
+	vote, _ := nostr.NewSubspaceOpEvent(testSID, cip.KindGovernanceVote)
+	vote.SetClock(clock)
+	assert.NoError(t, vote.Sign(alice))
+	assert.ErrorIs(t, v.AuthorizeOp(vote, nil), ErrUnauthorized)
+
+	other, _ := nostr.NewSubspaceOpEvent("0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890", cip.KindGovernancePost)
+	assert.ErrorIs(t, v.AuthorizeOp(other, nil), ErrWrongSubspace)
+
+	// grants must be well formed
+	grant, _ := NewGrantEvent(testSID)
+	grant.SetGrant("alice", cip.NewAuthTag(0, 0, 0), "")
+	assert.Error(t, v.Add(grant.Event))
+}
@@ -28,14 +28,18 @@ type ProposeEvent struct {
 	Rules      string
 }
 
-// SetProposal sets the proposal ID and rules
-func (e *ProposeEvent) SetProposal(proposalID, rules string) {
+// SetProposal sets the proposal ID and rules, it fails if the rules are malformed
+func (e *ProposeEvent) SetProposal(proposalID, rules string) error {
+	if err := cip.ValidateRules(rules); err != nil {
+		return err
+	}
 	e.ProposalID = proposalID
 	e.Rules = rules
 	e.Tags = append(e.Tags, nostr.Tag{"proposal_id", proposalID})
 	if rules != "" {
 		e.Tags = append(e.Tags, nostr.Tag{"rules", rules})
 	}
+	return nil
 }
 
 // VoteEvent represents a vote operation in governance subspace
@@ -59,13 +63,21 @@ type InviteEvent struct {
 	Rules       string
 }
 
-// SetInviter sets the inviter eth address and rules
-func (e *InviteEvent) SetInviter(inviterAddress, rules string) {
+// SetInviter sets the inviter eth address and rules, it fails if the address or the rules are malformed
+func (e *InviteEvent) SetInviter(inviterAddress, rules string) error {
+	if !nostr.IsValidAddress(inviterAddress) {
+		return fmt.Errorf("invalid inviter address: %q", inviterAddress)
+	}
+	if err := cip.ValidateRules(rules); err != nil {
+		return err
+	}
 	e.InviterAddr = inviterAddress
+	e.Rules = rules
 	e.Tags = append(e.Tags, nostr.Tag{"inviter_addr", inviterAddress})
 	if rules != "" {
 		e.Tags = append(e.Tags, nostr.Tag{"rules", rules})
 	}
+	return nil
 }
 
 // MintEvent represents a mint operation in governance subspace
@@ -118,6 +130,7 @@ func ParseGovernanceEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	subspaceID := ""
 	parents := []string{}
 	var authTag cip.AuthTag
+	var clock *cip.VLC
 
 	for _, tag := range evt.Tags {
 		if len(tag) < 2 {
@@ -132,6 +145,12 @@ func ParseGovernanceEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
 			}
 			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
 		case "parent":
 			parents = append(parents, tag[1:]...)
 		}
@@ -146,22 +165,22 @@ func ParseGovernanceEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Parse based on operation type
 	switch operation {
 	case cip.OpPost:
-		return parsePostEvent(evt, subspaceID, operation, authTag, parents)
+		return parsePostEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpPropose:
-		return parseProposeEvent(evt, subspaceID, operation, authTag, parents)
+		return parseProposeEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpVote:
-		return parseVoteEvent(evt, subspaceID, operation, authTag, parents)
+		return parseVoteEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpInvite:
-		return parseInviteEvent(evt, subspaceID, operation, authTag, parents)
+		return parseInviteEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpMint:
-		return parseMintEvent(evt, subspaceID, operation, authTag, parents)
+		return parseMintEvent(evt, subspaceID, operation, authTag, parents, clock)
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operation)
 	}
 
 }
 
-func parsePostEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*PostEvent, error) {
+func parsePostEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*PostEvent, error) {
 	post := &PostEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -169,6 +188,7 @@ func parsePostEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -185,7 +205,7 @@ func parsePostEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 	return post, nil
 }
 
-func parseProposeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ProposeEvent, error) {
+func parseProposeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ProposeEvent, error) {
 	propose := &ProposeEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -193,6 +213,7 @@ func parseProposeEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -211,7 +232,7 @@ func parseProposeEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 	return propose, nil
 }
 
-func parseVoteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*VoteEvent, error) {
+func parseVoteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*VoteEvent, error) {
 	vote := &VoteEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -219,6 +240,7 @@ func parseVoteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -237,7 +259,7 @@ func parseVoteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 	return vote, nil
 }
 
-func parseInviteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*InviteEvent, error) {
+func parseInviteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*InviteEvent, error) {
 	invite := &InviteEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -245,6 +267,7 @@ func parseInviteEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -263,7 +286,7 @@ func parseInviteEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 	return invite, nil
 }
 
-func parseMintEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*MintEvent, error) {
+func parseMintEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*MintEvent, error) {
 	mint := &MintEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -271,6 +294,7 @@ func parseMintEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -0,0 +1,247 @@
+package cip01
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+var (
+	ErrMissingMint    = errors.New("cip01: no mint event found for subspace")
+	ErrInvalidAmount  = errors.New("cip01: invalid token amount")
+	ErrInvalidRatio   = errors.New("cip01: invalid drop ratio")
+	ErrInvalidDecimal = errors.New("cip01: invalid token decimals")
+)
+
+// ParseDropRatio strictly parses a drop ratio like "30300:2,30301:2" into a kind -> points map
+func ParseDropRatio(dropRatio string) (map[int]int, error) {
+	rewards := make(map[int]int)
+	if dropRatio == "" {
+		return rewards, nil
+	}
+	for _, segment := range strings.Split(dropRatio, ",") {
+		parts := strings.Split(segment, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRatio, segment)
+		}
+		kind, err1 := strconv.Atoi(parts[0])
+		points, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || points < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRatio, segment)
+		}
+		if _, exists := rewards[kind]; exists {
+			return nil, fmt.Errorf("%w: duplicate kind %d", ErrInvalidRatio, kind)
+		}
+		rewards[kind] = points
+	}
+	return rewards, nil
+}
+
+// ParseAmount parses a decimal token amount like "12.5" into base units for the given decimals
+func ParseAmount(amount string, decimals int) (*big.Int, error) {
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" || len(frac) > decimals || strings.HasPrefix(whole, "-") || strings.HasPrefix(whole, "+") {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAmount, amount)
+	}
+	frac += strings.Repeat("0", decimals-len(frac))
+
+	v, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAmount, amount)
+	}
+	return v, nil
+}
+
+// FormatAmount formats base units as a decimal token amount, trimming trailing zeros
+func FormatAmount(amount *big.Int, decimals int) string {
+	if decimals == 0 {
+		return amount.String()
+	}
+
+	s := new(big.Int).Abs(amount).String()
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+
+	if amount.Sign() < 0 {
+		whole = "-" + whole
+	}
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// LedgerEntry is a single credit to an account
+type LedgerEntry struct {
+	EventID   string
+	Kind      int
+	Amount    *big.Int // base units
+	CreatedAt nostr.Timestamp
+}
+
+// Ledger computes token balances of a subspace from its mint event and the drop ratio
+type Ledger struct {
+	Mint     *MintEvent
+	Decimals int
+
+	rewards  map[int]*big.Int // kind -> base units credited per event
+	balances map[string]*big.Int
+	history  map[string][]LedgerEntry
+	supply   *big.Int
+	seen     map[string]struct{}
+}
+
+// NewLedger creates a ledger from a mint event, crediting the initial supply to the minter
+func NewLedger(mint *MintEvent) (*Ledger, error) {
+	decimals, err := strconv.Atoi(mint.TokenDecimals)
+	if err != nil || decimals < 0 || decimals > 77 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDecimal, mint.TokenDecimals)
+	}
+	ratio, err := ParseDropRatio(mint.DropRatio)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	l := &Ledger{
+		Mint:     mint,
+		Decimals: decimals,
+		rewards:  make(map[int]*big.Int, len(ratio)),
+		balances: make(map[string]*big.Int),
+		history:  make(map[string][]LedgerEntry),
+		supply:   new(big.Int),
+		seen:     make(map[string]struct{}),
+	}
+	for kind, points := range ratio {
+		l.rewards[kind] = new(big.Int).Mul(big.NewInt(int64(points)), unit)
+	}
+
+	if mint.InitialSupply != "" {
+		initial, err := ParseAmount(mint.InitialSupply, decimals)
+		if err != nil {
+			return nil, err
+		}
+		l.credit(mint.PubKey, LedgerEntry{
+			EventID:   mint.ID,
+			Kind:      mint.Kind,
+			Amount:    initial,
+			CreatedAt: mint.CreatedAt,
+		})
+	}
+	l.seen[mint.ID] = struct{}{}
+
+	return l, nil
+}
+
+// NewLedgerFromEvents finds the mint event of a subspace among the events and replays the rest into a ledger.
+// Only the creator of the subspace may mint its token, so mints of other authors are ignored. If
+// the creator minted more than once the earliest mint, by created_at then id, is the one.
+func NewLedgerFromEvents(subspaceID, creator string, events []nostr.Event) (*Ledger, error) {
+	var mint *nostr.Event
+	for i, evt := range events {
+		if evt.Kind != cip.KindGovernanceMint || evt.PubKey != creator || evt.Tags.FindWithValue("sid", subspaceID) == nil {
+			continue
+		}
+		if mint == nil || evt.CreatedAt < mint.CreatedAt || (evt.CreatedAt == mint.CreatedAt && evt.ID < mint.ID) {
+			mint = &events[i]
+		}
+	}
+	if mint == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingMint, subspaceID)
+	}
+
+	op, err := ParseGovernanceEvent(*mint)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := NewLedger(op.(*MintEvent))
+	if err != nil {
+		return nil, err
+	}
+	ledger.Replay(events)
+	return ledger, nil
+}
+
+// Replay applies all the events, see Apply
+func (l *Ledger) Replay(events []nostr.Event) {
+	for _, evt := range events {
+		l.Apply(evt)
+	}
+}
+
+// Apply credits the author of an event with the points of its kind.
+// Events of other subspaces, events older than the mint and already applied events are ignored.
+// It reports whether the event was credited.
+func (l *Ledger) Apply(evt nostr.Event) bool {
+	reward, ok := l.rewards[evt.Kind]
+	if !ok || evt.CreatedAt < l.Mint.CreatedAt || evt.Tags.FindWithValue("sid", l.Mint.SubspaceID) == nil {
+		return false
+	}
+	if _, ok := l.seen[evt.ID]; ok {
+		return false
+	}
+	l.seen[evt.ID] = struct{}{}
+
+	l.credit(evt.PubKey, LedgerEntry{
+		EventID:   evt.ID,
+		Kind:      evt.Kind,
+		Amount:    new(big.Int).Set(reward),
+		CreatedAt: evt.CreatedAt,
+	})
+	return true
+}
+
+func (l *Ledger) credit(pubkey string, entry LedgerEntry) {
+	balance, ok := l.balances[pubkey]
+	if !ok {
+		balance = new(big.Int)
+		l.balances[pubkey] = balance
+	}
+	balance.Add(balance, entry.Amount)
+	l.supply.Add(l.supply, entry.Amount)
+	l.history[pubkey] = append(l.history[pubkey], entry)
+}
+
+// BalanceOf returns the balance of an account in base units
+func (l *Ledger) BalanceOf(pubkey string) *big.Int {
+	if balance, ok := l.balances[pubkey]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return new(big.Int)
+}
+
+// Balances returns a copy of all the balances in base units
+func (l *Ledger) Balances() map[string]*big.Int {
+	balances := make(map[string]*big.Int, len(l.balances))
+	for pubkey, balance := range l.balances {
+		balances[pubkey] = new(big.Int).Set(balance)
+	}
+	return balances
+}
+
+// History returns the credits of an account in the order they were applied
+func (l *Ledger) History(pubkey string) []LedgerEntry {
+	history := make([]LedgerEntry, len(l.history[pubkey]))
+	for i, entry := range l.history[pubkey] {
+		entry.Amount = new(big.Int).Set(entry.Amount)
+		history[i] = entry
+	}
+	return history
+}
+
+// TotalSupply returns the initial supply plus all the rewards, in base units
+func (l *Ledger) TotalSupply() *big.Int {
+	return new(big.Int).Set(l.supply)
+}
+
+// Format formats an amount in base units using the token decimals
+func (l *Ledger) Format(amount *big.Int) string {
+	return FormatAmount(amount, l.Decimals)
+}
@@ -0,0 +1,145 @@
+package cip01
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDropRatio(t *testing.T) {
+	ratio, err := ParseDropRatio("30300:2,30301:2,30304:10")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{30300: 2, 30301: 2, 30304: 10}, ratio)
+
+	for _, s := range []string{"30300", "30300:x", "30300:-1", "30300:1,30300:2", "30300:1,"} {
+		_, err := ParseDropRatio(s)
+		assert.ErrorIs(t, err, ErrInvalidRatio, s)
+	}
+}
+
+func TestAmounts(t *testing.T) {
+	v, err := ParseAmount("12.5", 18)
+	assert.NoError(t, err)
+	assert.Equal(t, "12500000000000000000", v.String())
+	assert.Equal(t, "12.5", FormatAmount(v, 18))
+
+	v, err = ParseAmount("0.000001", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", v.String())
+	assert.Equal(t, "0.000001", FormatAmount(v, 6))
+	assert.Equal(t, "-0.5", FormatAmount(big.NewInt(-5), 1))
+	assert.Equal(t, "100", FormatAmount(big.NewInt(100), 0))
+
+	for _, s := range []string{"", "-1", "1.2345", "abc", ".5"} {
+		_, err := ParseAmount(s, 3)
+		assert.ErrorIs(t, err, ErrInvalidAmount, s)
+	}
+}
+
+func TestLedger(t *testing.T) {
+	mint, err := NewMintEvent(testSID)
+	assert.NoError(t, err)
+	mint.SetTokenInfo("DesciToken", "DST", "18", "100", "30300:2,30301:3")
+	mint.PubKey = "alice"
+	mint.ID = "mint"
+	mint.CreatedAt = 1000
+
+	post := func(author, id string, createdAt nostr.Timestamp) nostr.Event {
+		evt, err := NewPostEvent(testSID)
+		assert.NoError(t, err)
+		evt.PubKey = author
+		evt.ID = id
+		evt.CreatedAt = createdAt
+		return evt.Event
+	}
+	propose, err := NewProposeEvent(testSID)
+	assert.NoError(t, err)
+	propose.PubKey = "bob"
+	propose.ID = "propose"
+	propose.CreatedAt = 1003
+
+	otherSubspace, err := NewPostEvent("0x" + testSID[4:] + "ff")
+	assert.NoError(t, err)
+	otherSubspace.PubKey = "bob"
+	otherSubspace.ID = "other"
+	otherSubspace.CreatedAt = 1004
+
+	vote, err := NewVoteEvent(testSID)
+	assert.NoError(t, err)
+	vote.PubKey = "bob"
+	vote.ID = "vote"
+	vote.CreatedAt = 1005
+
+	events := []nostr.Event{
+		post("bob", "early", 999), // before the mint
+		mint.Event,
+		post("bob", "p1", 1001),
+		post("bob", "p1", 1001), // duplicate
+		post("carol", "p2", 1002),
+		propose.Event,
+		otherSubspace.Event,
+		vote.Event, // no reward for votes
+	}
+
+	ledger, err := NewLedgerFromEvents(testSID, "alice", events)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "100", ledger.Format(ledger.BalanceOf("alice")))
+	assert.Equal(t, "5", ledger.Format(ledger.BalanceOf("bob")))
+	assert.Equal(t, "2", ledger.Format(ledger.BalanceOf("carol")))
+	assert.Equal(t, "0", ledger.Format(ledger.BalanceOf("dave")))
+	assert.Equal(t, "107", ledger.Format(ledger.TotalSupply()))
+	assert.Len(t, ledger.Balances(), 3)
+
+	history := ledger.History("bob")
+	assert.Len(t, history, 2)
+	assert.Equal(t, "p1", history[0].EventID)
+	assert.Equal(t, "propose", history[1].EventID)
+
+	// the returned history can't alter the ledger
+	history[0].Amount.SetInt64(0)
+	assert.Equal(t, "5", ledger.Format(ledger.BalanceOf("bob")))
+
+	_, err = NewLedgerFromEvents(testSID, "alice", events[:1])
+	assert.ErrorIs(t, err, ErrMissingMint)
+
+	// only the creator mints
+	_, err = NewLedgerFromEvents(testSID, "bob", events)
+	assert.ErrorIs(t, err, ErrMissingMint)
+
+	mint.TokenDecimals = "x"
+	_, err = NewLedger(mint)
+	assert.ErrorIs(t, err, ErrInvalidDecimal)
+}
+
+func TestLedgerEarliestMint(t *testing.T) {
+	newMint := func(author, id, supply string, createdAt nostr.Timestamp) nostr.Event {
+		mint, err := NewMintEvent(testSID)
+		assert.NoError(t, err)
+		mint.SetTokenInfo("DesciToken", "DST", "0", supply, "30300:1")
+		mint.PubKey = author
+		mint.ID = id
+		mint.CreatedAt = createdAt
+		return mint.Event
+	}
+
+	// the later mint comes first and someone else minted even earlier
+	events := []nostr.Event{
+		newMint("alice", "mint2", "500", 1002),
+		newMint("mallory", "forged", "1000000", 999),
+		newMint("alice", "mint1", "100", 1001),
+	}
+	ledger, err := NewLedgerFromEvents(testSID, "alice", events)
+	assert.NoError(t, err)
+	assert.Equal(t, "mint1", ledger.Mint.ID)
+	assert.Equal(t, "100", ledger.Format(ledger.BalanceOf("alice")))
+	assert.Equal(t, "0", ledger.Format(ledger.BalanceOf("mallory")))
+
+	// same created_at, the lowest id wins
+	events = append(events, newMint("alice", "mint0", "200", 1001))
+	ledger, err = NewLedgerFromEvents(testSID, "alice", events)
+	assert.NoError(t, err)
+	assert.Equal(t, "mint0", ledger.Mint.ID)
+}
@@ -0,0 +1,231 @@
+package cip01
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// Vote values
+const (
+	VoteYes     = "yes"
+	VoteNo      = "no"
+	VoteAbstain = "abstain"
+)
+
+// ProposalStatus represents the outcome of a proposal
+type ProposalStatus string
+
+const (
+	ProposalOpen     ProposalStatus = "open"
+	ProposalPassed   ProposalStatus = "passed"
+	ProposalRejected ProposalStatus = "rejected"
+	ProposalExpired  ProposalStatus = "expired"
+)
+
+var (
+	ErrInvalidVote       = errors.New("cip01: invalid vote value")
+	ErrMissingProposalID = errors.New("cip01: missing proposal_id")
+	ErrDuplicateProposal = errors.New("cip01: proposal already exists")
+	ErrUnknownProposal   = errors.New("cip01: unknown proposal")
+	ErrVotingClosed      = errors.New("cip01: vote cast after the voting deadline")
+)
+
+// ValidateVote checks that a vote value is one of yes, no or abstain
+func ValidateVote(vote string) error {
+	switch vote {
+	case VoteYes, VoteNo, VoteAbstain:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidVote, vote)
+	}
+}
+
+// VotingPolicy defines how proposals are decided
+type VotingPolicy struct {
+	Quorum       float64 // fraction of eligible members that must vote, abstentions included
+	Threshold    float64 // a proposal passes when yes/(yes+no) is greater than this fraction
+	VotingPeriod int64   // seconds after the proposal during which votes are accepted, 0 for no deadline
+}
+
+// DefaultVotingPolicy is a simple majority of at least half of the members, without deadline
+var DefaultVotingPolicy = VotingPolicy{
+	Quorum:    0.5,
+	Threshold: 0.5,
+}
+
+// ParseVotingPolicy extracts the voting policy from the settings of a subspace rules string,
+// e.g. "energy>1000;quorum=0.6;threshold=0.66;voting_period=86400".
+// Missing values fall back to DefaultVotingPolicy.
+func ParseVotingPolicy(rules string) (VotingPolicy, error) {
+	r, err := cip.ParseRules(rules)
+	if err != nil {
+		return VotingPolicy{}, err
+	}
+
+	policy := DefaultVotingPolicy
+	if v, ok := r.Settings[cip.RuleSettingQuorum]; ok {
+		quorum, err := strconv.ParseFloat(v, 64)
+		if err != nil || quorum < 0 || quorum > 1 {
+			return VotingPolicy{}, fmt.Errorf("invalid quorum value: %s", v)
+		}
+		policy.Quorum = quorum
+	}
+	if v, ok := r.Settings[cip.RuleSettingThreshold]; ok {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil || threshold < 0 || threshold >= 1 {
+			return VotingPolicy{}, fmt.Errorf("invalid threshold value: %s", v)
+		}
+		policy.Threshold = threshold
+	}
+	if v, ok := r.Settings[cip.RuleSettingVotingPeriod]; ok {
+		period, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || period < 0 {
+			return VotingPolicy{}, fmt.Errorf("invalid voting_period value: %s", v)
+		}
+		policy.VotingPeriod = period
+	}
+	return policy, nil
+}
+
+// TrackedProposal is a proposal together with the latest vote of each member
+type TrackedProposal struct {
+	*ProposeEvent
+	Votes map[string]*VoteEvent // voter pubkey -> latest vote
+}
+
+// Deadline returns the time after which votes are no longer accepted, 0 if there is none
+func (p *TrackedProposal) Deadline(policy VotingPolicy) nostr.Timestamp {
+	if policy.VotingPeriod == 0 {
+		return 0
+	}
+	return p.CreatedAt + nostr.Timestamp(policy.VotingPeriod)
+}
+
+// ProposalResult is the outcome of tallying a proposal
+type ProposalResult struct {
+	ProposalID string
+	Status     ProposalStatus
+	Yes        int
+	No         int
+	Abstain    int
+	Eligible   int
+	Rules      string // rules that take effect, only set when the proposal passed
+}
+
+// ProposalTracker keeps track of proposals and their votes
+type ProposalTracker struct {
+	Policy    VotingPolicy
+	Proposals map[string]*TrackedProposal
+}
+
+// NewProposalTracker creates a new proposal tracker using the given policy
+func NewProposalTracker(policy VotingPolicy) *ProposalTracker {
+	return &ProposalTracker{
+		Policy:    policy,
+		Proposals: make(map[string]*TrackedProposal),
+	}
+}
+
+// AddProposal starts tracking a proposal
+func (t *ProposalTracker) AddProposal(propose *ProposeEvent) error {
+	if propose.ProposalID == "" {
+		return ErrMissingProposalID
+	}
+	if _, exists := t.Proposals[propose.ProposalID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateProposal, propose.ProposalID)
+	}
+	t.Proposals[propose.ProposalID] = &TrackedProposal{
+		ProposeEvent: propose,
+		Votes:        make(map[string]*VoteEvent),
+	}
+	return nil
+}
+
+// AddVote records a vote, replacing any older vote of the same member on the same proposal.
+// Votes cast after the deadline are rejected, so they never replace a vote cast in time.
+func (t *ProposalTracker) AddVote(vote *VoteEvent) error {
+	if vote.ProposalID == "" {
+		return ErrMissingProposalID
+	}
+	if err := ValidateVote(vote.Vote); err != nil {
+		return err
+	}
+	proposal, exists := t.Proposals[vote.ProposalID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownProposal, vote.ProposalID)
+	}
+	if deadline := proposal.Deadline(t.Policy); deadline != 0 && vote.CreatedAt > deadline {
+		return fmt.Errorf("%w: %s", ErrVotingClosed, vote.ProposalID)
+	}
+
+	if previous, ok := proposal.Votes[vote.PubKey]; ok {
+		// keep the latest vote, using the id as a tie-breaker so the result doesn't depend on arrival order
+		if previous.CreatedAt > vote.CreatedAt ||
+			(previous.CreatedAt == vote.CreatedAt && previous.ID > vote.ID) {
+			return nil
+		}
+	}
+	proposal.Votes[vote.PubKey] = vote
+	return nil
+}
+
+// Remove stops tracking a proposal
+func (t *ProposalTracker) Remove(proposalID string) {
+	delete(t.Proposals, proposalID)
+}
+
+// Tally counts the votes of the eligible members on a proposal and reports its outcome at the given time.
+// Votes from non-eligible pubkeys and votes cast after the deadline are ignored.
+func (t *ProposalTracker) Tally(proposalID string, eligible []string, now nostr.Timestamp) (ProposalResult, error) {
+	proposal, exists := t.Proposals[proposalID]
+	if !exists {
+		return ProposalResult{}, fmt.Errorf("%w: %s", ErrUnknownProposal, proposalID)
+	}
+
+	result := ProposalResult{
+		ProposalID: proposalID,
+		Status:     ProposalOpen,
+		Eligible:   len(eligible),
+	}
+
+	deadline := proposal.Deadline(t.Policy)
+	for _, member := range eligible {
+		vote, ok := proposal.Votes[member]
+		if !ok || (deadline != 0 && vote.CreatedAt > deadline) {
+			continue
+		}
+		switch vote.Vote {
+		case VoteYes:
+			result.Yes++
+		case VoteNo:
+			result.No++
+		case VoteAbstain:
+			result.Abstain++
+		}
+	}
+
+	if deadline != 0 && now <= deadline {
+		return result, nil
+	}
+
+	quorum := int(math.Ceil(t.Policy.Quorum * float64(len(eligible))))
+	if result.Yes+result.No+result.Abstain < quorum || result.Yes+result.No == 0 {
+		if deadline != 0 {
+			result.Status = ProposalExpired
+		}
+		return result, nil
+	}
+
+	if float64(result.Yes)/float64(result.Yes+result.No) > t.Policy.Threshold {
+		result.Status = ProposalPassed
+		result.Rules = proposal.Rules
+	} else {
+		result.Status = ProposalRejected
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package cip01
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSID = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+func newTestVote(t *testing.T, voter, id string, createdAt nostr.Timestamp, proposalID, value string) *VoteEvent {
+	vote, err := NewVoteEvent(testSID)
+	assert.NoError(t, err)
+	vote.SetVote(proposalID, value)
+	vote.PubKey = voter
+	vote.ID = id
+	vote.CreatedAt = createdAt
+	return vote
+}
+
+func newTestTracker(t *testing.T, policy VotingPolicy) *ProposalTracker {
+	propose, err := NewProposeEvent(testSID)
+	assert.NoError(t, err)
+	propose.SetProposal("prop_001", "energy>2000")
+	propose.CreatedAt = 1000
+
+	tracker := NewProposalTracker(policy)
+	assert.NoError(t, tracker.AddProposal(propose))
+	assert.ErrorIs(t, tracker.AddProposal(propose), ErrDuplicateProposal)
+	return tracker
+}
+
+func TestValidateVote(t *testing.T) {
+	for _, v := range []string{VoteYes, VoteNo, VoteAbstain} {
+		assert.NoError(t, ValidateVote(v))
+	}
+	for _, v := range []string{"", "Yes", "maybe"} {
+		assert.ErrorIs(t, ValidateVote(v), ErrInvalidVote)
+	}
+}
+
+func TestParseVotingPolicy(t *testing.T) {
+	policy, err := ParseVotingPolicy("energy>1000;quorum=0.6;threshold=0.66;voting_period=3600")
+	assert.NoError(t, err)
+	assert.Equal(t, VotingPolicy{Quorum: 0.6, Threshold: 0.66, VotingPeriod: 3600}, policy)
+
+	policy, err = ParseVotingPolicy("energy>1000")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultVotingPolicy, policy)
+
+	for _, rules := range []string{"quorum=2", "threshold=x", "voting_period=-1", "energy>>1"} {
+		_, err := ParseVotingPolicy(rules)
+		assert.Error(t, err, rules)
+	}
+}
+
+func TestProposalTrackerLatestVoteWins(t *testing.T) {
+	tracker := newTestTracker(t, DefaultVotingPolicy)
+	members := []string{"alice", "bob", "carol"}
+
+	assert.NoError(t, tracker.AddVote(newTestVote(t, "alice", "a2", 1002, "prop_001", VoteNo)))
+	// an older vote arriving later must not override the newer one
+	assert.NoError(t, tracker.AddVote(newTestVote(t, "alice", "a1", 1001, "prop_001", VoteYes)))
+	assert.NoError(t, tracker.AddVote(newTestVote(t, "bob", "b1", 1001, "prop_001", VoteYes)))
+	assert.NoError(t, tracker.AddVote(newTestVote(t, "mallory", "m1", 1001, "prop_001", VoteYes)))
+
+	assert.ErrorIs(t, tracker.AddVote(newTestVote(t, "bob", "b2", 1003, "prop_001", "maybe")), ErrInvalidVote)
+	assert.ErrorIs(t, tracker.AddVote(newTestVote(t, "bob", "b3", 1003, "prop_404", VoteYes)), ErrUnknownProposal)
+	assert.ErrorIs(t, tracker.AddVote(newTestVote(t, "bob", "b4", 1003, "", VoteYes)), ErrMissingProposalID)
+
+	result, err := tracker.Tally("prop_001", members, 1010)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Yes)
+	assert.Equal(t, 1, result.No)
+	assert.Equal(t, 3, result.Eligible)
+	// a tie doesn't pass a simple majority
+	assert.Equal(t, ProposalRejected, result.Status)
+	assert.Empty(t, result.Rules)
+
+	assert.NoError(t, tracker.AddVote(newTestVote(t, "carol", "c1", 1005, "prop_001", VoteYes)))
+	result, err = tracker.Tally("prop_001", members, 1010)
+	assert.NoError(t, err)
+	assert.Equal(t, ProposalPassed, result.Status)
+	assert.Equal(t, "energy>2000", result.Rules)
+}
+
+func TestProposalTrackerDeadline(t *testing.T) {
+	tracker := newTestTracker(t, VotingPolicy{Quorum: 0.5, Threshold: 0.5, VotingPeriod: 100})
+	members := []string{"alice", "bob", "carol", "dave"}
+
+	assert.NoError(t, tracker.AddVote(newTestVote(t, "alice", "a1", 1010, "prop_001", VoteYes)))
+
+	result, err := tracker.Tally("prop_001", members, 1050)
+	assert.NoError(t, err)
+	assert.Equal(t, ProposalOpen, result.Status)
+
+	// votes after the deadline are rejected
+	assert.ErrorIs(t, tracker.AddVote(newTestVote(t, "bob", "b1", 1200, "prop_001", VoteYes)), ErrVotingClosed)
+
+	result, err = tracker.Tally("prop_001", members, 1200)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Yes)
+	assert.Equal(t, ProposalExpired, result.Status)
+
+	_, err = tracker.Tally("prop_404", members, 1200)
+	assert.ErrorIs(t, err, ErrUnknownProposal)
+}
+
+func TestProposalTrackerLateRevote(t *testing.T) {
+	tracker := newTestTracker(t, VotingPolicy{Quorum: 0.5, Threshold: 0.5, VotingPeriod: 100})
+	members := []string{"alice", "bob"}
+
+	assert.NoError(t, tracker.AddVote(newTestVote(t, "alice", "a1", 1010, "prop_001", VoteYes)))
+	assert.ErrorIs(t, tracker.AddVote(newTestVote(t, "alice", "a2", 1200, "prop_001", VoteNo)), ErrVotingClosed)
+
+	// the vote cast in time still counts
+	result, err := tracker.Tally("prop_001", members, 1200)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Yes)
+	assert.Equal(t, 0, result.No)
+	assert.Equal(t, ProposalPassed, result.Status)
+}
+
+func TestSetProposalRejectsMalformedRules(t *testing.T) {
+	propose, err := NewProposeEvent(testSID)
+	assert.NoError(t, err)
+	assert.Error(t, propose.SetProposal("prop_001", "energy>"))
+	assert.Empty(t, propose.ProposalID)
+	assert.Nil(t, propose.Tags.Find("proposal_id"))
+
+	invite, err := NewInviteEvent(testSID)
+	assert.NoError(t, err)
+	assert.Error(t, invite.SetInviter("1111111111111111111111111111111111111111", "(energy>1"))
+	assert.NoError(t, invite.SetInviter("1111111111111111111111111111111111111111", "energy>1"))
+	assert.Equal(t, "energy>1", invite.Rules)
+}
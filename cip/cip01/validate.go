@@ -0,0 +1,62 @@
+package cip01
+
+import (
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// Validate checks that the post has content
+func (e *PostEvent) Validate() error {
+	v := e.Validator()
+	v.Required("content", e.Content)
+	return v.Err()
+}
+
+// Validate checks that the proposal has an id and well formed rules
+func (e *ProposeEvent) Validate() error {
+	v := e.Validator()
+	v.Required("proposal_id", e.ProposalID)
+	v.CheckErr("rules", cip.ValidateRules(e.Rules))
+	return v.Err()
+}
+
+// Validate checks that the vote references a proposal and has a known choice
+func (e *VoteEvent) Validate() error {
+	v := e.Validator()
+	v.Required("proposal_id", e.ProposalID)
+	if v.Required("vote", e.Vote) {
+		v.CheckErr("vote", ValidateVote(e.Vote))
+	}
+	return v.Err()
+}
+
+// Validate checks that the invite targets an eth address and has well formed rules
+func (e *InviteEvent) Validate() error {
+	v := e.Validator()
+	if v.Required("inviter_addr", e.InviterAddr) {
+		v.Check(nostr.IsValidAddress(e.InviterAddr), "inviter_addr", "not a 20 byte hex address: %q", e.InviterAddr)
+	}
+	v.CheckErr("rules", cip.ValidateRules(e.Rules))
+	return v.Err()
+}
+
+// Validate checks the token information, amounts are checked against the declared decimals
+func (e *MintEvent) Validate() error {
+	v := e.Validator()
+	v.Required("token_name", e.TokenName)
+	v.Required("token_symbol", e.TokenSymbol)
+
+	decimals, err := strconv.Atoi(e.TokenDecimals)
+	validDecimals := err == nil && decimals >= 0 && decimals <= 77
+	v.Check(validDecimals, "token_decimals", "must be an integer between 0 and 77, got %q", e.TokenDecimals)
+
+	if v.Required("initial_supply", e.InitialSupply) && validDecimals {
+		_, err := ParseAmount(e.InitialSupply, decimals)
+		v.CheckErr("initial_supply", err)
+	}
+	_, err = ParseDropRatio(e.DropRatio)
+	v.CheckErr("drop_ratio", err)
+	return v.Err()
+}
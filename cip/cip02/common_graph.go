@@ -25,6 +25,7 @@ func (e *ProjectEvent) SetProjectInfo(projectID, name, desc string, members []st
 	e.Members = members
 	e.Status = status
 
+	e.SetObjectID(projectID)
 	e.Tags = append(e.Tags,
 		nostr.Tag{"project_id", projectID},
 		nostr.Tag{"name", name},
@@ -61,6 +62,7 @@ func (e *TaskEvent) SetTaskInfo(projectID, taskID, title, assignee, status, dead
 	e.Deadline = deadline
 	e.Priority = priority
 
+	e.SetObjectID(taskID)
 	e.Tags = append(e.Tags,
 		nostr.Tag{"project_id", projectID},
 		nostr.Tag{"task_id", taskID},
@@ -84,6 +86,7 @@ func (e *EntityEvent) SetEntityInfo(entityName, entityType string) {
 	e.EntityName = entityName
 	e.EntityType = entityType
 
+	e.SetObjectID(entityName)
 	e.Tags = append(e.Tags,
 		nostr.Tag{"entity_name", entityName},
 		nostr.Tag{"entity_type", entityType},
@@ -150,6 +153,7 @@ func ParseCommonGraphEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	subspaceID := ""
 	parents := []string{}
 	var authTag cip.AuthTag
+	var clock *cip.VLC
 
 	for _, tag := range evt.Tags {
 		if len(tag) < 2 {
@@ -164,6 +168,12 @@ func ParseCommonGraphEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
 			}
 			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
 		case "parent":
 			parents = append(parents, tag[1:]...)
 		}
@@ -178,21 +188,21 @@ func ParseCommonGraphEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Parse based on operation type
 	switch operation {
 	case cip.OpProject:
-		return parseProjectEvent(evt, subspaceID, operation, authTag, parents)
+		return parseProjectEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpTask:
-		return parseTaskEvent(evt, subspaceID, operation, authTag, parents)
+		return parseTaskEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpEntity:
-		return parseEntityEvent(evt, subspaceID, operation, authTag, parents)
+		return parseEntityEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpRelation:
-		return parseRelationEvent(evt, subspaceID, operation, authTag, parents)
+		return parseRelationEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpObservation:
-		return parseObservationEvent(evt, subspaceID, operation, authTag, parents)
+		return parseObservationEvent(evt, subspaceID, operation, authTag, parents, clock)
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operation)
 	}
 }
 
-func parseProjectEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ProjectEvent, error) {
+func parseProjectEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ProjectEvent, error) {
 	project := &ProjectEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -200,6 +210,7 @@ func parseProjectEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -224,7 +235,7 @@ func parseProjectEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 	return project, nil
 }
 
-func parseTaskEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*TaskEvent, error) {
+func parseTaskEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*TaskEvent, error) {
 	task := &TaskEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -232,6 +243,7 @@ func parseTaskEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -260,7 +272,7 @@ func parseTaskEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 	return task, nil
 }
 
-func parseEntityEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*EntityEvent, error) {
+func parseEntityEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*EntityEvent, error) {
 	entity := &EntityEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -268,6 +280,7 @@ func parseEntityEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -286,7 +299,7 @@ func parseEntityEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 	return entity, nil
 }
 
-func parseRelationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*RelationEvent, error) {
+func parseRelationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*RelationEvent, error) {
 	relation := &RelationEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -294,6 +307,7 @@ func parseRelationEvent(evt nostr.Event, subspaceID, operation string, authTag c
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
@@ -323,7 +337,7 @@ func parseRelationEvent(evt nostr.Event, subspaceID, operation string, authTag c
 	return relation, nil
 }
 
-func parseObservationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ObservationEvent, error) {
+func parseObservationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ObservationEvent, error) {
 	observation := &ObservationEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -331,6 +345,7 @@ func parseObservationEvent(evt nostr.Event, subspaceID, operation string, authTa
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 
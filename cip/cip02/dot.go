@@ -0,0 +1,75 @@
+package cip02
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// dotAttrs formats attributes as [k="v", ...], skipping empty values
+func dotAttrs(attrs ...string) string {
+	var parts []string
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i+1] != "" {
+			parts = append(parts, attrs[i]+"="+dotQuote(attrs[i+1]))
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func dotProvenance(p Provenance) []string {
+	var createdAt string
+	if p.CreatedAt != 0 {
+		createdAt = strconv.FormatInt(int64(p.CreatedAt), 10)
+	}
+	return []string{"author", p.Author, "event_id", p.EventID, "created_at", createdAt}
+}
+
+// WriteDOT writes the graph as a Graphviz digraph for visualization. Entities are labelled with
+// their type, relations with their type and weight, and observations are note shaped nodes
+// pointing at their entity. Author, event id and created_at are kept as extra attributes,
+// which Graphviz ignores.
+func (g *KnowledgeGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(g.SubspaceID))
+
+	for _, n := range g.exchangeNodes() {
+		attrs := []string{"label", n.Name}
+		if n.Entity != nil {
+			attrs = []string{"label", n.Name + "\n(" + n.Entity.Type + ")", "entity_type", n.Entity.Type}
+			attrs = append(attrs, dotProvenance(n.Entity.Version.Provenance())...)
+		}
+		fmt.Fprintf(bw, "  %s %s;\n", dotQuote(n.Name), dotAttrs(attrs...))
+
+		for _, o := range n.Observations {
+			id := "observation:" + o.EventID
+			attrs := append([]string{"shape", "note", "label", o.Text}, dotProvenance(o.Provenance())...)
+			fmt.Fprintf(bw, "  %s %s;\n", dotQuote(id), dotAttrs(attrs...))
+			fmt.Fprintf(bw, "  %s -> %s [style=\"dashed\", arrowhead=\"none\"];\n", dotQuote(id), dotQuote(n.Name))
+		}
+	}
+
+	for _, r := range g.Relations() {
+		weight := strconv.FormatFloat(r.Weight, 'g', -1, 64)
+		attrs := []string{
+			"label", r.RelationType + " (" + weight + ")",
+			"relation_type", r.RelationType,
+			"context", r.Context,
+			"relation_weight", weight,
+			"description", r.Description,
+		}
+		attrs = append(attrs, dotProvenance(r.Version.Provenance())...)
+		fmt.Fprintf(bw, "  %s -> %s %s;\n", dotQuote(r.From), dotQuote(r.To), dotAttrs(attrs...))
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
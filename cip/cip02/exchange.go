@@ -0,0 +1,161 @@
+package cip02
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var ErrInvalidGraph = errors.New("cip02: invalid graph document")
+
+// SourceTagName is the tag an imported event uses to point at the event it was exported from
+const SourceTagName = "source"
+
+// Provenance identifies the event an exported entity, relation or observation came from
+type Provenance struct {
+	Author    string
+	EventID   string
+	CreatedAt nostr.Timestamp
+}
+
+// Provenance returns the author, event id and created_at of the version
+func (v Version) Provenance() Provenance {
+	return Provenance{Author: v.PubKey, EventID: v.EventID, CreatedAt: v.CreatedAt}
+}
+
+// Provenance returns the author, event id and created_at of the observation
+func (o Observation) Provenance() Provenance {
+	return Provenance{Author: o.PubKey, EventID: o.EventID, CreatedAt: o.CreatedAt}
+}
+
+// Tag returns the source tag of the provenance: ["source", <event id>, <author>, <created_at>]
+func (p Provenance) Tag() nostr.Tag {
+	return nostr.Tag{SourceTagName, p.EventID, p.Author, strconv.FormatInt(int64(p.CreatedAt), 10)}
+}
+
+// SourceOf returns the provenance recorded by the source tag of an imported event
+func SourceOf(evt nostr.Event) (Provenance, bool) {
+	tag := evt.Tags.Find(SourceTagName)
+	if tag == nil {
+		return Provenance{}, false
+	}
+	p := Provenance{EventID: tag[1]}
+	if len(tag) > 2 {
+		p.Author = tag[2]
+	}
+	if len(tag) > 3 {
+		createdAt, err := strconv.ParseInt(tag[3], 10, 64)
+		if err != nil {
+			return Provenance{}, false
+		}
+		p.CreatedAt = nostr.Timestamp(createdAt)
+	}
+	return p, true
+}
+
+// ImportBatch holds the unsigned events built from an imported graph document
+type ImportBatch struct {
+	SubspaceID   string
+	Entities     []*EntityEvent
+	Relations    []*RelationEvent
+	Observations []*ObservationEvent
+}
+
+// Events returns the events of the batch to be signed and published, entities first.
+// Signing them signs the events of the batch in place.
+func (b *ImportBatch) Events() []*nostr.Event {
+	events := make([]*nostr.Event, 0, len(b.Entities)+len(b.Relations)+len(b.Observations))
+	for _, e := range b.Entities {
+		events = append(events, &e.Event)
+	}
+	for _, e := range b.Relations {
+		events = append(events, &e.Event)
+	}
+	for _, e := range b.Observations {
+		events = append(events, &e.Event)
+	}
+	return events
+}
+
+func (b *ImportBatch) addEntity(name, entityType string, src Provenance) error {
+	e, err := NewEntityEvent(b.SubspaceID)
+	if err != nil {
+		return err
+	}
+	e.SetEntityInfo(name, entityType)
+	if err := withSource(e.SubspaceOpEvent, src, e.Validate); err != nil {
+		return fmt.Errorf("%w: entity %q: %w", ErrInvalidGraph, name, err)
+	}
+	b.Entities = append(b.Entities, e)
+	return nil
+}
+
+func (b *ImportBatch) addRelation(r Relation, src Provenance) error {
+	e, err := NewRelationEvent(b.SubspaceID)
+	if err != nil {
+		return err
+	}
+	e.SetRelationInfo(r.From, r.To, r.RelationType, r.Context, r.Weight, r.Description)
+	if err := withSource(e.SubspaceOpEvent, src, e.Validate); err != nil {
+		return fmt.Errorf("%w: relation %q -> %q: %w", ErrInvalidGraph, r.From, r.To, err)
+	}
+	b.Relations = append(b.Relations, e)
+	return nil
+}
+
+func (b *ImportBatch) addObservation(name, text string, src Provenance) error {
+	e, err := NewObservationEvent(b.SubspaceID)
+	if err != nil {
+		return err
+	}
+	e.SetObservationInfo(name, text)
+	if err := withSource(e.SubspaceOpEvent, src, e.Validate); err != nil {
+		return fmt.Errorf("%w: observation of %q: %w", ErrInvalidGraph, name, err)
+	}
+	b.Observations = append(b.Observations, e)
+	return nil
+}
+
+func withSource(op *nostr.SubspaceOpEvent, src Provenance, validate func() error) error {
+	if src.EventID != "" {
+		op.Tags = append(op.Tags, src.Tag())
+	}
+	return validate()
+}
+
+// exchangeNode is an entity as the export formats see it: every name the graph knows, with its
+// entity event if it has one and its observations
+type exchangeNode struct {
+	Name         string
+	Entity       *Entity
+	Observations []Observation
+}
+
+func (g *KnowledgeGraph) exchangeNodes() []exchangeNode {
+	names := make(map[string]struct{}, len(g.entities))
+	for name := range g.entities {
+		names[name] = struct{}{}
+	}
+	for name := range g.observations {
+		names[name] = struct{}{}
+	}
+	for _, r := range g.relations {
+		names[r.From] = struct{}{}
+		names[r.To] = struct{}{}
+	}
+
+	nodes := make([]exchangeNode, 0, len(names))
+	for name := range names {
+		node := exchangeNode{Name: name, Observations: g.observations[name]}
+		if e, ok := g.entities[name]; ok {
+			node.Entity = e
+		}
+		nodes = append(nodes, node)
+	}
+	slices.SortFunc(nodes, func(a, b exchangeNode) int { return strings.Compare(a.Name, b.Name) })
+	return nodes
+}
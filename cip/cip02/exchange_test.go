@@ -0,0 +1,189 @@
+package cip02
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExchangeGraph(t *testing.T) *KnowledgeGraph {
+	g := newTestGraph(t)
+	authored := entity(t, "e7", 140, "bob", "person")
+	authored.PubKey = "0xb0b"
+	_, err := g.Apply(authored)
+	require.NoError(t, err)
+	// an endpoint without an entity event and a description that needs escaping
+	_, err = g.Apply(relation(t, "r9", 150, "desci", "hetu", "part_of", "", 0.5))
+	require.NoError(t, err)
+	described, err := NewRelationEvent(testSID)
+	require.NoError(t, err)
+	described.SetRelationInfo("bob", "alice", "cites", "work", 2, `says "hi"`+"\nand more")
+	described.ID, described.CreatedAt = "r10", 150
+	_, err = g.Apply(described.Event)
+	require.NoError(t, err)
+	return g
+}
+
+// reimport signs nothing, it replays the batch as if its events had been published
+func reimport(t *testing.T, batch *ImportBatch) *KnowledgeGraph {
+	g := NewKnowledgeGraph(batch.SubspaceID)
+	for i, evt := range batch.Events() {
+		evt.ID = fmt.Sprintf("%064x", i+1)
+		_, err := g.Apply(*evt)
+		require.NoError(t, err)
+	}
+	return g
+}
+
+func assertSameGraph(t *testing.T, want, got *KnowledgeGraph) {
+	assert.Equal(t, want.EntityNames(), got.EntityNames())
+	for _, name := range want.EntityNames() {
+		w, _ := want.Entity(name)
+		g, _ := got.Entity(name)
+		assert.Equal(t, w.Type, g.Type, name)
+	}
+
+	wantRelations, gotRelations := want.Relations(), got.Relations()
+	require.Len(t, gotRelations, len(wantRelations))
+	for i, w := range wantRelations {
+		g := gotRelations[i]
+		assert.Equal(t, []any{w.From, w.To, w.RelationType, w.Context, w.Weight, w.Description},
+			[]any{g.From, g.To, g.RelationType, g.Context, g.Weight, g.Description})
+	}
+
+	for _, name := range []string{"alice", "carol"} {
+		var wantTexts, gotTexts []string
+		for _, o := range want.Observations(name) {
+			wantTexts = append(wantTexts, o.Text)
+		}
+		for _, o := range got.Observations(name) {
+			gotTexts = append(gotTexts, o.Text)
+		}
+		assert.ElementsMatch(t, wantTexts, gotTexts, name)
+	}
+}
+
+func sourcesOf(batch *ImportBatch) map[string]Provenance {
+	sources := make(map[string]Provenance)
+	for _, evt := range batch.Events() {
+		if p, ok := SourceOf(*evt); ok {
+			sources[p.EventID] = p
+		}
+	}
+	return sources
+}
+
+func TestJSONLDRoundTrip(t *testing.T) {
+	g := newExchangeGraph(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteJSONLD(&buf))
+	assert.Contains(t, buf.String(), `"@vocab": "urn:cip02:"`)
+	assert.Contains(t, buf.String(), `"author": "0xb0b"`)
+
+	batch, err := ImportJSONLD(&buf, testSID)
+	require.NoError(t, err)
+	// hetu has no entity event so no type to import
+	assert.Len(t, batch.Entities, 4)
+	assert.Len(t, batch.Relations, 6)
+	assert.Len(t, batch.Observations, 3)
+	assertSameGraph(t, g, reimport(t, batch))
+
+	sources := sourcesOf(batch)
+	assert.Equal(t, Provenance{Author: "0xb0b", EventID: "e7", CreatedAt: 140}, sources["e7"])
+	assert.Equal(t, Provenance{EventID: "o2", CreatedAt: 130}, sources["o2"])
+	assert.Contains(t, sources, "r10")
+
+	// endpoints may be plain names and the vocabulary may be expanded
+	batch, err = ImportJSONLD(strings.NewReader(`{"@graph": [
+		{"@type": "urn:cip02:Entity", "name": "x", "entityType": "thing"},
+		{"@type": "Relation", "from": "x", "to": "y", "relationType": "is", "weight": 1}
+	]}`), testSID)
+	require.NoError(t, err)
+	assert.Equal(t, "y", batch.Relations[0].To)
+	_, ok := SourceOf(batch.Entities[0].Event)
+	assert.False(t, ok)
+
+	_, err = ImportJSONLD(strings.NewReader(`{"@graph": [{"@type": "Relation", "to": "y"}]}`), testSID)
+	assert.ErrorIs(t, err, ErrInvalidGraph)
+	_, err = ImportJSONLD(strings.NewReader(`{"@graph": [{"@type": "Person"}]}`), testSID)
+	assert.ErrorIs(t, err, ErrInvalidGraph)
+	_, err = ImportJSONLD(strings.NewReader(`[`), testSID)
+	assert.ErrorIs(t, err, ErrInvalidGraph)
+}
+
+func TestGraphMLRoundTrip(t *testing.T) {
+	g := newExchangeGraph(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteGraphML(&buf))
+	assert.Contains(t, buf.String(), `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	assert.Contains(t, buf.String(), `<data key="author">0xb0b</data>`)
+
+	batch, err := ImportGraphML(&buf, testSID)
+	require.NoError(t, err)
+	assert.Len(t, batch.Entities, 4)
+	assert.Len(t, batch.Relations, 6)
+	assert.Len(t, batch.Observations, 3)
+	assertSameGraph(t, g, reimport(t, batch))
+
+	sources := sourcesOf(batch)
+	assert.Equal(t, Provenance{Author: "0xb0b", EventID: "e7", CreatedAt: 140}, sources["e7"])
+	assert.Equal(t, Provenance{EventID: "o3", CreatedAt: 120}, sources["o3"])
+
+	// a document from another tool, with its own key ids and defaults
+	batch, err = ImportGraphML(strings.NewReader(`<?xml version="1.0"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="entity_type" attr.type="string"><default>concept</default></key>
+  <key id="d1" for="edge" attr.name="relation_type" attr.type="string"><default>related</default></key>
+  <graph edgedefault="directed">
+    <node id="graphs"/>
+    <node id="maths"><data key="d0">field</data></node>
+    <edge source="graphs" target="maths"/>
+  </graph>
+</graphml>`), testSID)
+	require.NoError(t, err)
+	require.Len(t, batch.Entities, 2)
+	assert.Equal(t, "concept", batch.Entities[0].EntityType)
+	assert.Equal(t, "field", batch.Entities[1].EntityType)
+	require.Len(t, batch.Relations, 1)
+	assert.Equal(t, "related", batch.Relations[0].RelationType)
+
+	_, err = ImportGraphML(strings.NewReader(`<graphml><graph edgedefault="directed">
+		<edge source="a" target="b"/></graph></graphml>`), testSID)
+	assert.ErrorIs(t, err, ErrInvalidGraph)
+	_, err = ImportGraphML(strings.NewReader(`<graphml/>`), testSID)
+	assert.ErrorIs(t, err, ErrInvalidGraph)
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := newExchangeGraph(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteDOT(&buf))
+	dot := buf.String()
+
+	assert.True(t, strings.HasPrefix(dot, `digraph "`+testSID+`" {`))
+	assert.Contains(t, dot, `"bob" [label="bob\n(person)", entity_type="person", author="0xb0b", event_id="e7", created_at="140"];`)
+	assert.Contains(t, dot, `"hetu" [label="hetu"];`)
+	assert.Contains(t, dot, `"desci" -> "hetu" [label="part_of (0.5)", relation_type="part_of", relation_weight="0.5", event_id="r9", created_at="150"];`)
+	assert.Contains(t, dot, `description="says \"hi\"\nand more"`)
+	assert.Contains(t, dot, `"observation:o1" -> "alice"`)
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+}
+
+func TestSourceOf(t *testing.T) {
+	p := Provenance{Author: "0xabc", EventID: "id", CreatedAt: 42}
+	evt := nostr.Event{Tags: nostr.Tags{p.Tag()}}
+	got, ok := SourceOf(evt)
+	assert.True(t, ok)
+	assert.Equal(t, p, got)
+
+	_, ok = SourceOf(nostr.Event{Tags: nostr.Tags{{"source", "id", "0xabc", "x"}}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,499 @@
+package cip02
+
+import (
+	"cmp"
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+var (
+	ErrUnknownEntity    = errors.New("cip02: unknown entity")
+	ErrNoPath           = errors.New("cip02: no path between entities")
+	ErrNegativeWeight   = errors.New("cip02: negative relation cost")
+	ErrNotKnowledgeNode = errors.New("cip02: not an entity, relation or observation event")
+)
+
+// Version identifies the event an entity or relation was last set by, to tell which of two
+// versions is the latest
+type Version struct {
+	EventID   string
+	PubKey    string
+	CreatedAt nostr.Timestamp
+	Clock     *cip.VLC
+}
+
+func versionOf(op *nostr.SubspaceOpEvent) Version {
+	return Version{EventID: op.ID, PubKey: op.PubKey, CreatedAt: op.CreatedAt, Clock: op.Clock}
+}
+
+// After reports whether v supersedes other: by their clocks when one happened before the
+// other, else by created_at, else by event id so that every replica picks the same one
+func (v Version) After(other Version) bool {
+	if v.Clock != nil && other.Clock != nil {
+		switch v.Clock.Compare(other.Clock) {
+		case cip.OrderAfter:
+			return true
+		case cip.OrderBefore:
+			return false
+		}
+	}
+	if v.CreatedAt != other.CreatedAt {
+		return v.CreatedAt > other.CreatedAt
+	}
+	return v.EventID > other.EventID
+}
+
+// Entity is a node of the knowledge graph
+type Entity struct {
+	Name    string
+	Type    string
+	Version Version
+}
+
+// Observation is a fact recorded about an entity
+type Observation struct {
+	EntityName string
+	Text       string
+	PubKey     string
+	EventID    string
+	CreatedAt  nostr.Timestamp
+}
+
+// Relation is a directed edge of the knowledge graph, only the latest one per
+// (from, to, relation type) is kept
+type Relation struct {
+	From         string
+	To           string
+	RelationType string
+	Context      string
+	Weight       float64
+	Description  string
+	Version      Version
+}
+
+type relationKey struct {
+	from, to, relationType string
+}
+
+func (r *Relation) key() relationKey {
+	return relationKey{r.From, r.To, r.RelationType}
+}
+
+// KnowledgeGraph indexes the entity, relation and observation events of a subspace.
+// Relations and observations may name entities that have no entity event (yet), they are
+// still part of the graph.
+type KnowledgeGraph struct {
+	SubspaceID string
+
+	entities     map[string]*Entity
+	relations    map[relationKey]*Relation
+	outgoing     map[string]map[relationKey]*Relation
+	incoming     map[string]map[relationKey]*Relation
+	observations map[string][]Observation
+	seen         map[string]struct{}
+}
+
+// NewKnowledgeGraph creates an empty graph for the events of a subspace
+func NewKnowledgeGraph(subspaceID string) *KnowledgeGraph {
+	return &KnowledgeGraph{
+		SubspaceID:   subspaceID,
+		entities:     make(map[string]*Entity),
+		relations:    make(map[relationKey]*Relation),
+		outgoing:     make(map[string]map[relationKey]*Relation),
+		incoming:     make(map[string]map[relationKey]*Relation),
+		observations: make(map[string][]Observation),
+		seen:         make(map[string]struct{}),
+	}
+}
+
+// Replay applies all the events and returns the errors of the rejected ones.
+// Events other than entities, relations and observations of the subspace are ignored.
+func (g *KnowledgeGraph) Replay(events []nostr.Event) []error {
+	var errs []error
+	for _, evt := range events {
+		if _, err := g.Apply(evt); err != nil && !errors.Is(err, ErrNotKnowledgeNode) {
+			errs = append(errs, fmt.Errorf("event %s: %w", evt.ID, err))
+		}
+	}
+	return errs
+}
+
+// Apply parses and validates an entity, relation or observation event of the subspace and adds
+// it to the graph. It reports whether the graph changed.
+func (g *KnowledgeGraph) Apply(evt nostr.Event) (bool, error) {
+	switch evt.Kind {
+	case cip.KindCommonGraphEntity, cip.KindCommonGraphRelation, cip.KindCommonGraphObservation:
+	default:
+		return false, fmt.Errorf("%w: kind %d", ErrNotKnowledgeNode, evt.Kind)
+	}
+	if evt.Tags.FindWithValue("sid", g.SubspaceID) == nil {
+		return false, fmt.Errorf("%w: not in subspace %s", ErrNotKnowledgeNode, g.SubspaceID)
+	}
+
+	op, err := ParseCommonGraphEvent(evt)
+	if err != nil {
+		return false, err
+	}
+	if v, ok := op.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return false, err
+		}
+	}
+
+	switch e := op.(type) {
+	case *EntityEvent:
+		return g.AddEntity(e), nil
+	case *RelationEvent:
+		return g.AddRelation(e), nil
+	case *ObservationEvent:
+		return g.AddObservation(e), nil
+	}
+	return false, nil
+}
+
+// AddEntity sets the type of an entity unless a later version of it was added already
+func (g *KnowledgeGraph) AddEntity(e *EntityEvent) bool {
+	version := versionOf(e.SubspaceOpEvent)
+	if current, ok := g.entities[e.EntityName]; ok && !version.After(current.Version) {
+		return false
+	}
+	g.entities[e.EntityName] = &Entity{Name: e.EntityName, Type: e.EntityType, Version: version}
+	return true
+}
+
+// AddRelation sets the relation between two entities unless a later version of it was added already
+func (g *KnowledgeGraph) AddRelation(e *RelationEvent) bool {
+	r := &Relation{
+		From:         e.From,
+		To:           e.To,
+		RelationType: e.RelationType,
+		Context:      e.Context,
+		Weight:       e.Weight,
+		Description:  e.Description,
+		Version:      versionOf(e.SubspaceOpEvent),
+	}
+	if current, ok := g.relations[r.key()]; ok && !r.Version.After(current.Version) {
+		return false
+	}
+
+	g.link(r)
+	return true
+}
+
+// link indexes a relation, replacing the one with the same key
+func (g *KnowledgeGraph) link(r *Relation) {
+	key := r.key()
+	g.relations[key] = r
+	if g.outgoing[r.From] == nil {
+		g.outgoing[r.From] = make(map[relationKey]*Relation)
+	}
+	g.outgoing[r.From][key] = r
+	if g.incoming[r.To] == nil {
+		g.incoming[r.To] = make(map[relationKey]*Relation)
+	}
+	g.incoming[r.To][key] = r
+}
+
+// AddObservation attaches an observation to its entity, observations are kept in created_at order
+func (g *KnowledgeGraph) AddObservation(e *ObservationEvent) bool {
+	if _, ok := g.seen[e.ID]; ok {
+		return false
+	}
+	g.seen[e.ID] = struct{}{}
+
+	obs := Observation{
+		EntityName: e.EntityName,
+		Text:       e.Observation,
+		PubKey:     e.PubKey,
+		EventID:    e.ID,
+		CreatedAt:  e.CreatedAt,
+	}
+	list := g.observations[e.EntityName]
+	i, _ := slices.BinarySearchFunc(list, obs, compareObservations)
+	g.observations[e.EntityName] = slices.Insert(list, i, obs)
+	return true
+}
+
+func compareObservations(a, b Observation) int {
+	if a.CreatedAt != b.CreatedAt {
+		return cmp.Compare(a.CreatedAt, b.CreatedAt)
+	}
+	return strings.Compare(a.EventID, b.EventID)
+}
+
+// Entity returns the entity with the given name, if it has an entity event
+func (g *KnowledgeGraph) Entity(name string) (Entity, bool) {
+	if e, ok := g.entities[name]; ok {
+		return *e, true
+	}
+	return Entity{}, false
+}
+
+// FindEntity resolves a name to an entity, trying the exact name first and then ignoring case
+// and surrounding spaces
+func (g *KnowledgeGraph) FindEntity(name string) (Entity, bool) {
+	if e, ok := g.Entity(name); ok {
+		return e, true
+	}
+	name = strings.TrimSpace(name)
+	for _, candidate := range g.EntityNames() {
+		if strings.EqualFold(candidate, name) {
+			return *g.entities[candidate], true
+		}
+	}
+	return Entity{}, false
+}
+
+// EntityNames returns the names of the entities that have an entity event, sorted
+func (g *KnowledgeGraph) EntityNames() []string {
+	names := make([]string, 0, len(g.entities))
+	for name := range g.entities {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// EntitiesOfType returns the entities with the given type, sorted by name
+func (g *KnowledgeGraph) EntitiesOfType(entityType string) []Entity {
+	var entities []Entity
+	for _, name := range g.EntityNames() {
+		if e := g.entities[name]; e.Type == entityType {
+			entities = append(entities, *e)
+		}
+	}
+	return entities
+}
+
+// Observations returns the observations about an entity in created_at order
+func (g *KnowledgeGraph) Observations(name string) []Observation {
+	return slices.Clone(g.observations[name])
+}
+
+// Relation returns the latest relation of a type between two entities
+func (g *KnowledgeGraph) Relation(from, to, relationType string) (Relation, bool) {
+	if r, ok := g.relations[relationKey{from, to, relationType}]; ok {
+		return *r, true
+	}
+	return Relation{}, false
+}
+
+// Relations returns all the relations, sorted by from, to and type
+func (g *KnowledgeGraph) Relations() []Relation {
+	return sortedRelations(g.relations)
+}
+
+// Outgoing returns the relations from an entity, sorted by to and type
+func (g *KnowledgeGraph) Outgoing(name string) []Relation {
+	return sortedRelations(g.outgoing[name])
+}
+
+// Incoming returns the relations to an entity, sorted by from and type
+func (g *KnowledgeGraph) Incoming(name string) []Relation {
+	return sortedRelations(g.incoming[name])
+}
+
+func sortedRelations(relations map[relationKey]*Relation) []Relation {
+	list := make([]Relation, 0, len(relations))
+	for _, r := range relations {
+		list = append(list, *r)
+	}
+	slices.SortFunc(list, func(a, b Relation) int {
+		return cmpRelationKeys(a.key(), b.key())
+	})
+	return list
+}
+
+func cmpRelationKeys(a, b relationKey) int {
+	if c := strings.Compare(a.from, b.from); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.to, b.to); c != 0 {
+		return c
+	}
+	return strings.Compare(a.relationType, b.relationType)
+}
+
+// Neighbors returns the names of the entities related to an entity in either direction, sorted
+func (g *KnowledgeGraph) Neighbors(name string) []string {
+	set := make(map[string]struct{})
+	for _, r := range g.outgoing[name] {
+		set[r.To] = struct{}{}
+	}
+	for _, r := range g.incoming[name] {
+		set[r.From] = struct{}{}
+	}
+	delete(set, name)
+
+	neighbors := make([]string, 0, len(set))
+	for n := range set {
+		neighbors = append(neighbors, n)
+	}
+	slices.Sort(neighbors)
+	return neighbors
+}
+
+// hasNode reports whether an entity is declared or takes part in a relation or observation
+func (g *KnowledgeGraph) hasNode(name string) bool {
+	_, declared := g.entities[name]
+	return declared || len(g.outgoing[name]) > 0 || len(g.incoming[name]) > 0 || len(g.observations[name]) > 0
+}
+
+// PathOption changes how ShortestPath walks the graph
+type PathOption func(*pathOptions)
+
+type pathOptions struct {
+	undirected bool
+	cost       func(Relation) float64
+}
+
+// Undirected lets ShortestPath follow relations in both directions
+func Undirected() PathOption {
+	return func(o *pathOptions) { o.undirected = true }
+}
+
+// WithCost sets the cost of following a relation, which is its weight by default.
+// Relations with a NaN or infinite cost are not followed.
+func WithCost(cost func(Relation) float64) PathOption {
+	return func(o *pathOptions) { o.cost = cost }
+}
+
+// ShortestPath finds the path from one entity to another with the lowest total cost, following
+// relations from their from to their to. Costs can't be negative.
+// It returns the relations along the path and its cost.
+func (g *KnowledgeGraph) ShortestPath(from, to string, opts ...PathOption) ([]Relation, float64, error) {
+	o := pathOptions{cost: func(r Relation) float64 { return r.Weight }}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for _, name := range []string{from, to} {
+		if !g.hasNode(name) {
+			return nil, 0, fmt.Errorf("%w: %s", ErrUnknownEntity, name)
+		}
+	}
+
+	dist := map[string]float64{from: 0}
+	via := make(map[string]*Relation) // the relation the best path to a node arrives by
+	done := make(map[string]struct{})
+	queue := &pathQueue{{node: from}}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		if _, ok := done[item.node]; ok {
+			continue
+		}
+		done[item.node] = struct{}{}
+		if item.node == to {
+			break
+		}
+
+		edges := g.outgoing[item.node]
+		if o.undirected {
+			edges = mergeEdges(edges, g.incoming[item.node])
+		}
+		for _, r := range sortedRelations(edges) {
+			next := r.To
+			if next == item.node {
+				next = r.From
+			}
+			cost := o.cost(r)
+			if cost < 0 {
+				return nil, 0, fmt.Errorf("%w: %s -%s-> %s costs %v", ErrNegativeWeight, r.From, r.RelationType, r.To, cost)
+			}
+			if math.IsNaN(cost) || math.IsInf(cost, 1) {
+				continue
+			}
+			if d, ok := dist[next]; ok && d <= item.dist+cost {
+				continue
+			}
+			dist[next] = item.dist + cost
+			via[next] = &r
+			heap.Push(queue, pathItem{node: next, dist: item.dist + cost})
+		}
+	}
+
+	if _, ok := done[to]; !ok {
+		return nil, 0, fmt.Errorf("%w: %s -> %s", ErrNoPath, from, to)
+	}
+	var path []Relation
+	for node := to; node != from; {
+		r := via[node]
+		path = append(path, *r)
+		if r.To == node {
+			node = r.From
+		} else {
+			node = r.To
+		}
+	}
+	slices.Reverse(path)
+	return path, dist[to], nil
+}
+
+func mergeEdges(a, b map[relationKey]*Relation) map[relationKey]*Relation {
+	merged := make(map[relationKey]*Relation, len(a)+len(b))
+	for k, r := range a {
+		merged[k] = r
+	}
+	for k, r := range b {
+		merged[k] = r
+	}
+	return merged
+}
+
+type pathItem struct {
+	node string
+	dist float64
+}
+
+// pathQueue is a min-heap of nodes by distance, ties broken by name to keep paths deterministic
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].node < q[j].node
+}
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)   { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// Subgraph returns a new graph with the relations of the given context, the entities they
+// relate and the observations about those entities
+func (g *KnowledgeGraph) Subgraph(context string) *KnowledgeGraph {
+	sub := NewKnowledgeGraph(g.SubspaceID)
+	for _, r := range g.relations {
+		if r.Context != context {
+			continue
+		}
+		rel := *r
+		sub.link(&rel)
+
+		for _, name := range []string{rel.From, rel.To} {
+			if e, ok := g.entities[name]; ok {
+				entity := *e
+				sub.entities[name] = &entity
+			}
+			if obs, ok := g.observations[name]; ok {
+				sub.observations[name] = slices.Clone(obs)
+				for _, o := range obs {
+					sub.seen[o.EventID] = struct{}{}
+				}
+			}
+		}
+	}
+	return sub
+}
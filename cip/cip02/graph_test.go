@@ -0,0 +1,188 @@
+package cip02
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSID = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+func entity(t *testing.T, id string, createdAt nostr.Timestamp, name, entityType string) nostr.Event {
+	e, err := NewEntityEvent(testSID)
+	require.NoError(t, err)
+	e.SetEntityInfo(name, entityType)
+	e.ID, e.CreatedAt = id, createdAt
+	return e.Event
+}
+
+func relation(t *testing.T, id string, createdAt nostr.Timestamp, from, to, relationType, context string, weight float64) nostr.Event {
+	e, err := NewRelationEvent(testSID)
+	require.NoError(t, err)
+	e.SetRelationInfo(from, to, relationType, context, weight, "")
+	e.ID, e.CreatedAt = id, createdAt
+	return e.Event
+}
+
+func observation(t *testing.T, id string, createdAt nostr.Timestamp, name, text string) nostr.Event {
+	e, err := NewObservationEvent(testSID)
+	require.NoError(t, err)
+	e.SetObservationInfo(name, text)
+	e.ID, e.CreatedAt = id, createdAt
+	return e.Event
+}
+
+func newTestGraph(t *testing.T) *KnowledgeGraph {
+	g := NewKnowledgeGraph(testSID)
+	errs := g.Replay([]nostr.Event{
+		entity(t, "e1", 100, "alice", "person"),
+		entity(t, "e2", 100, "bob", "person"),
+		entity(t, "e3", 100, "carol", "person"),
+		entity(t, "e4", 100, "desci", "project"),
+		relation(t, "r1", 110, "alice", "bob", "knows", "work", 1),
+		relation(t, "r2", 110, "bob", "carol", "knows", "work", 1),
+		relation(t, "r3", 110, "alice", "carol", "knows", "social", 5),
+		relation(t, "r4", 110, "carol", "desci", "works_on", "work", 2),
+		observation(t, "o2", 130, "alice", "likes graphs"),
+		observation(t, "o1", 120, "alice", "is a researcher"),
+		observation(t, "o3", 120, "carol", "maintains desci"),
+	})
+	require.Empty(t, errs)
+	return g
+}
+
+func TestKnowledgeGraphEntities(t *testing.T) {
+	g := newTestGraph(t)
+
+	alice, ok := g.Entity("alice")
+	require.True(t, ok)
+	assert.Equal(t, "person", alice.Type)
+
+	found, ok := g.FindEntity(" Alice ")
+	require.True(t, ok)
+	assert.Equal(t, "alice", found.Name)
+	_, ok = g.FindEntity("dave")
+	assert.False(t, ok)
+
+	// a later entity event changes the type, an earlier one doesn't
+	changed, err := g.Apply(entity(t, "e5", 90, "alice", "robot"))
+	require.NoError(t, err)
+	assert.False(t, changed)
+	changed, err = g.Apply(entity(t, "e6", 200, "alice", "agent"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Len(t, g.EntitiesOfType("person"), 2)
+	assert.Equal(t, "alice", g.EntitiesOfType("agent")[0].Name)
+
+	obs := g.Observations("alice")
+	require.Len(t, obs, 2)
+	assert.Equal(t, "is a researcher", obs[0].Text)
+	assert.Equal(t, "likes graphs", obs[1].Text)
+
+	changed, err = g.Apply(observation(t, "o1", 120, "alice", "is a researcher"))
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// invalid events are rejected, events of other kinds or subspaces skipped
+	_, err = g.Apply(relation(t, "r9", 300, "", "bob", "knows", "", 1))
+	assert.Error(t, err)
+	other := entity(t, "e9", 300, "eve", "person")
+	other.Tags = nostr.Tags{{"sid", "0xother"}}
+	assert.Empty(t, g.Replay([]nostr.Event{other, {Kind: nostr.KindTextNote, ID: "note"}}))
+	_, ok = g.Entity("eve")
+	assert.False(t, ok)
+}
+
+func TestKnowledgeGraphRelations(t *testing.T) {
+	g := newTestGraph(t)
+
+	assert.Equal(t, []string{"bob", "carol"}, g.Neighbors("alice"))
+	assert.Equal(t, []string{"alice", "bob", "desci"}, g.Neighbors("carol"))
+	assert.Len(t, g.Outgoing("alice"), 2)
+	assert.Len(t, g.Incoming("carol"), 2)
+
+	// the latest weight wins, by clock first and then by created_at
+	_, err := g.Apply(relation(t, "r5", 105, "alice", "bob", "knows", "work", 9))
+	require.NoError(t, err)
+	r, _ := g.Relation("alice", "bob", "knows")
+	assert.Equal(t, 1.0, r.Weight)
+
+	newer := relation(t, "r6", 150, "alice", "bob", "knows", "work", 3)
+	_, err = g.Apply(newer)
+	require.NoError(t, err)
+	r, _ = g.Relation("alice", "bob", "knows")
+	assert.Equal(t, 3.0, r.Weight)
+
+	clock := cip.NewVLC()
+	clock.Set(1, 2)
+	newer = relation(t, "r7", 160, "bob", "carol", "knows", "work", 4)
+	newer.Tags = append(newer.Tags, nostr.Tag{"vlc", clock.String()})
+	_, err = g.Apply(newer)
+	require.NoError(t, err)
+	clock.Set(1, 1)
+	older := relation(t, "r8", 170, "bob", "carol", "knows", "work", 7)
+	older.Tags = append(older.Tags, nostr.Tag{"vlc", clock.String()})
+	changed, err := g.Apply(older)
+	require.NoError(t, err)
+	assert.False(t, changed, "a causally older relation should lose despite its created_at")
+	r, _ = g.Relation("bob", "carol", "knows")
+	assert.Equal(t, 4.0, r.Weight)
+}
+
+func TestKnowledgeGraphShortestPath(t *testing.T) {
+	g := newTestGraph(t)
+
+	path, cost, err := g.ShortestPath("alice", "desci")
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, cost)
+	require.Len(t, path, 3)
+	assert.Equal(t, []string{"bob", "carol", "desci"}, []string{path[0].To, path[1].To, path[2].To})
+
+	// a custom cost makes the direct relation cheaper
+	path, cost, err = g.ShortestPath("alice", "desci", WithCost(func(r Relation) float64 {
+		if r.Context == "social" {
+			return 0
+		}
+		return r.Weight
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, cost)
+	assert.Len(t, path, 2)
+
+	// relations are directed unless asked otherwise
+	_, _, err = g.ShortestPath("desci", "alice")
+	assert.ErrorIs(t, err, ErrNoPath)
+	path, _, err = g.ShortestPath("desci", "alice", Undirected())
+	require.NoError(t, err)
+	assert.Equal(t, "desci", path[0].To)
+	assert.Equal(t, "alice", path[len(path)-1].From)
+
+	_, _, err = g.ShortestPath("alice", "dave")
+	assert.ErrorIs(t, err, ErrUnknownEntity)
+
+	_, _, err = g.ShortestPath("alice", "desci", WithCost(func(r Relation) float64 { return -1 }))
+	assert.ErrorIs(t, err, ErrNegativeWeight)
+}
+
+func TestKnowledgeGraphSubgraph(t *testing.T) {
+	g := newTestGraph(t)
+
+	work := g.Subgraph("work")
+	assert.Len(t, work.Relations(), 3)
+	assert.Equal(t, []string{"alice", "bob", "carol", "desci"}, work.EntityNames())
+	assert.Len(t, work.Observations("carol"), 1)
+
+	social := g.Subgraph("social")
+	assert.Len(t, social.Relations(), 1)
+	assert.Equal(t, []string{"alice", "carol"}, social.EntityNames())
+	_, _, err := social.ShortestPath("alice", "desci")
+	assert.ErrorIs(t, err, ErrUnknownEntity)
+
+	// the subgraph is a copy
+	_, err = work.Apply(relation(t, "r9", 300, "desci", "alice", "funds", "work", 1))
+	require.NoError(t, err)
+	assert.Len(t, g.Relations(), 4)
+}
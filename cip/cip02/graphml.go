@@ -0,0 +1,276 @@
+package cip02
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const graphmlNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// kinds of GraphML nodes and edges, observations are nodes linked to their entity
+const (
+	graphmlEntity      = "entity"
+	graphmlRelation    = "relation"
+	graphmlObservation = "observation"
+)
+
+// graphmlKeys are the attributes written by WriteGraphML, attr.name is what ImportGraphML
+// looks at so documents edited by other tools still import
+var graphmlKeys = []graphmlKey{
+	{ID: "kind", For: "all", Name: "kind", Type: "string"},
+	{ID: "name", For: "node", Name: "name", Type: "string"},
+	{ID: "entity_type", For: "node", Name: "entity_type", Type: "string"},
+	{ID: "text", For: "node", Name: "text", Type: "string"},
+	{ID: "relation_type", For: "edge", Name: "relation_type", Type: "string"},
+	{ID: "context", For: "edge", Name: "context", Type: "string"},
+	{ID: "weight", For: "edge", Name: "weight", Type: "double"},
+	{ID: "description", For: "edge", Name: "description", Type: "string"},
+	{ID: "author", For: "all", Name: "author", Type: "string"},
+	{ID: "event_id", For: "all", Name: "event_id", Type: "string"},
+	{ID: "created_at", For: "all", Name: "created_at", Type: "long"},
+}
+
+type graphmlDocument struct {
+	XMLName xml.Name       `xml:"graphml"`
+	XMLNS   string         `xml:"xmlns,attr,omitempty"`
+	Keys    []graphmlKey   `xml:"key"`
+	Graphs  []graphmlGraph `xml:"graph"`
+}
+
+type graphmlKey struct {
+	ID      string `xml:"id,attr"`
+	For     string `xml:"for,attr,omitempty"`
+	Name    string `xml:"attr.name,attr,omitempty"`
+	Type    string `xml:"attr.type,attr,omitempty"`
+	Default string `xml:"default,omitempty"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr,omitempty"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	ID     string        `xml:"id,attr,omitempty"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphmlAttrs collects the data of an element, skipping empty values
+type graphmlAttrs []graphmlData
+
+func (a *graphmlAttrs) set(key, value string) {
+	if value != "" {
+		*a = append(*a, graphmlData{Key: key, Value: value})
+	}
+}
+
+func (a *graphmlAttrs) provenance(p Provenance) {
+	a.set("author", p.Author)
+	a.set("event_id", p.EventID)
+	if p.CreatedAt != 0 {
+		a.set("created_at", strconv.FormatInt(int64(p.CreatedAt), 10))
+	}
+}
+
+// WriteGraphML writes the graph as a directed GraphML graph. Entities and relations become
+// nodes and edges, observations become nodes with an edge to their entity. Author, event id
+// and created_at of the events are kept as data of every node and edge.
+func (g *KnowledgeGraph) WriteGraphML(w io.Writer) error {
+	graph := graphmlGraph{ID: g.SubspaceID, EdgeDefault: "directed"}
+
+	ids := make(map[string]string)
+	var observations int
+	for i, n := range g.exchangeNodes() {
+		id := "n" + strconv.Itoa(i)
+		ids[n.Name] = id
+
+		var attrs graphmlAttrs
+		attrs.set("kind", graphmlEntity)
+		attrs.set("name", n.Name)
+		if n.Entity != nil {
+			attrs.set("entity_type", n.Entity.Type)
+			attrs.provenance(n.Entity.Version.Provenance())
+		}
+		graph.Nodes = append(graph.Nodes, graphmlNode{ID: id, Data: attrs})
+
+		for _, o := range n.Observations {
+			obsID := "o" + strconv.Itoa(observations)
+			observations++
+
+			var attrs graphmlAttrs
+			attrs.set("kind", graphmlObservation)
+			attrs.set("text", o.Text)
+			attrs.provenance(o.Provenance())
+			graph.Nodes = append(graph.Nodes, graphmlNode{ID: obsID, Data: attrs})
+			graph.Edges = append(graph.Edges, graphmlEdge{
+				Source: obsID,
+				Target: id,
+				Data:   []graphmlData{{Key: "kind", Value: graphmlObservation}},
+			})
+		}
+	}
+	for _, r := range g.Relations() {
+		var attrs graphmlAttrs
+		attrs.set("kind", graphmlRelation)
+		attrs.set("relation_type", r.RelationType)
+		attrs.set("context", r.Context)
+		attrs.set("weight", strconv.FormatFloat(r.Weight, 'g', -1, 64))
+		attrs.set("description", r.Description)
+		attrs.provenance(r.Version.Provenance())
+		graph.Edges = append(graph.Edges, graphmlEdge{Source: ids[r.From], Target: ids[r.To], Data: attrs})
+	}
+	for i := range graph.Edges {
+		graph.Edges[i].ID = "e" + strconv.Itoa(i)
+	}
+
+	doc := graphmlDocument{XMLNS: graphmlNamespace, Keys: graphmlKeys, Graphs: []graphmlGraph{graph}}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ImportGraphML reads the first graph of a GraphML document as written by WriteGraphML into
+// events of the given subspace. Nodes without a kind are entities and edges without a kind are
+// relations; an entity without a name is named after its node id, and entities without a type
+// only get their observations imported.
+func ImportGraphML(r io.Reader, subspaceID string) (*ImportBatch, error) {
+	var doc graphmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGraph, err)
+	}
+	if len(doc.Graphs) == 0 {
+		return nil, fmt.Errorf("%w: no graph", ErrInvalidGraph)
+	}
+	graph := doc.Graphs[0]
+
+	keys := make(map[string]graphmlKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		keys[k.ID] = k
+	}
+	attrsOf := func(element string, data []graphmlData) map[string]string {
+		attrs := make(map[string]string)
+		for _, k := range doc.Keys {
+			if k.Default != "" && (k.For == element || k.For == "all" || k.For == "") {
+				attrs[keyName(k)] = k.Default
+			}
+		}
+		for _, d := range data {
+			if k, ok := keys[d.Key]; ok {
+				attrs[keyName(k)] = d.Value
+			} else {
+				attrs[d.Key] = d.Value
+			}
+		}
+		return attrs
+	}
+
+	nodes := make(map[string]map[string]string, len(graph.Nodes))
+	names := make(map[string]string, len(graph.Nodes))
+	for _, n := range graph.Nodes {
+		attrs := attrsOf("node", n.Data)
+		nodes[n.ID] = attrs
+		names[n.ID] = n.ID
+		if name := attrs["name"]; name != "" {
+			names[n.ID] = name
+		}
+	}
+
+	batch := &ImportBatch{SubspaceID: subspaceID}
+	for _, n := range graph.Nodes {
+		attrs := nodes[n.ID]
+		if kind := attrs["kind"]; (kind != "" && kind != graphmlEntity) || attrs["entity_type"] == "" {
+			continue
+		}
+		src, err := graphmlProvenance(attrs)
+		if err == nil {
+			err = batch.addEntity(names[n.ID], attrs["entity_type"], src)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range graph.Edges {
+		attrs := attrsOf("edge", e.Data)
+		if _, ok := nodes[e.Source]; !ok {
+			return nil, fmt.Errorf("%w: edge from unknown node %q", ErrInvalidGraph, e.Source)
+		}
+		if _, ok := nodes[e.Target]; !ok {
+			return nil, fmt.Errorf("%w: edge to unknown node %q", ErrInvalidGraph, e.Target)
+		}
+
+		var err error
+		switch attrs["kind"] {
+		case "", graphmlRelation:
+			rel := Relation{
+				From:         names[e.Source],
+				To:           names[e.Target],
+				RelationType: attrs["relation_type"],
+				Context:      attrs["context"],
+				Description:  attrs["description"],
+			}
+			if weight := attrs["weight"]; weight != "" {
+				if rel.Weight, err = strconv.ParseFloat(weight, 64); err != nil {
+					return nil, fmt.Errorf("%w: edge %q weight: %w", ErrInvalidGraph, e.ID, err)
+				}
+			}
+			var src Provenance
+			if src, err = graphmlProvenance(attrs); err == nil {
+				err = batch.addRelation(rel, src)
+			}
+		case graphmlObservation:
+			obs := nodes[e.Source]
+			var src Provenance
+			if src, err = graphmlProvenance(obs); err == nil {
+				err = batch.addObservation(names[e.Target], obs["text"], src)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
+
+func keyName(k graphmlKey) string {
+	if k.Name != "" {
+		return k.Name
+	}
+	return k.ID
+}
+
+func graphmlProvenance(attrs map[string]string) (Provenance, error) {
+	p := Provenance{Author: attrs["author"], EventID: attrs["event_id"]}
+	if createdAt := attrs["created_at"]; createdAt != "" {
+		ts, err := strconv.ParseInt(createdAt, 10, 64)
+		if err != nil {
+			return Provenance{}, fmt.Errorf("%w: created_at %q", ErrInvalidGraph, createdAt)
+		}
+		p.CreatedAt = nostr.Timestamp(ts)
+	}
+	return p, nil
+}
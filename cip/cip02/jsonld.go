@@ -0,0 +1,165 @@
+package cip02
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// JSONLDVocab is the vocabulary the exported JSON-LD terms expand to
+const JSONLDVocab = "urn:cip02:"
+
+var jsonldContext = map[string]any{
+	"@vocab": JSONLDVocab,
+	"xsd":    "http://www.w3.org/2001/XMLSchema#",
+	"from":   map[string]string{"@type": "@id"},
+	"to":     map[string]string{"@type": "@id"},
+	"weight": map[string]string{"@type": "xsd:double"},
+}
+
+type jsonldDocument struct {
+	Context  any          `json:"@context"`
+	ID       string       `json:"@id,omitempty"`
+	Subspace string       `json:"subspace,omitempty"`
+	Graph    []jsonldNode `json:"@graph"`
+}
+
+type jsonldProvenance struct {
+	Author    string          `json:"author,omitempty"`
+	EventID   string          `json:"eventId,omitempty"`
+	CreatedAt nostr.Timestamp `json:"createdAt,omitempty"`
+}
+
+// jsonldNode is either an Entity or a Relation
+type jsonldNode struct {
+	ID   string `json:"@id,omitempty"`
+	Type string `json:"@type"`
+
+	Name         string              `json:"name,omitempty"`
+	EntityType   string              `json:"entityType,omitempty"`
+	Observations []jsonldObservation `json:"observations,omitempty"`
+
+	From         string   `json:"from,omitempty"`
+	To           string   `json:"to,omitempty"`
+	RelationType string   `json:"relationType,omitempty"`
+	Context      string   `json:"context,omitempty"`
+	Weight       *float64 `json:"weight,omitempty"`
+	Description  string   `json:"description,omitempty"`
+
+	jsonldProvenance
+}
+
+type jsonldObservation struct {
+	Text string `json:"text"`
+
+	jsonldProvenance
+}
+
+func jsonldEntityID(subspaceID, name string) string {
+	return JSONLDVocab + subspaceID + ":entity:" + url.PathEscape(name)
+}
+
+// WriteJSONLD writes the graph as a JSON-LD document: one Entity node per entity, with its
+// observations, and one Relation node per relation. Author, event id and created_at of the
+// events are kept on every node and observation.
+func (g *KnowledgeGraph) WriteJSONLD(w io.Writer) error {
+	doc := jsonldDocument{
+		Context:  jsonldContext,
+		ID:       JSONLDVocab + g.SubspaceID,
+		Subspace: g.SubspaceID,
+		Graph:    []jsonldNode{},
+	}
+
+	for _, n := range g.exchangeNodes() {
+		node := jsonldNode{ID: jsonldEntityID(g.SubspaceID, n.Name), Type: "Entity", Name: n.Name}
+		if n.Entity != nil {
+			node.EntityType = n.Entity.Type
+			node.jsonldProvenance = jsonldProvenance(n.Entity.Version.Provenance())
+		}
+		for _, o := range n.Observations {
+			node.Observations = append(node.Observations, jsonldObservation{
+				Text:             o.Text,
+				jsonldProvenance: jsonldProvenance(o.Provenance()),
+			})
+		}
+		doc.Graph = append(doc.Graph, node)
+	}
+	for _, r := range g.Relations() {
+		weight := r.Weight
+		doc.Graph = append(doc.Graph, jsonldNode{
+			Type:             "Relation",
+			From:             jsonldEntityID(g.SubspaceID, r.From),
+			To:               jsonldEntityID(g.SubspaceID, r.To),
+			RelationType:     r.RelationType,
+			Context:          r.Context,
+			Weight:           &weight,
+			Description:      r.Description,
+			jsonldProvenance: jsonldProvenance(r.Version.Provenance()),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// ImportJSONLD reads a JSON-LD document as written by WriteJSONLD into events of the given
+// subspace. Relations may name their endpoints by the @id of an Entity node or by name.
+// Entities without a type only get their observations imported.
+func ImportJSONLD(r io.Reader, subspaceID string) (*ImportBatch, error) {
+	var doc jsonldDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGraph, err)
+	}
+
+	names := make(map[string]string)
+	for _, node := range doc.Graph {
+		if node.Type == "Entity" && node.ID != "" {
+			names[node.ID] = node.Name
+		}
+	}
+	resolve := func(ref string) string {
+		if name, ok := names[ref]; ok {
+			return name
+		}
+		return ref
+	}
+
+	batch := &ImportBatch{SubspaceID: subspaceID}
+	for _, node := range doc.Graph {
+		var err error
+		switch strings.TrimPrefix(node.Type, JSONLDVocab) {
+		case "Entity":
+			if node.EntityType != "" {
+				err = batch.addEntity(node.Name, node.EntityType, Provenance(node.jsonldProvenance))
+			}
+			for _, o := range node.Observations {
+				if err == nil {
+					err = batch.addObservation(node.Name, o.Text, Provenance(o.jsonldProvenance))
+				}
+			}
+		case "Relation":
+			r := Relation{
+				From:         resolve(node.From),
+				To:           resolve(node.To),
+				RelationType: node.RelationType,
+				Context:      node.Context,
+				Description:  node.Description,
+			}
+			if node.Weight != nil {
+				r.Weight = *node.Weight
+			}
+			err = batch.addRelation(r, Provenance(node.jsonldProvenance))
+		default:
+			err = fmt.Errorf("%w: unknown node type %q", ErrInvalidGraph, node.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
@@ -0,0 +1,348 @@
+package cip02
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+var (
+	ErrNotTrackerEvent   = errors.New("cip02: not a project or task event")
+	ErrInvalidWorkflow   = errors.New("cip02: invalid workflow")
+	ErrUnknownStatus     = errors.New("cip02: status is not part of the workflow")
+	ErrInvalidTransition = errors.New("cip02: status transition is not allowed")
+	ErrUnknownProject    = errors.New("cip02: unknown project")
+	ErrProjectChanged    = errors.New("cip02: task belongs to another project")
+	ErrNotProjectMember  = errors.New("cip02: assignee is not a member of the project")
+	ErrNotProjectEditor  = errors.New("cip02: only the creator and members may update a project or its tasks")
+)
+
+// Workflow defines the statuses of a task and the transitions between them
+type Workflow struct {
+	Initial     string              // status of new tasks, tasks created without a status get it
+	Transitions map[string][]string // status -> statuses a task may move to
+}
+
+// DefaultWorkflow is todo -> doing -> done, with doing -> todo to give a task up
+var DefaultWorkflow = Workflow{
+	Initial: "todo",
+	Transitions: map[string][]string{
+		"todo":  {"doing"},
+		"doing": {"todo", "done"},
+		"done":  nil,
+	},
+}
+
+// ParseWorkflow parses a workflow given as comma separated chains of transitions, e.g.
+// "todo>doing>review>done,review>doing". The first status is the initial one.
+func ParseWorkflow(s string) (Workflow, error) {
+	w := Workflow{Transitions: make(map[string][]string)}
+	for _, chain := range strings.Split(s, ",") {
+		statuses := strings.Split(chain, ">")
+		if len(statuses) < 2 {
+			return Workflow{}, fmt.Errorf("%w: %q has no transition", ErrInvalidWorkflow, chain)
+		}
+		for i, status := range statuses {
+			status = strings.TrimSpace(status)
+			if status == "" {
+				return Workflow{}, fmt.Errorf("%w: empty status in %q", ErrInvalidWorkflow, chain)
+			}
+			if w.Initial == "" {
+				w.Initial = status
+			}
+			if _, ok := w.Transitions[status]; !ok {
+				w.Transitions[status] = nil
+			}
+			if i > 0 {
+				from := strings.TrimSpace(statuses[i-1])
+				if !slices.Contains(w.Transitions[from], status) {
+					w.Transitions[from] = append(w.Transitions[from], status)
+				}
+			}
+		}
+	}
+	return w, nil
+}
+
+// Known checks if the status is part of the workflow
+func (w Workflow) Known(status string) bool {
+	_, ok := w.Transitions[status]
+	return ok
+}
+
+// Allows checks if a task may move from one status to another, staying in a status is always allowed
+func (w Workflow) Allows(from, to string) bool {
+	return from == to || slices.Contains(w.Transitions[from], to)
+}
+
+// IsFinal checks if a status has no way out, tasks in a final status are never overdue
+func (w Workflow) IsFinal(status string) bool {
+	return w.Known(status) && len(w.Transitions[status]) == 0
+}
+
+// Project is the current state of a project
+type Project struct {
+	ProjectID string
+	Creator   string // author of the first version of the project
+	Name      string
+	Desc      string
+	Members   []string
+	Status    string
+	Version   Version
+}
+
+// IsMember checks if the given pubkey is a member of the project
+func (p *Project) IsMember(pubkey string) bool {
+	return slices.Contains(p.Members, pubkey)
+}
+
+// Task is the current state of a task
+type Task struct {
+	ProjectID string
+	TaskID    string
+	Title     string
+	Assignee  string // empty when unassigned
+	Status    string
+	Deadline  time.Time // zero when there is none
+	Priority  string
+	Version   Version
+}
+
+// TaskTracker folds the project and task events of a subspace into the current projects and
+// tasks. Task statuses follow its workflow and assignees must be members of their project.
+//
+// The author of the first version of a project is its creator, only the creator and the current
+// members may update it afterwards.
+type TaskTracker struct {
+	SubspaceID string
+	Workflow   Workflow
+
+	projects map[string]*Project
+	tasks    map[string]*Task
+	seen     map[string]struct{}
+}
+
+// NewTaskTracker creates an empty tracker for the events of a subspace
+func NewTaskTracker(subspaceID string, workflow Workflow) *TaskTracker {
+	return &TaskTracker{
+		SubspaceID: subspaceID,
+		Workflow:   workflow,
+		projects:   make(map[string]*Project),
+		tasks:      make(map[string]*Task),
+		seen:       make(map[string]struct{}),
+	}
+}
+
+// Replay applies the events by created_at, then event id, and returns the errors of the
+// rejected ones. Events other than projects and tasks of the subspace are ignored.
+func (t *TaskTracker) Replay(events []nostr.Event) []error {
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b nostr.Event) int {
+		if a.CreatedAt != b.CreatedAt {
+			return cmp.Compare(a.CreatedAt, b.CreatedAt)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	var errs []error
+	for _, evt := range events {
+		if _, err := t.Apply(evt); err != nil && !errors.Is(err, ErrNotTrackerEvent) {
+			errs = append(errs, fmt.Errorf("event %s: %w", evt.ID, err))
+		}
+	}
+	return errs
+}
+
+// Apply parses and validates a project or task event of the subspace and folds it into the
+// current state. Events older than the current version of their project or task are ignored.
+// It reports whether the state changed.
+func (t *TaskTracker) Apply(evt nostr.Event) (bool, error) {
+	switch evt.Kind {
+	case cip.KindCommonGraphProject, cip.KindCommonGraphTask:
+	default:
+		return false, fmt.Errorf("%w: kind %d", ErrNotTrackerEvent, evt.Kind)
+	}
+	if evt.Tags.FindWithValue("sid", t.SubspaceID) == nil {
+		return false, fmt.Errorf("%w: not in subspace %s", ErrNotTrackerEvent, t.SubspaceID)
+	}
+	if _, ok := t.seen[evt.ID]; ok {
+		return false, nil
+	}
+
+	op, err := ParseCommonGraphEvent(evt)
+	if err != nil {
+		return false, err
+	}
+
+	var changed bool
+	switch e := op.(type) {
+	case *ProjectEvent:
+		if err := e.Validate(); err != nil {
+			return false, err
+		}
+		if changed, err = t.AddProject(e); err != nil {
+			return false, err
+		}
+	case *TaskEvent:
+		if err := e.Validate(); err != nil {
+			return false, err
+		}
+		if changed, err = t.AddTask(e); err != nil {
+			return false, err
+		}
+	}
+	t.seen[evt.ID] = struct{}{}
+	return changed, nil
+}
+
+// AddProject creates or updates a project unless a later version of it was added already.
+// Updates must come from the creator or a current member of the project.
+func (t *TaskTracker) AddProject(e *ProjectEvent) (bool, error) {
+	version := versionOf(e.SubspaceOpEvent)
+	creator := e.PubKey
+	if current, ok := t.projects[e.ProjectID]; ok {
+		if !version.After(current.Version) {
+			return false, nil
+		}
+		if e.PubKey != current.Creator && !current.IsMember(e.PubKey) {
+			return false, fmt.Errorf("%w: %s in %s", ErrNotProjectEditor, e.PubKey, e.ProjectID)
+		}
+		creator = current.Creator
+	}
+	t.projects[e.ProjectID] = &Project{
+		ProjectID: e.ProjectID,
+		Creator:   creator,
+		Name:      e.Name,
+		Desc:      e.Desc,
+		Members:   slices.Clone(e.Members),
+		Status:    e.Status,
+		Version:   version,
+	}
+	return true, nil
+}
+
+// AddTask creates or updates a task unless a later version of it was added already. The task
+// must stay in a known project, move along the workflow and be assigned to a project member.
+// Its author must be the creator or a member of the project, or the current assignee.
+func (t *TaskTracker) AddTask(e *TaskEvent) (bool, error) {
+	version := versionOf(e.SubspaceOpEvent)
+	current, exists := t.tasks[e.TaskID]
+	if exists && !version.After(current.Version) {
+		return false, nil
+	}
+
+	project, ok := t.projects[e.ProjectID]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrUnknownProject, e.ProjectID)
+	}
+	if exists && current.ProjectID != e.ProjectID {
+		return false, fmt.Errorf("%w: %s", ErrProjectChanged, current.ProjectID)
+	}
+	if e.PubKey != project.Creator && !project.IsMember(e.PubKey) && !(exists && e.PubKey == current.Assignee) {
+		return false, fmt.Errorf("%w: %s in %s", ErrNotProjectEditor, e.PubKey, e.ProjectID)
+	}
+	if e.Assignee != "" && !project.IsMember(e.Assignee) {
+		return false, fmt.Errorf("%w: %s in %s", ErrNotProjectMember, e.Assignee, e.ProjectID)
+	}
+
+	status := e.Status
+	if status == "" {
+		status = t.Workflow.Initial
+	}
+	if !t.Workflow.Known(status) {
+		return false, fmt.Errorf("%w: %q", ErrUnknownStatus, status)
+	}
+	if exists {
+		if !t.Workflow.Allows(current.Status, status) {
+			return false, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current.Status, status)
+		}
+	} else if status != t.Workflow.Initial {
+		return false, fmt.Errorf("%w: new task in %s", ErrInvalidTransition, status)
+	}
+
+	task := &Task{
+		ProjectID: e.ProjectID,
+		TaskID:    e.TaskID,
+		Title:     e.Title,
+		Assignee:  e.Assignee,
+		Status:    status,
+		Priority:  e.Priority,
+		Version:   version,
+	}
+	if e.Deadline != "" {
+		task.Deadline, _ = ParseDeadline(e.Deadline) // checked by Validate
+	}
+	t.tasks[e.TaskID] = task
+	return true, nil
+}
+
+// Project returns the current state of a project
+func (t *TaskTracker) Project(projectID string) (Project, bool) {
+	if p, ok := t.projects[projectID]; ok {
+		project := *p
+		project.Members = slices.Clone(p.Members)
+		return project, true
+	}
+	return Project{}, false
+}
+
+// Projects returns all the projects, sorted by id
+func (t *TaskTracker) Projects() []Project {
+	projects := make([]Project, 0, len(t.projects))
+	for id := range t.projects {
+		project, _ := t.Project(id)
+		projects = append(projects, project)
+	}
+	slices.SortFunc(projects, func(a, b Project) int { return strings.Compare(a.ProjectID, b.ProjectID) })
+	return projects
+}
+
+// Task returns the current state of a task
+func (t *TaskTracker) Task(taskID string) (Task, bool) {
+	if task, ok := t.tasks[taskID]; ok {
+		return *task, true
+	}
+	return Task{}, false
+}
+
+// Tasks returns the tasks of a project, sorted by id
+func (t *TaskTracker) Tasks(projectID string) []Task {
+	return t.tasksWhere(func(task *Task) bool { return task.ProjectID == projectID })
+}
+
+// TasksByAssignee returns the tasks assigned to a pubkey, sorted by id
+func (t *TaskTracker) TasksByAssignee(assignee string) []Task {
+	return t.tasksWhere(func(task *Task) bool { return task.Assignee == assignee })
+}
+
+// TasksByStatus returns the tasks in a status, sorted by id
+func (t *TaskTracker) TasksByStatus(status string) []Task {
+	return t.tasksWhere(func(task *Task) bool { return task.Status == status })
+}
+
+// Overdue returns the tasks whose deadline has passed at the given time and that are not in a
+// final status, the most overdue first
+func (t *TaskTracker) Overdue(now time.Time) []Task {
+	tasks := t.tasksWhere(func(task *Task) bool {
+		return !task.Deadline.IsZero() && task.Deadline.Before(now) && !t.Workflow.IsFinal(task.Status)
+	})
+	slices.SortStableFunc(tasks, func(a, b Task) int { return a.Deadline.Compare(b.Deadline) })
+	return tasks
+}
+
+func (t *TaskTracker) tasksWhere(match func(*Task) bool) []Task {
+	var tasks []Task
+	for _, task := range t.tasks {
+		if match(task) {
+			tasks = append(tasks, *task)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b Task) int { return strings.Compare(a.TaskID, b.TaskID) })
+	return tasks
+}
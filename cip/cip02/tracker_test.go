@@ -0,0 +1,143 @@
+package cip02
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func project(t *testing.T, id string, createdAt nostr.Timestamp, author, projectID string, members ...string) nostr.Event {
+	e, err := NewProjectEvent(testSID)
+	require.NoError(t, err)
+	e.SetProjectInfo(projectID, "Project "+projectID, "", members, "active")
+	e.ID, e.CreatedAt, e.PubKey = id, createdAt, author
+	return e.Event
+}
+
+func task(t *testing.T, id string, createdAt nostr.Timestamp, author, projectID, taskID, assignee, status, deadline string) nostr.Event {
+	e, err := NewTaskEvent(testSID)
+	require.NoError(t, err)
+	e.SetTaskInfo(projectID, taskID, "Task "+taskID, assignee, status, deadline, "high")
+	e.ID, e.CreatedAt, e.PubKey = id, createdAt, author
+	return e.Event
+}
+
+func TestParseWorkflow(t *testing.T) {
+	w, err := ParseWorkflow("todo>doing>review>done, review>doing")
+	require.NoError(t, err)
+	assert.Equal(t, "todo", w.Initial)
+	assert.True(t, w.Allows("review", "doing"))
+	assert.True(t, w.Allows("doing", "doing"))
+	assert.False(t, w.Allows("todo", "done"))
+	assert.True(t, w.IsFinal("done"))
+	assert.False(t, w.IsFinal("review"))
+	assert.False(t, w.Known("blocked"))
+
+	for _, s := range []string{"", "todo", "todo>", "todo>>done"} {
+		_, err := ParseWorkflow(s)
+		assert.ErrorIs(t, err, ErrInvalidWorkflow, s)
+	}
+}
+
+func TestTaskTracker(t *testing.T) {
+	tracker := NewTaskTracker(testSID, DefaultWorkflow)
+	errs := tracker.Replay([]nostr.Event{
+		// out of order, replay sorts by created_at
+		task(t, "t1", 110, "alice", "p1", "task1", "alice", "", "2026-01-10"),
+		project(t, "p1", 100, "owner", "p1", "alice", "bob"),
+		task(t, "t2", 120, "alice", "p1", "task2", "bob", "todo", ""),
+		task(t, "t3", 130, "alice", "p1", "task1", "alice", "doing", "2026-01-10"),
+		task(t, "t4", 130, "alice", "p1", "task3", "", "todo", "2026-01-05"),
+		task(t, "t5", 140, "alice", "p1", "task2", "bob", "done", ""),   // todo -> done skips doing
+		task(t, "t6", 140, "alice", "p1", "task3", "carol", "todo", ""), // carol is not a member
+		task(t, "t7", 150, "alice", "p2", "task4", "alice", "todo", ""), // unknown project
+		task(t, "t8", 150, "alice", "p1", "task5", "bob", "done", ""),   // must start in todo
+		task(t, "t9", 150, "alice", "p1", "task6", "bob", "blocked", ""),
+		{Kind: nostr.KindTextNote, ID: "note"},
+	})
+	require.Len(t, errs, 5)
+	assert.ErrorIs(t, errs[0], ErrInvalidTransition)
+	assert.ErrorIs(t, errs[1], ErrNotProjectMember)
+	assert.ErrorIs(t, errs[2], ErrUnknownProject)
+	assert.ErrorIs(t, errs[3], ErrInvalidTransition)
+	assert.ErrorIs(t, errs[4], ErrUnknownStatus)
+
+	task1, ok := tracker.Task("task1")
+	require.True(t, ok)
+	assert.Equal(t, "doing", task1.Status)
+	assert.Equal(t, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), task1.Deadline)
+	assert.Equal(t, "t3", task1.Version.EventID)
+
+	assert.Len(t, tracker.Tasks("p1"), 3)
+	assert.Equal(t, []string{"task1"}, taskIDs(tracker.TasksByAssignee("alice")))
+	assert.Equal(t, []string{"task2", "task3"}, taskIDs(tracker.TasksByStatus("todo")))
+	assert.Equal(t, []string{"task3", "task1"}, taskIDs(tracker.Overdue(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, []string{"task3"}, taskIDs(tracker.Overdue(time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC))))
+
+	// an older update is ignored, a newer one follows the workflow
+	changed, err := tracker.Apply(task(t, "t10", 125, "alice", "p1", "task1", "alice", "todo", ""))
+	require.NoError(t, err)
+	assert.False(t, changed)
+	changed, err = tracker.Apply(task(t, "t11", 200, "alice", "p1", "task1", "alice", "done", ""))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"task3"}, taskIDs(tracker.Overdue(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))))
+
+	_, err = tracker.Apply(task(t, "t12", 210, "alice", "p1", "task1", "alice", "doing", ""))
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	// carol can be assigned once added to the project
+	_, err = tracker.Apply(project(t, "p3", 220, "bob", "p1", "alice", "bob", "carol"))
+	require.NoError(t, err)
+	_, err = tracker.Apply(task(t, "t13", 230, "alice", "p1", "task3", "carol", "doing", ""))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task3"}, taskIDs(tracker.TasksByAssignee("carol")))
+
+	_, err = tracker.Apply(project(t, "p4", 240, "dave", "p2"))
+	require.NoError(t, err)
+	_, err = tracker.Apply(task(t, "t14", 250, "alice", "p2", "task3", "", "doing", ""))
+	assert.ErrorIs(t, err, ErrProjectChanged)
+
+	projects := tracker.Projects()
+	require.Len(t, projects, 2)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, projects[0].Members)
+	projects[0].Members[0] = "mallory"
+	p1, _ := tracker.Project("p1")
+	assert.True(t, p1.IsMember("alice"))
+	assert.Equal(t, "owner", p1.Creator)
+
+	// outsiders can't take a project over, the creator can still update it after leaving it
+	_, err = tracker.Apply(project(t, "p5", 260, "mallory", "p1", "mallory"))
+	assert.ErrorIs(t, err, ErrNotProjectEditor)
+	p1, _ = tracker.Project("p1")
+	assert.Equal(t, []string{"alice", "bob", "carol"}, p1.Members)
+	assert.Equal(t, "p3", p1.Version.EventID)
+	_, err = tracker.Apply(project(t, "p6", 270, "owner", "p1", "alice"))
+	require.NoError(t, err)
+	_, err = tracker.Apply(project(t, "p7", 280, "bob", "p1", "bob"))
+	assert.ErrorIs(t, err, ErrNotProjectEditor)
+
+	// nor can they move or reassign tasks, the assignee can even once out of the project
+	_, err = tracker.Apply(task(t, "t15", 290, "mallory", "p1", "task3", "alice", "todo", ""))
+	assert.ErrorIs(t, err, ErrNotProjectEditor)
+	_, err = tracker.Apply(task(t, "t16", 290, "mallory", "p1", "task7", "", "todo", ""))
+	assert.ErrorIs(t, err, ErrNotProjectEditor)
+	_, err = tracker.Apply(task(t, "t17", 300, "carol", "p1", "task3", "", "done", ""))
+	require.NoError(t, err)
+	_, err = tracker.Apply(task(t, "t18", 300, "owner", "p1", "task7", "alice", "todo", ""))
+	require.NoError(t, err)
+	task3, _ := tracker.Task("task3")
+	assert.Equal(t, "done", task3.Status)
+	assert.Equal(t, "t17", task3.Version.EventID)
+}
+
+func taskIDs(tasks []Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.TaskID
+	}
+	return ids
+}
@@ -0,0 +1,80 @@
+package cip02
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// deadlineLayouts are the accepted formats for task deadlines, besides unix timestamps
+var deadlineLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// ParseDeadline parses a task deadline given as a unix timestamp, RFC 3339 time or date
+func ParseDeadline(deadline string) (time.Time, error) {
+	if ts, err := strconv.ParseInt(deadline, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	var err error
+	for _, layout := range deadlineLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, deadline); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// Validate checks that the project has an id and a name
+func (e *ProjectEvent) Validate() error {
+	v := e.ObjectValidator(e.ProjectID)
+	v.Required("project_id", e.ProjectID)
+	v.Required("name", e.Name)
+	return v.Err()
+}
+
+// Validate checks that the task belongs to a project and has a well formed deadline
+func (e *TaskEvent) Validate() error {
+	v := e.ObjectValidator(e.TaskID)
+	v.Required("project_id", e.ProjectID)
+	v.Required("task_id", e.TaskID)
+	v.Required("title", e.Title)
+	if e.Deadline != "" {
+		_, err := ParseDeadline(e.Deadline)
+		v.Check(err == nil, "deadline", "not a unix timestamp or date: %q", e.Deadline)
+	}
+	return v.Err()
+}
+
+// Validate checks that the entity has a name and a type
+func (e *EntityEvent) Validate() error {
+	v := e.ObjectValidator(e.EntityName)
+	v.Required("entity_name", e.EntityName)
+	v.Required("entity_type", e.EntityType)
+	return v.Err()
+}
+
+// Validate checks the relation endpoints and that the weight tag is a number
+func (e *RelationEvent) Validate() error {
+	v := e.Validator()
+	v.Required("from", e.From)
+	v.Required("to", e.To)
+	v.Required("relation_type", e.RelationType)
+
+	// the typed Weight is lenient, so check the raw tag
+	if weight := e.Tags.Find("weight"); weight != nil {
+		_, err := cip.ParseNumber(weight[1])
+		v.CheckErr("weight", err)
+	} else {
+		v.Fail("weight", "is required")
+	}
+	return v.Err()
+}
+
+// Validate checks that the observation is attached to an entity
+func (e *ObservationEvent) Validate() error {
+	v := e.Validator()
+	v.Required("entity_name", e.EntityName)
+	v.Required("observation", e.Observation)
+	return v.Err()
+}
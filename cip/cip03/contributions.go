@@ -0,0 +1,110 @@
+package cip03
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+var ErrInvalidContribution = errors.New("cip03: invalid contribution")
+
+// Well known contribution roles. RoleBase is credited to the parent model, the other roles to
+// the inputs of the same role.
+const (
+	RoleBase     = "base"
+	RoleData     = "data"
+	RoleAlgo     = "algo"
+	RoleCompute  = "compute"
+	RoleFinetune = "finetune"
+)
+
+// Contribution is the weight given to a role in building a model. Unnamed weights have an empty
+// role and are credited to the model itself.
+type Contribution struct {
+	Role   string
+	Weight float64
+}
+
+// Contributions are the weights of a contrib tag, they don't have to add up to 1
+type Contributions []Contribution
+
+// ParseContributions strictly parses a contrib tag like "base:0.1,data:0.6,algo:0.4" or
+// "0.8,0.2". Weights must be non-negative numbers, not all zero, and a role may only appear once.
+func ParseContributions(s string) (Contributions, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var contributions Contributions
+	seen := make(map[string]struct{})
+	for _, part := range strings.Split(s, ",") {
+		role, weight, found := strings.Cut(part, ":")
+		if !found {
+			role, weight = "", part
+		} else if role = strings.TrimSpace(role); role == "" {
+			return nil, fmt.Errorf("%w: empty role in %q", ErrInvalidContribution, part)
+		}
+
+		w, err := cip.ParseNumber(strings.TrimSpace(weight))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidContribution, err)
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("%w: negative weight %q", ErrInvalidContribution, part)
+		}
+		if role != "" {
+			if _, ok := seen[role]; ok {
+				return nil, fmt.Errorf("%w: duplicate role %q", ErrInvalidContribution, role)
+			}
+			seen[role] = struct{}{}
+		}
+		contributions = append(contributions, Contribution{Role: role, Weight: w})
+	}
+	if contributions.Total() == 0 {
+		return nil, fmt.Errorf("%w: all weights are zero", ErrInvalidContribution)
+	}
+	return contributions, nil
+}
+
+// Total returns the sum of the weights
+func (c Contributions) Total() float64 {
+	var total float64
+	for _, contribution := range c {
+		total += contribution.Weight
+	}
+	return total
+}
+
+// Shares returns the normalized weight of each role, unnamed weights are summed under ""
+func (c Contributions) Shares() map[string]float64 {
+	total := c.Total()
+	shares := make(map[string]float64, len(c))
+	if total == 0 {
+		return shares
+	}
+	for _, contribution := range c {
+		shares[contribution.Role] += contribution.Weight / total
+	}
+	return shares
+}
+
+// String formats the contributions as a contrib tag value
+func (c Contributions) String() string {
+	parts := make([]string, len(c))
+	for i, contribution := range c {
+		weight := strconv.FormatFloat(contribution.Weight, 'g', -1, 64)
+		if contribution.Role == "" {
+			parts[i] = weight
+		} else {
+			parts[i] = contribution.Role + ":" + weight
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParsedContributions parses the contrib tag of the model, see ParseContributions
+func (e *ModelEvent) ParsedContributions() (Contributions, error) {
+	return ParseContributions(e.Contributions)
+}
@@ -0,0 +1,280 @@
+package cip03
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+var (
+	ErrNotLineageEvent = errors.New("cip03: not a model, dataset, algo, compute or finetune event")
+	ErrUnknownNode     = errors.New("cip03: event is not part of the lineage")
+	ErrLineageCycle    = errors.New("cip03: lineage has a cycle")
+)
+
+// LineageNode is an event of the lineage with the links to the events it was built from
+type LineageNode struct {
+	EventID   string
+	Operation string
+	Author    string
+	CreatedAt nostr.Timestamp
+
+	// Recipients are credited with the own share of the node: the contributors of a dataset,
+	// the author otherwise
+	Recipients []string
+
+	// Contributions weigh the roles of the inputs, nil when the node has none
+	Contributions Contributions
+
+	// Inputs are the upstream events, a model's parent is an input with RoleBase and a
+	// finetune's dataset one with RoleData
+	Inputs []Input
+}
+
+// Attribution is the share of credit of each address for an event of the lineage.
+// Shares add up to 1 together with the unresolved ones.
+type Attribution struct {
+	Credits    map[string]float64 // address -> share
+	Unresolved map[string]float64 // upstream event id missing from the lineage -> share
+}
+
+// Lineage links the model, dataset, algo, compute and finetune events of a subspace to the
+// events they were built from, and propagates credit along the links.
+type Lineage struct {
+	SubspaceID string
+
+	nodes      map[string]*LineageNode
+	downstream map[string][]string
+}
+
+// NewLineage creates an empty lineage for the events of a subspace
+func NewLineage(subspaceID string) *Lineage {
+	return &Lineage{
+		SubspaceID: subspaceID,
+		nodes:      make(map[string]*LineageNode),
+		downstream: make(map[string][]string),
+	}
+}
+
+// Replay adds all the events and returns the errors of the rejected ones.
+// Events that aren't lineage events of the subspace are left out of the errors.
+func (l *Lineage) Replay(events []nostr.Event) []error {
+	var errs []error
+	for _, evt := range events {
+		if err := l.Add(evt); err != nil && !errors.Is(err, ErrNotLineageEvent) {
+			errs = append(errs, fmt.Errorf("event %s: %w", evt.ID, err))
+		}
+	}
+	return errs
+}
+
+// Add parses and validates a lineage event of the subspace and links it to its inputs.
+// Inputs may be added before or after the events that use them.
+func (l *Lineage) Add(evt nostr.Event) error {
+	switch evt.Kind {
+	case cip.KindModelgraphModel, cip.KindModelgraphDataset, cip.KindModelgraphAlgo,
+		cip.KindModelgraphCompute, cip.KindModelgraphFinetune:
+	default:
+		return fmt.Errorf("%w: kind %d", ErrNotLineageEvent, evt.Kind)
+	}
+	if evt.Tags.FindWithValue("sid", l.SubspaceID) == nil {
+		return fmt.Errorf("%w: not in subspace %s", ErrNotLineageEvent, l.SubspaceID)
+	}
+	if _, ok := l.nodes[evt.ID]; ok {
+		return nil
+	}
+
+	op, err := ParseModelGraphEvent(evt)
+	if err != nil {
+		return err
+	}
+	if err := op.Validate(); err != nil {
+		return err
+	}
+
+	node := &LineageNode{
+		EventID:    evt.ID,
+		Operation:  op.GetOperation(),
+		Author:     evt.PubKey,
+		CreatedAt:  evt.CreatedAt,
+		Recipients: []string{evt.PubKey},
+	}
+	switch e := op.(type) {
+	case *ModelEvent:
+		if e.ParentHash != "" {
+			node.Inputs = append(node.Inputs, Input{Role: RoleBase, EventID: e.ParentHash})
+		}
+		node.Inputs = append(node.Inputs, e.Inputs...)
+		node.Contributions, _ = e.ParsedContributions() // checked by Validate
+	case *DatasetEvent:
+		if len(e.Contributors) > 0 {
+			node.Recipients = slices.Clone(e.Contributors)
+		}
+	case *FinetuneEvent:
+		node.Inputs = append(node.Inputs, Input{Role: RoleData, EventID: e.DatasetID})
+		node.Inputs = append(node.Inputs, e.Inputs...)
+	}
+
+	l.nodes[evt.ID] = node
+	for _, input := range node.Inputs {
+		if !slices.Contains(l.downstream[input.EventID], evt.ID) {
+			l.downstream[input.EventID] = append(l.downstream[input.EventID], evt.ID)
+		}
+	}
+	return nil
+}
+
+// Node returns the lineage node of an event
+func (l *Lineage) Node(eventID string) (LineageNode, bool) {
+	if n, ok := l.nodes[eventID]; ok {
+		node := *n
+		node.Recipients = slices.Clone(n.Recipients)
+		node.Contributions = slices.Clone(n.Contributions)
+		node.Inputs = slices.Clone(n.Inputs)
+		return node, true
+	}
+	return LineageNode{}, false
+}
+
+// Parent returns the parent model of a model, if it has one
+func (l *Lineage) Parent(eventID string) (string, bool) {
+	if n, ok := l.nodes[eventID]; ok {
+		for _, input := range n.Inputs {
+			if input.Role == RoleBase && n.Operation == cip.OpModel {
+				return input.EventID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Ancestors returns the chain of parent models of a model, closest first. The chain stops at the
+// first parent missing from the lineage, which is still included.
+func (l *Lineage) Ancestors(eventID string) []string {
+	var ancestors []string
+	seen := map[string]struct{}{eventID: {}}
+	for id := eventID; ; {
+		parent, ok := l.Parent(id)
+		if !ok {
+			return ancestors
+		}
+		if _, ok := seen[parent]; ok {
+			return ancestors // cycle
+		}
+		seen[parent] = struct{}{}
+		ancestors = append(ancestors, parent)
+		id = parent
+	}
+}
+
+// Children returns the events that use an event as an input, sorted
+func (l *Lineage) Children(eventID string) []string {
+	children := slices.Clone(l.downstream[eventID])
+	slices.Sort(children)
+	return children
+}
+
+// Descendants returns all the events built from an event directly or transitively, sorted
+func (l *Lineage) Descendants(eventID string) []string {
+	seen := make(map[string]struct{})
+	queue := []string{eventID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range l.downstream[id] {
+			if _, ok := seen[child]; !ok && child != eventID {
+				seen[child] = struct{}{}
+				queue = append(queue, child)
+			}
+		}
+	}
+	descendants := make([]string, 0, len(seen))
+	for id := range seen {
+		descendants = append(descendants, id)
+	}
+	slices.Sort(descendants)
+	return descendants
+}
+
+// Attribute propagates the credit for an event up its lineage down to the authors of the
+// original datasets, algorithms and compute.
+//
+// The share of each contribution role goes to the inputs of that role, split equally. Unnamed
+// weights and roles without inputs stay with the recipients of the node. A node with inputs but
+// no contributions gives an equal share to each role of its inputs and to its recipients.
+// Shares of inputs missing from the lineage are reported as unresolved.
+func (l *Lineage) Attribute(eventID string) (Attribution, error) {
+	if _, ok := l.nodes[eventID]; !ok {
+		return Attribution{}, fmt.Errorf("%w: %s", ErrUnknownNode, eventID)
+	}
+	return l.attribute(eventID, make(map[string]Attribution), make(map[string]struct{}))
+}
+
+// attribute returns the attribution of a whole event. The attribution of each node is computed
+// once and kept in memo, since lineages share their inputs, path holds the nodes being computed
+// to detect cycles.
+func (l *Lineage) attribute(eventID string, memo map[string]Attribution, path map[string]struct{}) (Attribution, error) {
+	if a, ok := memo[eventID]; ok {
+		return a, nil
+	}
+	node, ok := l.nodes[eventID]
+	if !ok {
+		return Attribution{Credits: map[string]float64{}, Unresolved: map[string]float64{eventID: 1}}, nil
+	}
+	if _, ok := path[eventID]; ok {
+		return Attribution{}, fmt.Errorf("%w: through %s", ErrLineageCycle, eventID)
+	}
+	path[eventID] = struct{}{}
+	defer delete(path, eventID)
+
+	inputs := make(map[string][]string)
+	var roles []string
+	for _, input := range node.Inputs {
+		if _, ok := inputs[input.Role]; !ok {
+			roles = append(roles, input.Role)
+		}
+		inputs[input.Role] = append(inputs[input.Role], input.EventID)
+	}
+
+	shares := node.Contributions.Shares()
+	if node.Contributions == nil {
+		for _, role := range roles {
+			shares[role] = 1 / float64(len(roles)+1)
+		}
+		shares[""] = 1 / float64(len(roles)+1)
+	}
+
+	a := Attribution{Credits: make(map[string]float64), Unresolved: make(map[string]float64)}
+	var own float64
+	for _, role := range slices.Sorted(maps.Keys(shares)) {
+		roleShare, ids := shares[role], inputs[role]
+		if role == "" || len(ids) == 0 {
+			own += roleShare
+			continue
+		}
+		for _, id := range ids {
+			input, err := l.attribute(id, memo, path)
+			if err != nil {
+				return Attribution{}, err
+			}
+			share := roleShare / float64(len(ids))
+			for address, credit := range input.Credits {
+				a.Credits[address] += share * credit
+			}
+			for upstream, credit := range input.Unresolved {
+				a.Unresolved[upstream] += share * credit
+			}
+		}
+	}
+	if own > 0 {
+		for _, recipient := range node.Recipients {
+			a.Credits[recipient] += own / float64(len(node.Recipients))
+		}
+	}
+	memo[eventID] = a
+	return a, nil
+}
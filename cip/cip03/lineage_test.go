@@ -0,0 +1,188 @@
+package cip03
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSID = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+func eventID(n int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+func TestParseContributions(t *testing.T) {
+	c, err := ParseContributions("base:0.1, data:0.6,algo:0.4")
+	require.NoError(t, err)
+	assert.Equal(t, Contributions{{RoleBase, 0.1}, {RoleData, 0.6}, {RoleAlgo, 0.4}}, c)
+	assert.InDelta(t, 1.1, c.Total(), 1e-9)
+	assert.InDelta(t, 0.6/1.1, c.Shares()[RoleData], 1e-9)
+	assert.Equal(t, "base:0.1,data:0.6,algo:0.4", c.String())
+
+	c, err = ParseContributions("0.8,0.2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"": 1}, c.Shares())
+
+	c, err = ParseContributions("")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+
+	for _, s := range []string{"data:-1", "data:x", "data:1,data:2", ":1", "data:0,algo:0", "data:NaN", "1,"} {
+		_, err := ParseContributions(s)
+		assert.ErrorIs(t, err, ErrInvalidContribution, s)
+	}
+
+	model, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	model.SetContributions("data:1,data:1")
+	assert.Error(t, model.Validate())
+}
+
+func TestLineage(t *testing.T) {
+	dataset, err := NewDatasetEvent(testSID)
+	require.NoError(t, err)
+	dataset.SetDatasetInfo("p1", "t1", "text", "json", []string{"0xd1", "0xd2"})
+	dataset.ID, dataset.PubKey = eventID(1), "0xcurator"
+
+	algo, err := NewAlgoEvent(testSID)
+	require.NoError(t, err)
+	algo.Tags = append(algo.Tags, nostr.Tag{"algo_type", "transformer"})
+	algo.ID, algo.PubKey = eventID(2), "0xa1"
+
+	compute, err := NewComputeEvent(testSID)
+	require.NoError(t, err)
+	compute.Tags = append(compute.Tags, nostr.Tag{"compute_type", "training"})
+	compute.ID, compute.PubKey = eventID(3), "0xc1"
+
+	base, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	base.SetContributions("data:0.5,algo:0.25,compute:0.25")
+	base.SetInput(RoleData, dataset.ID)
+	base.SetInput(RoleAlgo, algo.ID)
+	base.SetInput(RoleCompute, compute.ID)
+	base.ID, base.PubKey = eventID(4), "0xm1"
+
+	finetune, err := NewFinetuneEvent(testSID)
+	require.NoError(t, err)
+	finetune.SetFinetuneInfo("p1", "t2", dataset.ID, "provider", "m2")
+	finetune.ID, finetune.PubKey = eventID(5), "0xf1"
+
+	// built from the base model and a finetune, part of the credit stays with its author
+	model, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	model.SetParent(base.ID)
+	model.SetContributions("base:0.5,finetune:0.3,0.2")
+	model.SetInput(RoleFinetune, finetune.ID)
+	model.ID, model.PubKey = eventID(6), "0xm2"
+
+	// the parent of this one is unknown
+	orphan, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	orphan.SetParent(eventID(99))
+	orphan.ID, orphan.PubKey = eventID(7), "0xm3"
+
+	invalid, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	invalid.SetInput(RoleData, "not-an-id")
+	invalid.ID = eventID(8)
+
+	session, err := NewSessionEvent(testSID)
+	require.NoError(t, err)
+
+	lineage := NewLineage(testSID)
+	// models come before their inputs
+	errs := lineage.Replay([]nostr.Event{
+		model.Event, orphan.Event, invalid.Event, session.Event,
+		base.Event, finetune.Event, dataset.Event, algo.Event, compute.Event,
+	})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), eventID(8))
+
+	parent, ok := lineage.Parent(model.ID)
+	assert.True(t, ok)
+	assert.Equal(t, base.ID, parent)
+	assert.Equal(t, []string{base.ID}, lineage.Ancestors(model.ID))
+	assert.Equal(t, []string{eventID(99)}, lineage.Ancestors(orphan.ID))
+	assert.Equal(t, []string{base.ID, finetune.ID}, lineage.Children(dataset.ID))
+	assert.Equal(t, []string{base.ID, finetune.ID, model.ID}, lineage.Descendants(dataset.ID))
+
+	node, ok := lineage.Node(finetune.ID)
+	require.True(t, ok)
+	assert.Equal(t, []Input{{RoleData, dataset.ID}}, node.Inputs)
+
+	a, err := lineage.Attribute(base.ID)
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"0xd1": 0.25, "0xd2": 0.25, "0xa1": 0.25, "0xc1": 0.25}, a.Credits, 1e-9)
+	assert.Empty(t, a.Unresolved)
+
+	// base 0.5 goes through the base model, the finetune 0.3 is split between its dataset and
+	// its author, 0.2 stays with the model author
+	a, err = lineage.Attribute(model.ID)
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{
+		"0xd1": 0.125 + 0.075,
+		"0xd2": 0.125 + 0.075,
+		"0xa1": 0.125,
+		"0xc1": 0.125,
+		"0xf1": 0.15,
+		"0xm2": 0.2,
+	}, a.Credits, 1e-9)
+
+	a, err = lineage.Attribute(orphan.ID)
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"0xm3": 0.5}, a.Credits, 1e-9)
+	assert.InDeltaMapValues(t, map[string]float64{eventID(99): 0.5}, a.Unresolved, 1e-9)
+
+	_, err = lineage.Attribute(eventID(99))
+	assert.ErrorIs(t, err, ErrUnknownNode)
+}
+
+func TestLineageSharedInputs(t *testing.T) {
+	dataset, err := NewDatasetEvent(testSID)
+	require.NoError(t, err)
+	dataset.SetDatasetInfo("p1", "t1", "text", "json", []string{"0xd1"})
+	dataset.ID, dataset.PubKey = eventID(1), "0xcurator"
+	events := []nostr.Event{dataset.Event}
+
+	// each layer has two models built from both models of the layer below, so there are 2^64
+	// paths down to the dataset
+	left, right := dataset.ID, dataset.ID
+	for layer := 1; layer <= 64; layer++ {
+		var ids [2]string
+		for i := range ids {
+			model, err := NewModelEvent(testSID)
+			require.NoError(t, err)
+			model.SetContributions("data:1")
+			model.SetInput(RoleData, left)
+			model.SetInput(RoleData, right)
+			model.ID, model.PubKey = eventID(layer*2+i), "0xm"
+			ids[i] = model.ID
+			events = append(events, model.Event)
+		}
+		left, right = ids[0], ids[1]
+	}
+
+	lineage := NewLineage(testSID)
+	require.Empty(t, lineage.Replay(events))
+	a, err := lineage.Attribute(left)
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"0xd1": 1}, a.Credits, 1e-9)
+
+	// a model built from itself through another one
+	first, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	first.SetParent(eventID(1001))
+	first.ID, first.PubKey = eventID(1000), "0xm"
+	second, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	second.SetParent(first.ID)
+	second.ID, second.PubKey = eventID(1001), "0xm"
+	require.Empty(t, lineage.Replay([]nostr.Event{first.Event, second.Event}))
+
+	_, err = lineage.Attribute(second.ID)
+	assert.ErrorIs(t, err, ErrLineageCycle)
+}
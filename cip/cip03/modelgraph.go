@@ -12,9 +12,25 @@ type ModelEvent struct {
 	*nostr.SubspaceOpEvent
 	ParentHash    string
 	Contributions string
+	Inputs        []Input
 	Content       string
 }
 
+// Input links an operation to an upstream event, the role names the contribution it gets credit for
+type Input struct {
+	Role    string
+	EventID string
+}
+
+// parseInput reads an ["input", <role>, <event id>] tag, a missing event id is left for Validate to report
+func parseInput(tag nostr.Tag) Input {
+	input := Input{Role: tag[1]}
+	if len(tag) > 2 {
+		input.EventID = tag[2]
+	}
+	return input
+}
+
 // SetContributions sets the contribution weights
 func (e *ModelEvent) SetContributions(contributions string) {
 	e.Contributions = contributions
@@ -27,6 +43,13 @@ func (e *ModelEvent) SetParent(parentHash string) {
 	e.Tags = append(e.Tags, nostr.Tag{"parent", parentHash})
 }
 
+// SetInput links the model to a dataset, algo, compute or finetune event it was built from,
+// the role is the one its contribution weight is given for
+func (e *ModelEvent) SetInput(role, eventID string) {
+	e.Inputs = append(e.Inputs, Input{Role: role, EventID: eventID})
+	e.Tags = append(e.Tags, nostr.Tag{"input", role, eventID})
+}
+
 // ComputeEvent represents a compute operation in modelgraph subspace
 type ComputeEvent struct {
 	*nostr.SubspaceOpEvent
@@ -89,6 +112,7 @@ type FinetuneEvent struct {
 	DatasetID  string
 	ProviderID string
 	ModelName  string
+	Inputs     []Input
 	Content    string
 }
 
@@ -109,6 +133,12 @@ func (e *FinetuneEvent) SetFinetuneInfo(projectID, taskID, datasetID, providerID
 	)
 }
 
+// SetInput links the finetune to another event it was built from, such as its base model
+func (e *FinetuneEvent) SetInput(role, eventID string) {
+	e.Inputs = append(e.Inputs, Input{Role: role, EventID: eventID})
+	e.Tags = append(e.Tags, nostr.Tag{"input", role, eventID})
+}
+
 // ConversationEvent represents a conversation operation in modelgraph subspace
 type ConversationEvent struct {
 	*nostr.SubspaceOpEvent
@@ -173,6 +203,7 @@ func ParseModelGraphEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Extract common fields
 	subspaceID := ""
 	var authTag cip.AuthTag
+	var clock *cip.VLC
 	parents := []string{}
 
 	for _, tag := range evt.Tags {
@@ -188,6 +219,14 @@ func ParseModelGraphEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
 			}
 			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
+		case "parent":
+			parents = append(parents, tag[1:]...)
 		}
 	}
 
@@ -200,33 +239,35 @@ func ParseModelGraphEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Parse based on operation type
 	switch operation {
 	case cip.OpModel:
-		return parseModelEvent(evt, subspaceID, operation, authTag, parents)
+		return parseModelEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpDataset:
-		return parseDatasetEvent(evt, subspaceID, operation, authTag, parents)
+		return parseDatasetEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpCompute:
-		return parseComputeEvent(evt, subspaceID, operation, authTag, parents)
+		return parseComputeEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpAlgo:
-		return parseAlgoEvent(evt, subspaceID, operation, authTag, parents)
+		return parseAlgoEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpValid:
-		return parseValidEvent(evt, subspaceID, operation, authTag, parents)
+		return parseValidEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpFinetune:
-		return parseFinetuneEvent(evt, subspaceID, operation, authTag, parents)
+		return parseFinetuneEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpConversation:
-		return parseConversationEvent(evt, subspaceID, operation, authTag, parents)
+		return parseConversationEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpSession:
-		return parseSessionEvent(evt, subspaceID, operation, authTag, parents)
+		return parseSessionEvent(evt, subspaceID, operation, authTag, parents, clock)
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operation)
 	}
 }
 
-func parseDatasetEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*DatasetEvent, error) {
+func parseDatasetEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*DatasetEvent, error) {
 	dataset := &DatasetEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -254,13 +295,15 @@ func parseDatasetEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 	return dataset, nil
 }
 
-func parseModelEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ModelEvent, error) {
+func parseModelEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ModelEvent, error) {
 	model := &ModelEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -274,19 +317,23 @@ func parseModelEvent(evt nostr.Event, subspaceID, operation string, authTag cip.
 			model.ParentHash = tag[1]
 		case "contrib":
 			model.Contributions = tag[1]
+		case "input":
+			model.Inputs = append(model.Inputs, parseInput(tag))
 		}
 	}
 
 	return model, nil
 }
 
-func parseComputeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ComputeEvent, error) {
+func parseComputeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ComputeEvent, error) {
 	compute := &ComputeEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -303,13 +350,15 @@ func parseComputeEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 	return compute, nil
 }
 
-func parseAlgoEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*AlgoEvent, error) {
+func parseAlgoEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*AlgoEvent, error) {
 	algo := &AlgoEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -326,13 +375,15 @@ func parseAlgoEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 	return algo, nil
 }
 
-func parseValidEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ValidEvent, error) {
+func parseValidEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ValidEvent, error) {
 	valid := &ValidEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -349,13 +400,15 @@ func parseValidEvent(evt nostr.Event, subspaceID, operation string, authTag cip.
 	return valid, nil
 }
 
-func parseFinetuneEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*FinetuneEvent, error) {
+func parseFinetuneEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*FinetuneEvent, error) {
 	finetune := &FinetuneEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -375,19 +428,23 @@ func parseFinetuneEvent(evt nostr.Event, subspaceID, operation string, authTag c
 			finetune.ProviderID = tag[1]
 		case "model_name":
 			finetune.ModelName = tag[1]
+		case "input":
+			finetune.Inputs = append(finetune.Inputs, parseInput(tag))
 		}
 	}
 
 	return finetune, nil
 }
 
-func parseConversationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ConversationEvent, error) {
+func parseConversationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ConversationEvent, error) {
 	conversation := &ConversationEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -413,13 +470,15 @@ func parseConversationEvent(evt nostr.Event, subspaceID, operation string, authT
 	return conversation, nil
 }
 
-func parseSessionEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*SessionEvent, error) {
+func parseSessionEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*SessionEvent, error) {
 	session := &SessionEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
 			Operation:  operation,
 			AuthTag:    authTag,
+			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
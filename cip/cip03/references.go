@@ -0,0 +1,198 @@
+package cip03
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/nbd-wtf/go-nostr/cip/cip02"
+)
+
+var (
+	ErrDanglingReference      = errors.New("cip03: referenced object does not exist")
+	ErrCrossSubspaceReference = errors.New("cip03: referenced object belongs to another subspace")
+	ErrTaskOutsideProject     = errors.New("cip03: referenced task belongs to another project")
+	ErrUnsupportedReferences  = errors.New("cip03: event kind has no checked references")
+)
+
+// Reference is an object an operation points at by one of its tags
+type Reference struct {
+	Field string // tag holding the reference
+	Value string
+	Kind  int  // kind of the referenced object
+	ByID  bool // the value is an event id, else an object id
+}
+
+// ReferenceError reports a reference that doesn't resolve in the subspace of the operation
+type ReferenceError struct {
+	Reference
+	SubspaceID string // subspace the object was found in, for cross-subspace references
+	Err        error
+}
+
+func (e *ReferenceError) Error() string {
+	if e.SubspaceID != "" {
+		return fmt.Sprintf("%s %q: %v (%s)", e.Field, e.Value, e.Err, e.SubspaceID)
+	}
+	return fmt.Sprintf("%s %q: %v", e.Field, e.Value, e.Err)
+}
+
+func (e *ReferenceError) Unwrap() error { return e.Err }
+
+// ReferencesOf returns the references of a dataset, finetune or conversation event, skipping
+// empty ones:
+//   - project_id and task_id point at cip02 projects and tasks
+//   - dataset_id points at a dataset event
+//   - provider_id points at a compute event, unless it is the address of the provider
+//   - session_id points at the session events and model_id at a model event
+func ReferencesOf(evt nostr.Event) ([]Reference, error) {
+	op, err := ParseModelGraphEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	add := func(field, value string, kind int, byID bool) {
+		if value != "" {
+			refs = append(refs, Reference{Field: field, Value: value, Kind: kind, ByID: byID})
+		}
+	}
+	switch e := op.(type) {
+	case *DatasetEvent:
+		add("project_id", e.ProjectID, cip.KindCommonGraphProject, false)
+		add("task_id", e.TaskID, cip.KindCommonGraphTask, false)
+	case *FinetuneEvent:
+		add("project_id", e.ProjectID, cip.KindCommonGraphProject, false)
+		add("task_id", e.TaskID, cip.KindCommonGraphTask, false)
+		add("dataset_id", e.DatasetID, cip.KindModelgraphDataset, true)
+		if !nostr.IsValidAddress(e.ProviderID) {
+			add("provider_id", e.ProviderID, cip.KindModelgraphCompute, true)
+		}
+	case *ConversationEvent:
+		add("session_id", e.SessionID, cip.KindModelgraphSession, false)
+		add("model_id", e.ModelID, cip.KindModelgraphModel, true)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedReferences, evt.Kind)
+	}
+	return refs, nil
+}
+
+// ReferenceChecker verifies that the objects referenced by modelgraph operations exist in the
+// subspace of the operation.
+type ReferenceChecker struct {
+	store nostr.RelayStore
+}
+
+// NewReferenceChecker creates a checker looking the referenced objects up in a store
+func NewReferenceChecker(store nostr.RelayStore) *ReferenceChecker {
+	return &ReferenceChecker{store: store}
+}
+
+// Check resolves the references of a dataset, finetune or conversation event and returns the
+// dangling and cross-subspace ones, along with tasks outside of the referenced project.
+// The error is only set when the event can't be parsed or the store fails.
+//
+// Objects are looked up by event id, or by the d tag addressing them in the subspace and then
+// by their id tag for objects written before they were addressable. The latter needs a store
+// that filters on multi-letter tags.
+func (c *ReferenceChecker) Check(ctx context.Context, evt nostr.Event) ([]*ReferenceError, error) {
+	refs, err := ReferencesOf(evt)
+	if err != nil {
+		return nil, err
+	}
+	sid := evt.Tags.Find("sid")
+	if sid == nil {
+		return nil, errors.New("missing sid tag")
+	}
+	subspaceID := sid[1]
+
+	var problems []*ReferenceError
+	var projectID, taskProject string
+	for _, ref := range refs {
+		found, err := c.lookup(ctx, subspaceID, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		var foundIn string
+		for _, candidate := range found {
+			sid := candidate.Tags.Find("sid")
+			if sid == nil || sid[1] != subspaceID {
+				if foundIn == "" && sid != nil {
+					foundIn = sid[1]
+				}
+				continue
+			}
+			foundIn = subspaceID
+			if ref.Field == "task_id" {
+				if op, err := cip02.ParseCommonGraphEvent(*candidate); err == nil {
+					if task, ok := op.(*cip02.TaskEvent); ok {
+						taskProject = task.ProjectID
+					}
+				}
+			}
+			break
+		}
+
+		switch foundIn {
+		case subspaceID:
+			if ref.Field == "project_id" {
+				projectID = ref.Value
+			}
+		case "":
+			problems = append(problems, &ReferenceError{Reference: ref, Err: ErrDanglingReference})
+		default:
+			problems = append(problems, &ReferenceError{Reference: ref, SubspaceID: foundIn, Err: ErrCrossSubspaceReference})
+		}
+	}
+
+	if projectID != "" && taskProject != "" && taskProject != projectID {
+		for _, ref := range refs {
+			if ref.Field == "task_id" {
+				problems = append(problems, &ReferenceError{Reference: ref, Err: ErrTaskOutsideProject})
+			}
+		}
+	}
+	return problems, nil
+}
+
+// lookup returns the events matching a reference, in any subspace
+func (c *ReferenceChecker) lookup(ctx context.Context, subspaceID string, ref Reference) ([]*nostr.Event, error) {
+	if ref.ByID {
+		return c.store.QuerySync(ctx, nostr.Filter{IDs: []string{ref.Value}, Kinds: []int{ref.Kind}})
+	}
+
+	events, err := c.store.QuerySync(ctx, nostr.Filter{
+		Kinds: []int{ref.Kind},
+		Tags:  nostr.TagMap{"d": []string{cip.ObjectIdentifier(subspaceID, ref.Value)}},
+	})
+	if err != nil || len(events) > 0 {
+		return events, err
+	}
+	return c.store.QuerySync(ctx, nostr.Filter{
+		Kinds: []int{ref.Kind},
+		Tags:  nostr.TagMap{ref.Field: []string{ref.Value}},
+	})
+}
+
+// RejectEvent is an ingest hook for relays that want strict pipelines: it rejects dataset,
+// finetune and conversation events whose references don't resolve in their subspace.
+// Other events are accepted.
+func (c *ReferenceChecker) RejectEvent(ctx context.Context, evt *nostr.Event) (reject bool, msg string) {
+	switch evt.Kind {
+	case cip.KindModelgraphDataset, cip.KindModelgraphFinetune, cip.KindModelgraphConversation:
+	default:
+		return false, ""
+	}
+
+	problems, err := c.Check(ctx, *evt)
+	if err != nil {
+		return true, "error: failed to check references: " + err.Error()
+	}
+	if len(problems) > 0 {
+		return true, "invalid: " + problems[0].Error()
+	}
+	return false, ""
+}
@@ -0,0 +1,140 @@
+package cip03
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip/cip02"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	nostr.RelayStore
+	events []*nostr.Event
+}
+
+func (s *memoryStore) QuerySync(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var res []*nostr.Event
+	for _, evt := range s.events {
+		if filter.Matches(evt) {
+			res = append(res, evt)
+		}
+	}
+	return res, nil
+}
+
+func TestReferenceChecker(t *testing.T) {
+	otherSID := "0x" + testSID[4:] + "ff"
+	ctx := context.Background()
+
+	project, err := cip02.NewProjectEvent(testSID)
+	require.NoError(t, err)
+	project.SetProjectInfo("p1", "Project", "", nil, "active")
+	project.ID = eventID(1)
+
+	task, err := cip02.NewTaskEvent(testSID)
+	require.NoError(t, err)
+	task.SetTaskInfo("p1", "t1", "Collect", "", "todo", "", "")
+	task.ID = eventID(2)
+
+	otherTask, err := cip02.NewTaskEvent(testSID)
+	require.NoError(t, err)
+	otherTask.SetTaskInfo("p2", "t2", "Elsewhere", "", "todo", "", "")
+	otherTask.ID = eventID(3)
+
+	// a task written before objects had their own d tag
+	legacyTask, err := cip02.NewTaskEvent(testSID)
+	require.NoError(t, err)
+	legacyTask.SetTaskInfo("p1", "t3", "Legacy", "", "todo", "", "")
+	legacyTask.Tags[0] = nostr.Tag{"d", "subspace_op"}
+	legacyTask.ID = eventID(4)
+
+	foreignProject, err := cip02.NewProjectEvent(otherSID)
+	require.NoError(t, err)
+	foreignProject.SetProjectInfo("p9", "Foreign", "", nil, "active")
+	foreignProject.ID = eventID(5)
+
+	dataset, err := NewDatasetEvent(testSID)
+	require.NoError(t, err)
+	dataset.SetDatasetInfo("p1", "t1", "text", "json", nil)
+	dataset.ID = eventID(6)
+
+	foreignDataset, err := NewDatasetEvent(otherSID)
+	require.NoError(t, err)
+	foreignDataset.SetDatasetInfo("", "", "text", "json", nil)
+	foreignDataset.ID = eventID(7)
+
+	store := &memoryStore{events: []*nostr.Event{
+		&project.Event, &task.Event, &otherTask.Event, &legacyTask.Event,
+		&foreignProject.Event, &dataset.Event, &foreignDataset.Event,
+	}}
+	checker := NewReferenceChecker(store)
+
+	problems, err := checker.Check(ctx, dataset.Event)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	finetune, err := NewFinetuneEvent(testSID)
+	require.NoError(t, err)
+	finetune.SetFinetuneInfo("p1", "t3", dataset.ID, "0x000000000000000000000000000000000000dEaD", "m1")
+	problems, err = checker.Check(ctx, finetune.Event)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	reject, _ := checker.RejectEvent(ctx, &finetune.Event)
+	assert.False(t, reject)
+
+	finetune, err = NewFinetuneEvent(testSID)
+	require.NoError(t, err)
+	finetune.SetFinetuneInfo("p1", "t2", foreignDataset.ID, "provider_001", "m1")
+	problems, err = checker.Check(ctx, finetune.Event)
+	require.NoError(t, err)
+	require.Len(t, problems, 3)
+	assert.Equal(t, "dataset_id", problems[0].Field)
+	assert.ErrorIs(t, problems[0], ErrCrossSubspaceReference)
+	assert.Equal(t, otherSID, problems[0].SubspaceID)
+	assert.Equal(t, "provider_id", problems[1].Field)
+	assert.ErrorIs(t, problems[1], ErrDanglingReference)
+	assert.Equal(t, "task_id", problems[2].Field)
+	assert.ErrorIs(t, problems[2], ErrTaskOutsideProject)
+
+	reject, msg := checker.RejectEvent(ctx, &finetune.Event)
+	assert.True(t, reject)
+	assert.Contains(t, msg, "invalid: dataset_id")
+
+	dataset, err = NewDatasetEvent(testSID)
+	require.NoError(t, err)
+	dataset.SetDatasetInfo("p9", "t9", "text", "json", nil)
+	problems, err = checker.Check(ctx, dataset.Event)
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+	assert.ErrorIs(t, problems[0], ErrCrossSubspaceReference)
+	assert.ErrorIs(t, problems[1], ErrDanglingReference)
+
+	conversation, err := NewConversationEvent(testSID)
+	require.NoError(t, err)
+	conversation.SetConversationInfo("s1", "u1", eventID(42), "1712345678", "h")
+	problems, err = checker.Check(ctx, conversation.Event)
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+	assert.Equal(t, "session_id", problems[0].Field)
+	assert.Equal(t, "model_id", problems[1].Field)
+
+	session, err := NewSessionEvent(testSID)
+	require.NoError(t, err)
+	session.SetSessionInfo("s1", "start", "u1", "1712345678", "")
+	model, err := NewModelEvent(testSID)
+	require.NoError(t, err)
+	model.ID = eventID(42)
+	store.events = append(store.events, &session.Event, &model.Event)
+	problems, err = checker.Check(ctx, conversation.Event)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	// events without references pass the hook
+	reject, _ = checker.RejectEvent(ctx, &model.Event)
+	assert.False(t, reject)
+	_, err = checker.Check(ctx, model.Event)
+	assert.ErrorIs(t, err, ErrUnsupportedReferences)
+}
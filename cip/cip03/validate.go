@@ -0,0 +1,91 @@
+package cip03
+
+import (
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// Validate checks the parent hash, the inputs and the contribution weights of the model
+func (e *ModelEvent) Validate() error {
+	v := e.Validator()
+	v.Hash("parent", e.ParentHash)
+	validateInputs(v, e.Inputs)
+	_, err := e.ParsedContributions()
+	v.CheckErr("contrib", err)
+	return v.Err()
+}
+
+func validateInputs(v *cip.Validator, inputs []Input) {
+	for _, input := range inputs {
+		v.Check(input.Role != "", "input", "missing role for %q", input.EventID)
+		if v.Required("input", input.EventID) {
+			v.Hash("input", input.EventID)
+		}
+	}
+}
+
+// Validate checks that the compute operation has a type
+func (e *ComputeEvent) Validate() error {
+	v := e.Validator()
+	v.Required("compute_type", e.ComputeType)
+	return v.Err()
+}
+
+// Validate checks that the algorithm operation has a type
+func (e *AlgoEvent) Validate() error {
+	v := e.Validator()
+	v.Required("algo_type", e.AlgoType)
+	return v.Err()
+}
+
+// Validate checks that the validation operation has a result
+func (e *ValidEvent) Validate() error {
+	v := e.Validator()
+	v.Required("valid_result", e.ValidResult)
+	return v.Err()
+}
+
+// Validate checks that the dataset has a category and a format
+func (e *DatasetEvent) Validate() error {
+	v := e.Validator()
+	v.Required("category", e.Category)
+	v.Required("format", e.Format)
+	return v.Err()
+}
+
+// Validate checks that the finetune references a dataset, a provider and a model, and its inputs
+func (e *FinetuneEvent) Validate() error {
+	v := e.Validator()
+	v.Required("dataset_id", e.DatasetID)
+	v.Required("provider_id", e.ProviderID)
+	v.Required("model_name", e.ModelName)
+	validateInputs(v, e.Inputs)
+	return v.Err()
+}
+
+// Validate checks the conversation references and its timestamp
+func (e *ConversationEvent) Validate() error {
+	v := e.Validator()
+	v.Required("session_id", e.SessionID)
+	v.Required("user_id", e.UserID)
+	v.Required("model_id", e.ModelID)
+	v.Integer("timestamp", e.Timestamp)
+	return v.Err()
+}
+
+// Validate checks the session references and that it does not end before it starts
+func (e *SessionEvent) Validate() error {
+	v := e.Validator()
+	v.Required("session_id", e.SessionID)
+	v.Required("action", e.Action)
+	v.Required("user_id", e.UserID)
+	v.Integer("start_time", e.StartTime)
+	v.Integer("end_time", e.EndTime)
+	start, err1 := strconv.ParseUint(e.StartTime, 10, 64)
+	end, err2 := strconv.ParseUint(e.EndTime, 10, 64)
+	if err1 == nil && err2 == nil && end < start {
+		v.Fail("end_time", "%d is before start_time %d", end, start)
+	}
+	return v.Err()
+}
@@ -1 +1,212 @@
-package cip04
\ No newline at end of file
+package cip04
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// KeyEvent is implemented by every causality key lifecycle event
+type KeyEvent interface {
+	nostr.SubspaceOpEventPtr
+	GetKey() uint32
+	Counter() uint64
+	base() *keyOp
+}
+
+// keyOp holds the fields shared by the causality key events
+type keyOp struct {
+	*nostr.SubspaceOpEvent
+	Key uint32
+}
+
+func (e *keyOp) GetKey() uint32 { return e.Key }
+
+func (e *keyOp) base() *keyOp { return e }
+
+// Counter returns the counter of the event clock for its key, which orders the lifecycle of the key
+func (e *keyOp) Counter() uint64 {
+	if e.Clock == nil {
+		return 0
+	}
+	return e.Clock.Get(e.Key)
+}
+
+func (e *keyOp) setKey(key uint32) {
+	e.Key = key
+	e.Tags = append(e.Tags, nostr.Tag{"key", strconv.FormatUint(uint64(key), 10)})
+}
+
+// KeyIssueEvent creates a causality key in a subspace and gives it to its first holder
+type KeyIssueEvent struct {
+	keyOp
+	Holder string
+}
+
+// SetIssueInfo sets the issued key and its first holder
+func (e *KeyIssueEvent) SetIssueInfo(key uint32, holder string) {
+	e.setKey(key)
+	e.Holder = holder
+	e.Tags = append(e.Tags, nostr.Tag{"holder", holder})
+}
+
+// KeyTransferEvent moves a causality key from its current holder, the author, to another address
+type KeyTransferEvent struct {
+	keyOp
+	To string
+}
+
+// SetTransferInfo sets the transferred key and its new holder
+func (e *KeyTransferEvent) SetTransferInfo(key uint32, to string) {
+	e.setKey(key)
+	e.To = to
+	e.Tags = append(e.Tags, nostr.Tag{"to", to})
+}
+
+// KeyBindEvent makes a causality key of its subspace usable in a target subspace
+type KeyBindEvent struct {
+	keyOp
+	Target string
+}
+
+// SetBindInfo sets the bound key and the subspace it is bound to
+func (e *KeyBindEvent) SetBindInfo(key uint32, targetSubspaceID string) {
+	e.setKey(key)
+	e.Target = targetSubspaceID
+	e.Tags = append(e.Tags, nostr.Tag{"target", targetSubspaceID})
+}
+
+// KeyRetireEvent retires a causality key, nobody holds it afterwards
+type KeyRetireEvent struct {
+	keyOp
+}
+
+// SetRetireInfo sets the retired key
+func (e *KeyRetireEvent) SetRetireInfo(key uint32) {
+	e.setKey(key)
+}
+
+// ParseKeyTokenEvent parses a Nostr event into a causality key event
+func ParseKeyTokenEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
+	// Extract common fields
+	subspaceID := ""
+	parents := []string{}
+	var authTag cip.AuthTag
+	var clock *cip.VLC
+	var key uint32
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "sid":
+			subspaceID = tag[1]
+		case "auth":
+			auth, err := cip.ParseAuthTag(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
+			}
+			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
+		case "parent":
+			parents = append(parents, tag[1:]...)
+		case "key":
+			k, err := strconv.ParseUint(tag[1], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse key tag: %v", err)
+			}
+			key = uint32(k)
+		}
+	}
+
+	// Get operation from kind
+	operation, exists := cip.GetOpFromKind(evt.Kind)
+	if !exists {
+		return nil, fmt.Errorf("unknown kind value: %d", evt.Kind)
+	}
+
+	op := keyOp{
+		SubspaceOpEvent: &nostr.SubspaceOpEvent{
+			SubspaceID: subspaceID,
+			Operation:  operation,
+			AuthTag:    authTag,
+			Event:      evt,
+			Parents:    parents,
+			Clock:      clock,
+		},
+		Key: key,
+	}
+
+	// Parse based on operation type
+	switch operation {
+	case cip.OpKeyIssue:
+		return &KeyIssueEvent{keyOp: op, Holder: tagValue(evt, "holder")}, nil
+	case cip.OpKeyTransfer:
+		return &KeyTransferEvent{keyOp: op, To: tagValue(evt, "to")}, nil
+	case cip.OpKeyBind:
+		return &KeyBindEvent{keyOp: op, Target: tagValue(evt, "target")}, nil
+	case cip.OpKeyRetire:
+		return &KeyRetireEvent{keyOp: op}, nil
+	default:
+		return nil, fmt.Errorf("unknown operation type: %s", operation)
+	}
+}
+
+func tagValue(evt nostr.Event, name string) string {
+	if tag := evt.Tags.Find(name); tag != nil {
+		return tag[1]
+	}
+	return ""
+}
+
+func newKeyOp(subspaceID string, kind int) (keyOp, error) {
+	baseEvent, err := nostr.NewSubspaceOpEvent(subspaceID, kind)
+	if err != nil {
+		return keyOp{}, err
+	}
+	return keyOp{SubspaceOpEvent: baseEvent}, nil
+}
+
+// NewKeyIssueEvent creates a new key issue event
+func NewKeyIssueEvent(subspaceID string) (*KeyIssueEvent, error) {
+	op, err := newKeyOp(subspaceID, cip.KindKeyIssue)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyIssueEvent{keyOp: op}, nil
+}
+
+// NewKeyTransferEvent creates a new key transfer event
+func NewKeyTransferEvent(subspaceID string) (*KeyTransferEvent, error) {
+	op, err := newKeyOp(subspaceID, cip.KindKeyTransfer)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyTransferEvent{keyOp: op}, nil
+}
+
+// NewKeyBindEvent creates a new key bind event
+func NewKeyBindEvent(subspaceID string) (*KeyBindEvent, error) {
+	op, err := newKeyOp(subspaceID, cip.KindKeyBind)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyBindEvent{keyOp: op}, nil
+}
+
+// NewKeyRetireEvent creates a new key retire event
+func NewKeyRetireEvent(subspaceID string) (*KeyRetireEvent, error) {
+	op, err := newKeyOp(subspaceID, cip.KindKeyRetire)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyRetireEvent{keyOp: op}, nil
+}
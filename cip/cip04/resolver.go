@@ -0,0 +1,223 @@
+package cip04
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrUnknownKey   = errors.New("cip04: key is not issued")
+	ErrKeyRetired   = errors.New("cip04: key is retired")
+	ErrNotHolder    = errors.New("cip04: author does not hold the key")
+	ErrNotIssuer    = errors.New("cip04: author may not issue keys in this subspace")
+	ErrKeyExists    = errors.New("cip04: key is already issued")
+	ErrStaleCounter = errors.New("cip04: counter does not advance the key")
+)
+
+// KeyState is the state of a key after one of its lifecycle events
+type KeyState struct {
+	Counter uint64
+	EventID string
+	Holder  string // empty once retired
+	Retired bool
+}
+
+// keyRef identifies a key by the subspace it was issued in
+type keyRef struct {
+	subspaceID string
+	key        uint32
+}
+
+// binding is an accepted bind of a key to another subspace
+type binding struct {
+	target  string
+	counter uint64
+	eventID string
+}
+
+// Resolver collects causality key events and answers which address holds a key in a subspace
+// at a given clock value. Events may be added in any order, the lifecycle of each key is
+// replayed by increasing counter, then event id, and invalid transitions are skipped.
+type Resolver struct {
+	creators map[string]string
+	events   map[keyRef][]KeyEvent
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		creators: make(map[string]string),
+		events:   make(map[keyRef][]KeyEvent),
+	}
+}
+
+// SetCreator restricts the issuance of keys in a subspace to its creator.
+// Without it the first issue of a key wins.
+func (r *Resolver) SetCreator(subspaceID, creator string) {
+	r.creators[subspaceID] = creator
+}
+
+// Add records a causality key event. Its transition is only checked when the key is resolved.
+func (r *Resolver) Add(evt nostr.Event) error {
+	op, err := ParseKeyTokenEvent(evt)
+	if err != nil {
+		return err
+	}
+	if err := op.Validate(); err != nil {
+		return err
+	}
+	e := op.(KeyEvent)
+	ref := keyRef{e.GetSubspaceID(), e.GetKey()}
+	for _, existing := range r.events[ref] {
+		if existing.base().ID == evt.ID {
+			return nil
+		}
+	}
+	r.events[ref] = append(r.events[ref], e)
+	return nil
+}
+
+// History replays the lifecycle of a key issued in a subspace and returns its successive
+// states, along with the errors of the events that were skipped
+func (r *Resolver) History(subspaceID string, key uint32) ([]KeyState, []error) {
+	history, _, errs := r.replay(keyRef{subspaceID, key})
+	return history, errs
+}
+
+// replay applies the events of a key in order and also returns its bindings to other subspaces
+func (r *Resolver) replay(ref keyRef) ([]KeyState, []binding, []error) {
+	events := slices.Clone(r.events[ref])
+	slices.SortFunc(events, func(a, b KeyEvent) int {
+		if a.Counter() != b.Counter() {
+			if a.Counter() < b.Counter() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.base().ID, b.base().ID)
+	})
+
+	var history []KeyState
+	var bindings []binding
+	var errs []error
+	var issuer string
+	for _, e := range events {
+		id, author := e.base().ID, e.base().PubKey
+		reject := func(err error) { errs = append(errs, fmt.Errorf("%w: event %s", err, id)) }
+
+		if _, ok := e.(*KeyIssueEvent); !ok && len(history) == 0 {
+			reject(ErrUnknownKey)
+			continue
+		}
+		var current KeyState
+		if len(history) > 0 {
+			current = history[len(history)-1]
+			if e.Counter() <= current.Counter {
+				reject(ErrStaleCounter)
+				continue
+			}
+			if current.Retired {
+				reject(ErrKeyRetired)
+				continue
+			}
+		}
+		next := KeyState{Counter: e.Counter(), EventID: id, Holder: current.Holder}
+
+		switch e := e.(type) {
+		case *KeyIssueEvent:
+			if len(history) > 0 {
+				reject(ErrKeyExists)
+				continue
+			}
+			if creator, ok := r.creators[ref.subspaceID]; ok && !samePubKey(author, creator) {
+				reject(ErrNotIssuer)
+				continue
+			}
+			issuer = author
+			next.Holder = e.Holder
+		case *KeyTransferEvent:
+			if !samePubKey(author, current.Holder) {
+				reject(ErrNotHolder)
+				continue
+			}
+			next.Holder = e.To
+		case *KeyBindEvent:
+			if !samePubKey(author, current.Holder) {
+				reject(ErrNotHolder)
+				continue
+			}
+			bindings = append(bindings, binding{target: e.Target, counter: e.Counter(), eventID: id})
+		case *KeyRetireEvent:
+			if !samePubKey(author, current.Holder) && !samePubKey(author, issuer) {
+				reject(ErrNotHolder)
+				continue
+			}
+			next.Holder = ""
+			next.Retired = true
+		}
+		history = append(history, next)
+	}
+	return history, bindings, errs
+}
+
+// HolderAt returns the address holding key in a subspace at the given clock value. Keys issued
+// in the subspace take precedence, otherwise the key is looked up among the keys bound to the
+// subspace, the earliest binding winning.
+func (r *Resolver) HolderAt(subspaceID string, key uint32, clock uint64) (string, error) {
+	ref := keyRef{subspaceID, key}
+	if history, _, _ := r.replay(ref); len(history) > 0 && history[0].Counter <= clock {
+		return holderAt(history, clock)
+	}
+
+	var best *binding
+	var bestHistory []KeyState
+	for other := range r.events {
+		if other.key != key || other.subspaceID == subspaceID {
+			continue
+		}
+		history, bindings, _ := r.replay(other)
+		for i, b := range bindings {
+			if b.counter > clock || b.target != subspaceID {
+				continue
+			}
+			if best == nil || b.counter < best.counter || (b.counter == best.counter && b.eventID < best.eventID) {
+				best = &bindings[i]
+				bestHistory = history
+			}
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("%w: key %d in subspace %s at clock %d", ErrUnknownKey, key, subspaceID, clock)
+	}
+	return holderAt(bestHistory, clock)
+}
+
+// Holder returns the address currently holding key in a subspace
+func (r *Resolver) Holder(subspaceID string, key uint32) (string, error) {
+	return r.HolderAt(subspaceID, key, ^uint64(0))
+}
+
+func holderAt(history []KeyState, clock uint64) (string, error) {
+	i, _ := slices.BinarySearchFunc(history, clock, func(state KeyState, clock uint64) int {
+		if state.Counter <= clock {
+			return -1
+		}
+		return 1
+	})
+	if i == 0 {
+		return "", fmt.Errorf("%w at clock %d", ErrUnknownKey, clock)
+	}
+	state := history[i-1]
+	if state.Retired {
+		return "", fmt.Errorf("%w at clock %d", ErrKeyRetired, state.Counter)
+	}
+	return state.Holder, nil
+}
+
+// samePubKey compares pubkeys ignoring the case of addresses
+func samePubKey(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
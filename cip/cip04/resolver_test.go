@@ -0,0 +1,138 @@
+package cip04
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	homeSID  = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	otherSID = "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
+	testKey  = uint32(7)
+)
+
+// sign stamps the event with the counter of the key and signs it with sk
+func sign(t *testing.T, sk string, e KeyEvent, counter uint64) nostr.Event {
+	op := e.base()
+	clock := cip.NewVLC()
+	clock.Set(op.Key, counter)
+	op.SetClock(clock)
+	assert.NoError(t, op.Sign(sk))
+	assert.NoError(t, e.Validate())
+	return op.Event
+}
+
+func TestKeyLifecycle(t *testing.T) {
+	owner, alice, bob := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	ownerAddr, _ := nostr.GetAddress(owner)
+	aliceAddr, _ := nostr.GetAddress(alice)
+	bobAddr, _ := nostr.GetAddress(bob)
+
+	issue, _ := NewKeyIssueEvent(homeSID)
+	issue.SetIssueInfo(testKey, aliceAddr)
+	transfer, _ := NewKeyTransferEvent(homeSID)
+	transfer.SetTransferInfo(testKey, bobAddr)
+	stolen, _ := NewKeyTransferEvent(homeSID)
+	stolen.SetTransferInfo(testKey, aliceAddr)
+	retire, _ := NewKeyRetireEvent(homeSID)
+	retire.SetRetireInfo(testKey)
+
+	events := []nostr.Event{
+		sign(t, owner, retire, 9),
+		sign(t, alice, stolen, 6), // alice no longer holds the key
+		sign(t, alice, transfer, 5),
+		sign(t, owner, issue, 1),
+	}
+
+	r := NewResolver()
+	r.SetCreator(homeSID, ownerAddr)
+	for _, evt := range events {
+		assert.NoError(t, r.Add(evt))
+	}
+
+	history, errs := r.History(homeSID, testKey)
+	assert.Len(t, history, 3)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrNotHolder)
+
+	_, err := r.HolderAt(homeSID, testKey, 0)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	holder, err := r.HolderAt(homeSID, testKey, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, aliceAddr, holder)
+	holder, err = r.HolderAt(homeSID, testKey, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, bobAddr, holder)
+	_, err = r.Holder(homeSID, testKey)
+	assert.ErrorIs(t, err, ErrKeyRetired)
+
+	// typed fields survive a round trip
+	parsed, err := ParseKeyTokenEvent(events[2])
+	assert.NoError(t, err)
+	assert.Equal(t, bobAddr, parsed.(*KeyTransferEvent).To)
+	assert.Equal(t, uint64(5), parsed.(KeyEvent).Counter())
+}
+
+func TestKeyIssuers(t *testing.T) {
+	owner, alice := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	ownerAddr, _ := nostr.GetAddress(owner)
+	aliceAddr, _ := nostr.GetAddress(alice)
+
+	rogue, _ := NewKeyIssueEvent(homeSID)
+	rogue.SetIssueInfo(testKey, aliceAddr)
+	issue, _ := NewKeyIssueEvent(homeSID)
+	issue.SetIssueInfo(testKey, ownerAddr)
+
+	r := NewResolver()
+	r.SetCreator(homeSID, ownerAddr)
+	assert.NoError(t, r.Add(sign(t, alice, rogue, 1)))
+	assert.NoError(t, r.Add(sign(t, owner, issue, 2)))
+	holder, err := r.Holder(homeSID, testKey)
+	assert.NoError(t, err)
+	assert.Equal(t, ownerAddr, holder)
+
+	// a second issue of the same key is rejected
+	again, _ := NewKeyIssueEvent(homeSID)
+	again.SetIssueInfo(testKey, aliceAddr)
+	assert.NoError(t, r.Add(sign(t, owner, again, 3)))
+	_, errs := r.History(homeSID, testKey)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[1], ErrKeyExists)
+}
+
+func TestKeyBinding(t *testing.T) {
+	owner, alice, bob := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	aliceAddr, _ := nostr.GetAddress(alice)
+	bobAddr, _ := nostr.GetAddress(bob)
+
+	issue, _ := NewKeyIssueEvent(homeSID)
+	issue.SetIssueInfo(testKey, aliceAddr)
+	bind, _ := NewKeyBindEvent(homeSID)
+	bind.SetBindInfo(testKey, otherSID)
+	transfer, _ := NewKeyTransferEvent(homeSID)
+	transfer.SetTransferInfo(testKey, bobAddr)
+
+	r := NewResolver()
+	assert.NoError(t, r.Add(sign(t, owner, issue, 1)))
+	assert.NoError(t, r.Add(sign(t, alice, bind, 2)))
+	assert.NoError(t, r.Add(sign(t, alice, transfer, 3)))
+
+	_, err := r.HolderAt(otherSID, testKey, 1)
+	assert.ErrorIs(t, err, ErrUnknownKey, "not bound yet")
+	holder, err := r.HolderAt(otherSID, testKey, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, aliceAddr, holder)
+	holder, err = r.Holder(otherSID, testKey)
+	assert.NoError(t, err)
+	assert.Equal(t, bobAddr, holder, "the bound key follows its holder")
+
+	// keys cannot be bound to their own subspace
+	self, _ := NewKeyBindEvent(homeSID)
+	self.SetBindInfo(testKey, homeSID)
+	self.SetClock(cip.NewVLC())
+	assert.Error(t, self.Validate())
+	assert.Error(t, r.Add(self.Event))
+}
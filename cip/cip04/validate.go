@@ -0,0 +1,47 @@
+package cip04
+
+import (
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// validator checks the key and that the event clock has a counter for it
+func (e *keyOp) validator() *cip.Validator {
+	v := e.Validator()
+	v.Check(e.Key != 0, "key", "is required")
+	v.Check(e.Counter() != 0, "vlc", "has no counter for key %d", e.Key)
+	return v
+}
+
+// Validate checks the key and its first holder
+func (e *KeyIssueEvent) Validate() error {
+	v := e.validator()
+	if v.Required("holder", e.Holder) {
+		v.Check(nostr.IsValidPublicKey(e.Holder), "holder", "not an address or public key: %q", e.Holder)
+	}
+	return v.Err()
+}
+
+// Validate checks the key and its new holder
+func (e *KeyTransferEvent) Validate() error {
+	v := e.validator()
+	if v.Required("to", e.To) {
+		v.Check(nostr.IsValidPublicKey(e.To), "to", "not an address or public key: %q", e.To)
+	}
+	return v.Err()
+}
+
+// Validate checks the key and that it is bound to another subspace
+func (e *KeyBindEvent) Validate() error {
+	v := e.validator()
+	if v.Required("target", e.Target) {
+		v.CheckErr("target", cip.ValidateSubspaceID(e.Target))
+		v.Check(e.Target != e.SubspaceID, "target", "cannot bind a key to its own subspace")
+	}
+	return v.Err()
+}
+
+// Validate checks the retired key
+func (e *KeyRetireEvent) Validate() error {
+	return e.validator().Err()
+}
@@ -28,6 +28,7 @@ func (e *PaperEvent) SetPaperInfo(doi, paperType string, authors, keywords []str
 	e.Year = year
 	e.Journal = journal
 
+	e.SetObjectID(doi)
 	e.Tags = append(e.Tags,
 		nostr.Tag{"doi", doi},
 		nostr.Tag{"paper_type", paperType},
@@ -220,6 +221,7 @@ func ParseOpenResearchEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Extract common fields
 	subspaceID := ""
 	var authTag cip.AuthTag
+	var clock *cip.VLC
 	parents := []string{}
 
 	for _, tag := range evt.Tags {
@@ -235,6 +237,12 @@ func ParseOpenResearchEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
 			}
 			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
 		case "parent":
 			parents = append(parents, tag[1:]...)
 		}
@@ -249,25 +257,25 @@ func ParseOpenResearchEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Parse based on operation type
 	switch operation {
 	case cip.OpPaper:
-		return parsePaperEvent(evt, subspaceID, operation, authTag, parents)
+		return parsePaperEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpAnnotation:
-		return parseAnnotationEvent(evt, subspaceID, operation, authTag, parents)
+		return parseAnnotationEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpReview:
-		return parseReviewEvent(evt, subspaceID, operation, authTag, parents)
+		return parseReviewEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpAIAnalysis:
-		return parseAIAnalysisEvent(evt, subspaceID, operation, authTag, parents)
+		return parseAIAnalysisEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpDiscussion:
-		return parseDiscussionEvent(evt, subspaceID, operation, authTag, parents)
+		return parseDiscussionEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpReadPaper:
-		return parseReadPaperEvent(evt, subspaceID, operation, authTag, parents)
+		return parseReadPaperEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpCoCreate:
-		return parseCoCreatePaperEvent(evt, subspaceID, operation, authTag, parents)
+		return parseCoCreatePaperEvent(evt, subspaceID, operation, authTag, parents, clock)
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operation)
 	}
 }
 
-func parsePaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*PaperEvent, error) {
+func parsePaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*PaperEvent, error) {
 	paper := &PaperEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -275,6 +283,7 @@ func parsePaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	paper.Event.Content = evt.Content
@@ -302,7 +311,7 @@ func parsePaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.
 	return paper, nil
 }
 
-func parseAnnotationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*AnnotationEvent, error) {
+func parseAnnotationEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*AnnotationEvent, error) {
 	annotation := &AnnotationEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -310,6 +319,7 @@ func parseAnnotationEvent(evt nostr.Event, subspaceID, operation string, authTag
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	annotation.Event.Content = evt.Content
@@ -333,7 +343,7 @@ func parseAnnotationEvent(evt nostr.Event, subspaceID, operation string, authTag
 	return annotation, nil
 }
 
-func parseReviewEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ReviewEvent, error) {
+func parseReviewEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ReviewEvent, error) {
 	review := &ReviewEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -341,6 +351,7 @@ func parseReviewEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 		Aspects: make(map[string]string),
@@ -371,7 +382,7 @@ func parseReviewEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 	return review, nil
 }
 
-func parseAIAnalysisEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*AIAnalysisEvent, error) {
+func parseAIAnalysisEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*AIAnalysisEvent, error) {
 	analysis := &AIAnalysisEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -379,6 +390,7 @@ func parseAIAnalysisEvent(evt nostr.Event, subspaceID, operation string, authTag
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -400,7 +412,7 @@ func parseAIAnalysisEvent(evt nostr.Event, subspaceID, operation string, authTag
 	return analysis, nil
 }
 
-func parseDiscussionEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*DiscussionEvent, error) {
+func parseDiscussionEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*DiscussionEvent, error) {
 	discussion := &DiscussionEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -408,6 +420,7 @@ func parseDiscussionEvent(evt nostr.Event, subspaceID, operation string, authTag
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
@@ -429,7 +442,7 @@ func parseDiscussionEvent(evt nostr.Event, subspaceID, operation string, authTag
 	return discussion, nil
 }
 
-func parseReadPaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ReadPaperEvent, error) {
+func parseReadPaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ReadPaperEvent, error) {
 	readPaper := &ReadPaperEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -437,6 +450,7 @@ func parseReadPaperEvent(evt nostr.Event, subspaceID, operation string, authTag
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	readPaper.Event.Content = evt.Content
@@ -460,7 +474,7 @@ func parseReadPaperEvent(evt nostr.Event, subspaceID, operation string, authTag
 	return readPaper, nil
 }
 
-func parseCoCreatePaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*CoCreatePaperEvent, error) {
+func parseCoCreatePaperEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*CoCreatePaperEvent, error) {
 	coCreate := &CoCreatePaperEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -468,6 +482,7 @@ func parseCoCreatePaperEvent(evt nostr.Event, subspaceID, operation string, auth
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 		Content: evt.Content,
 	}
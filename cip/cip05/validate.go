@@ -0,0 +1,84 @@
+package cip04
+
+import (
+	"regexp"
+
+	"github.com/nbd-wtf/go-nostr/cip"
+)
+
+// doiPattern matches a DOI like "10.1234/example.2023"
+var doiPattern = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+
+// yearPattern matches a four digit publication year
+var yearPattern = regexp.MustCompile(`^\d{4}$`)
+
+// IsValidDOI checks if doi is a well formed DOI
+func IsValidDOI(doi string) bool {
+	return doiPattern.MatchString(doi)
+}
+
+// Validate checks the DOI and the publication year of the paper
+func (e *PaperEvent) Validate() error {
+	v := e.ObjectValidator(e.DOI)
+	if v.Required("doi", e.DOI) {
+		v.Check(IsValidDOI(e.DOI), "doi", "malformed DOI: %q", e.DOI)
+	}
+	if e.Year != "" {
+		v.Check(yearPattern.MatchString(e.Year), "year", "not a four digit year: %q", e.Year)
+	}
+	return v.Err()
+}
+
+// Validate checks that the annotation points into a paper
+func (e *AnnotationEvent) Validate() error {
+	v := e.Validator()
+	v.Required("paper_id", e.PaperID)
+	v.Required("position", e.Position)
+	return v.Err()
+}
+
+// Validate checks that the review rates a paper with numeric scores
+func (e *ReviewEvent) Validate() error {
+	v := e.Validator()
+	v.Required("paper_id", e.PaperID)
+	if v.Required("rating", e.Rating) {
+		v.Number("rating", e.Rating)
+	}
+	for aspect, score := range e.Aspects {
+		_, err := cip.ParseNumber(score)
+		v.Check(err == nil, "aspects", "score of %s is not a number: %q", aspect, score)
+	}
+	return v.Err()
+}
+
+// Validate checks that the analysis has a type and covers at least one paper
+func (e *AIAnalysisEvent) Validate() error {
+	v := e.Validator()
+	v.Required("analysis_type", e.AnalysisType)
+	v.RequiredList("paper_ids", e.PaperIDs)
+	return v.Err()
+}
+
+// Validate checks that the discussion has a topic
+func (e *DiscussionEvent) Validate() error {
+	v := e.Validator()
+	v.Required("topic", e.Topic)
+	return v.Err()
+}
+
+// Validate checks the read references and its duration
+func (e *ReadPaperEvent) Validate() error {
+	v := e.Validator()
+	v.Required("paper_id", e.PaperID)
+	v.Required("user_id", e.UserID)
+	v.Number("duration", e.Duration)
+	return v.Err()
+}
+
+// Validate checks that the paper is co-created by at least one user
+func (e *CoCreatePaperEvent) Validate() error {
+	v := e.Validator()
+	v.Required("paper_id", e.PaperID)
+	v.RequiredList("user_ids", e.UserIDs)
+	return v.Err()
+}
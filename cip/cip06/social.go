@@ -226,6 +226,7 @@ func ParseSocialEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Extract common fields
 	subspaceID := ""
 	var authTag cip.AuthTag
+	var clock *cip.VLC
 	parents := []string{}
 
 	for _, tag := range evt.Tags {
@@ -241,6 +242,12 @@ func ParseSocialEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
 			}
 			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
 		case "parent":
 			parents = append(parents, tag[1:]...)
 		}
@@ -255,31 +262,31 @@ func ParseSocialEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Parse based on operation type
 	switch operation {
 	case cip.OpLike:
-		return parseLikeEvent(evt, subspaceID, operation, authTag, parents)
+		return parseLikeEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpCollect:
-		return parseCollectEvent(evt, subspaceID, operation, authTag, parents)
+		return parseCollectEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpShare:
-		return parseShareEvent(evt, subspaceID, operation, authTag, parents)
+		return parseShareEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpComment:
-		return parseCommentEvent(evt, subspaceID, operation, authTag, parents)
+		return parseCommentEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpTag:
-		return parseTagEvent(evt, subspaceID, operation, authTag, parents)
+		return parseTagEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpFollow:
-		return parseFollowEvent(evt, subspaceID, operation, authTag, parents)
+		return parseFollowEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpUnfollow:
-		return parseUnfollowEvent(evt, subspaceID, operation, authTag, parents)
+		return parseUnfollowEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpQuestion:
-		return parseQuestionEvent(evt, subspaceID, operation, authTag, parents)
+		return parseQuestionEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpRoom:
-		return parseRoomEvent(evt, subspaceID, operation, authTag, parents)
+		return parseRoomEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpMessage:
-		return parseMessageEvent(evt, subspaceID, operation, authTag, parents)
+		return parseMessageEvent(evt, subspaceID, operation, authTag, parents, clock)
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operation)
 	}
 }
 
-func parseLikeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*LikeEvent, error) {
+func parseLikeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*LikeEvent, error) {
 	like := &LikeEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -287,6 +294,7 @@ func parseLikeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	like.Event.Content = evt.Content
@@ -306,7 +314,7 @@ func parseLikeEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 	return like, nil
 }
 
-func parseCollectEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*CollectEvent, error) {
+func parseCollectEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*CollectEvent, error) {
 	collect := &CollectEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -314,6 +322,7 @@ func parseCollectEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	collect.Event.Content = evt.Content
@@ -333,7 +342,7 @@ func parseCollectEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 	return collect, nil
 }
 
-func parseShareEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ShareEvent, error) {
+func parseShareEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ShareEvent, error) {
 	share := &ShareEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -341,6 +350,7 @@ func parseShareEvent(evt nostr.Event, subspaceID, operation string, authTag cip.
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	share.Event.Content = evt.Content
@@ -364,7 +374,7 @@ func parseShareEvent(evt nostr.Event, subspaceID, operation string, authTag cip.
 	return share, nil
 }
 
-func parseCommentEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*CommentEvent, error) {
+func parseCommentEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*CommentEvent, error) {
 	comment := &CommentEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -372,6 +382,7 @@ func parseCommentEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	comment.Event.Content = evt.Content
@@ -393,7 +404,7 @@ func parseCommentEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 	return comment, nil
 }
 
-func parseTagEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*TagEvent, error) {
+func parseTagEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*TagEvent, error) {
 	tag := &TagEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -401,6 +412,7 @@ func parseTagEvent(evt nostr.Event, subspaceID, operation string, authTag cip.Au
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	tag.Event.Content = evt.Content
@@ -420,7 +432,7 @@ func parseTagEvent(evt nostr.Event, subspaceID, operation string, authTag cip.Au
 	return tag, nil
 }
 
-func parseFollowEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*FollowEvent, error) {
+func parseFollowEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*FollowEvent, error) {
 	follow := &FollowEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -428,6 +440,7 @@ func parseFollowEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	follow.Event.Content = evt.Content
@@ -447,7 +460,7 @@ func parseFollowEvent(evt nostr.Event, subspaceID, operation string, authTag cip
 	return follow, nil
 }
 
-func parseUnfollowEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*UnfollowEvent, error) {
+func parseUnfollowEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*UnfollowEvent, error) {
 	unfollow := &UnfollowEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -455,6 +468,7 @@ func parseUnfollowEvent(evt nostr.Event, subspaceID, operation string, authTag c
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	unfollow.Event.Content = evt.Content
@@ -474,7 +488,7 @@ func parseUnfollowEvent(evt nostr.Event, subspaceID, operation string, authTag c
 	return unfollow, nil
 }
 
-func parseQuestionEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*QuestionEvent, error) {
+func parseQuestionEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*QuestionEvent, error) {
 	question := &QuestionEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -482,6 +496,7 @@ func parseQuestionEvent(evt nostr.Event, subspaceID, operation string, authTag c
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	question.Event.Content = evt.Content
@@ -503,7 +518,7 @@ func parseQuestionEvent(evt nostr.Event, subspaceID, operation string, authTag c
 	return question, nil
 }
 
-func parseRoomEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*RoomEvent, error) {
+func parseRoomEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*RoomEvent, error) {
 	room := &RoomEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -511,6 +526,7 @@ func parseRoomEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	room.Event.Content = evt.Content
@@ -532,7 +548,7 @@ func parseRoomEvent(evt nostr.Event, subspaceID, operation string, authTag cip.A
 	return room, nil
 }
 
-func parseMessageEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*MessageEvent, error) {
+func parseMessageEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*MessageEvent, error) {
 	message := &MessageEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -540,6 +556,7 @@ func parseMessageEvent(evt nostr.Event, subspaceID, operation string, authTag ci
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	message.Event.Content = evt.Content
@@ -0,0 +1,84 @@
+package cip06
+
+// Validate checks that the like has an object and a user
+func (e *LikeEvent) Validate() error {
+	v := e.Validator()
+	v.Required("object_id", e.ObjectID)
+	v.Required("user_id", e.UserID)
+	return v.Err()
+}
+
+// Validate checks that the collect has an object and a user
+func (e *CollectEvent) Validate() error {
+	v := e.Validator()
+	v.Required("object_id", e.ObjectID)
+	v.Required("user_id", e.UserID)
+	return v.Err()
+}
+
+// Validate checks the share references and its click count
+func (e *ShareEvent) Validate() error {
+	v := e.Validator()
+	v.Required("object_id", e.ObjectID)
+	v.Required("user_id", e.UserID)
+	v.Integer("clicks", e.Clicks)
+	return v.Err()
+}
+
+// Validate checks that the comment has an object and a user
+func (e *CommentEvent) Validate() error {
+	v := e.Validator()
+	v.Required("object_id", e.ObjectID)
+	v.Required("user_id", e.UserID)
+	return v.Err()
+}
+
+// Validate checks that the tag has an object and a value
+func (e *TagEvent) Validate() error {
+	v := e.Validator()
+	v.Required("object_id", e.ObjectID)
+	v.Required("tag", e.Tag)
+	return v.Err()
+}
+
+// Validate checks that the follow links two different users
+func (e *FollowEvent) Validate() error {
+	v := e.Validator()
+	v.Required("user_id", e.UserID)
+	if v.Required("target_id", e.TargetID) {
+		v.Check(e.TargetID != e.UserID, "target_id", "cannot follow oneself")
+	}
+	return v.Err()
+}
+
+// Validate checks that the unfollow links two different users
+func (e *UnfollowEvent) Validate() error {
+	v := e.Validator()
+	v.Required("user_id", e.UserID)
+	if v.Required("target_id", e.TargetID) {
+		v.Check(e.TargetID != e.UserID, "target_id", "cannot unfollow oneself")
+	}
+	return v.Err()
+}
+
+// Validate checks that the question has an object and a user
+func (e *QuestionEvent) Validate() error {
+	v := e.Validator()
+	v.Required("object_id", e.ObjectID)
+	v.Required("user_id", e.UserID)
+	return v.Err()
+}
+
+// Validate checks that the room has a name
+func (e *RoomEvent) Validate() error {
+	v := e.Validator()
+	v.Required("name", e.Name)
+	return v.Err()
+}
+
+// Validate checks that the message is posted in a room
+func (e *MessageEvent) Validate() error {
+	v := e.Validator()
+	v.Required("room_id", e.RoomID)
+	return v.Err()
+}
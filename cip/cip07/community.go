@@ -21,6 +21,7 @@ func (e *CommunityCreateEvent) SetCommunityCreateInfo(communityID, name, communi
 	e.Name = name
 	e.Type = communityType
 
+	e.SetObjectID(communityID)
 	e.Tags = append(e.Tags,
 		nostr.Tag{"community_id", communityID},
 		nostr.Tag{"name", name},
@@ -68,6 +69,7 @@ func (e *ChannelCreateEvent) SetChannelCreateInfo(communityID, channelID, name,
 	e.Name = name
 	e.Type = channelType
 
+	e.SetObjectID(channelID)
 	e.Tags = append(e.Tags,
 		nostr.Tag{"community_id", communityID},
 		nostr.Tag{"channel_id", channelID},
@@ -105,6 +107,7 @@ func ParseCommunityEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Extract common fields
 	subspaceID := ""
 	var authTag cip.AuthTag
+	var clock *cip.VLC
 	parents := []string{}
 
 	for _, tag := range evt.Tags {
@@ -120,6 +123,12 @@ func ParseCommunityEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
 			}
 			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
 		case "parent":
 			parents = append(parents, tag[1:]...)
 		}
@@ -134,19 +143,19 @@ func ParseCommunityEvent(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
 	// Parse based on operation type
 	switch operation {
 	case cip.OpCommunityCreate:
-		return parseCommunityCreateEvent(evt, subspaceID, operation, authTag, parents)
+		return parseCommunityCreateEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpCommunityInvite:
-		return parseCommunityInviteEvent(evt, subspaceID, operation, authTag, parents)
+		return parseCommunityInviteEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpChannelCreate:
-		return parseChannelCreateEvent(evt, subspaceID, operation, authTag, parents)
+		return parseChannelCreateEvent(evt, subspaceID, operation, authTag, parents, clock)
 	case cip.OpChannelMessage:
-		return parseChannelMessageEvent(evt, subspaceID, operation, authTag, parents)
+		return parseChannelMessageEvent(evt, subspaceID, operation, authTag, parents, clock)
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operation)
 	}
 }
 
-func parseCommunityCreateEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*CommunityCreateEvent, error) {
+func parseCommunityCreateEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*CommunityCreateEvent, error) {
 	create := &CommunityCreateEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -154,6 +163,7 @@ func parseCommunityCreateEvent(evt nostr.Event, subspaceID, operation string, au
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	create.Event.Content = evt.Content
@@ -175,7 +185,7 @@ func parseCommunityCreateEvent(evt nostr.Event, subspaceID, operation string, au
 	return create, nil
 }
 
-func parseCommunityInviteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*CommunityInviteEvent, error) {
+func parseCommunityInviteEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*CommunityInviteEvent, error) {
 	invite := &CommunityInviteEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -183,6 +193,7 @@ func parseCommunityInviteEvent(evt nostr.Event, subspaceID, operation string, au
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	invite.Event.Content = evt.Content
@@ -206,7 +217,7 @@ func parseCommunityInviteEvent(evt nostr.Event, subspaceID, operation string, au
 	return invite, nil
 }
 
-func parseChannelCreateEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ChannelCreateEvent, error) {
+func parseChannelCreateEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ChannelCreateEvent, error) {
 	create := &ChannelCreateEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -214,6 +225,7 @@ func parseChannelCreateEvent(evt nostr.Event, subspaceID, operation string, auth
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	create.Event.Content = evt.Content
@@ -237,7 +249,7 @@ func parseChannelCreateEvent(evt nostr.Event, subspaceID, operation string, auth
 	return create, nil
 }
 
-func parseChannelMessageEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*ChannelMessageEvent, error) {
+func parseChannelMessageEvent(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*ChannelMessageEvent, error) {
 	message := &ChannelMessageEvent{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -245,6 +257,7 @@ func parseChannelMessageEvent(evt nostr.Event, subspaceID, operation string, aut
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	message.Event.Content = evt.Content
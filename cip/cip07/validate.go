@@ -0,0 +1,37 @@
+package cip07
+
+// Validate checks that the community has an id and a name
+func (e *CommunityCreateEvent) Validate() error {
+	v := e.ObjectValidator(e.CommunityID)
+	v.Required("community_id", e.CommunityID)
+	v.Required("name", e.Name)
+	return v.Err()
+}
+
+// Validate checks that the invite links an inviter and an invitee of a community
+func (e *CommunityInviteEvent) Validate() error {
+	v := e.Validator()
+	v.Required("community_id", e.CommunityID)
+	v.Required("inviter_id", e.InviterID)
+	if v.Required("invitee_id", e.InviteeID) {
+		v.Check(e.InviteeID != e.InviterID, "invitee_id", "cannot invite oneself")
+	}
+	return v.Err()
+}
+
+// Validate checks that the channel belongs to a community and has a name
+func (e *ChannelCreateEvent) Validate() error {
+	v := e.ObjectValidator(e.ChannelID)
+	v.Required("community_id", e.CommunityID)
+	v.Required("channel_id", e.ChannelID)
+	v.Required("name", e.Name)
+	return v.Err()
+}
+
+// Validate checks that the message is posted in a channel by a user
+func (e *ChannelMessageEvent) Validate() error {
+	v := e.Validator()
+	v.Required("channel_id", e.ChannelID)
+	v.Required("user_id", e.UserID)
+	return v.Err()
+}
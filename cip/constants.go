@@ -5,6 +5,8 @@ const (
 	// Subspace common event kinds
 	KindSubspaceCreate = 30100
 	KindSubspaceJoin   = 30200
+	KindSubspaceGrant  = 30201
+	KindSubspaceRevoke = 30202
 
 	// Governance event kinds
 	KindGovernancePost    = 30300
@@ -20,6 +22,12 @@ const (
 	KindCommonGraphRelation    = 30104
 	KindCommonGraphObservation = 30105
 
+	// KeyToken event kinds, 30402 and 30403 are NIP-99 classified listings
+	KindKeyIssue    = 30420
+	KindKeyTransfer = 30421
+	KindKeyBind     = 30422
+	KindKeyRetire   = 30423
+
 	// Modelgraph event kind
 	KindModelgraphModel        = 30404
 	KindModelgraphDataset      = 30405
@@ -63,6 +71,8 @@ const (
 	// General base operation types
 	OpSubspaceCreate = "subspace_create" // 30100
 	OpSubspaceJoin   = "subspace_join"   // 30200
+	OpGrant          = "grant"           // 30201
+	OpRevoke         = "revoke"          // 30202
 
 	// Governance operation types (governance operations)
 	OpPost    = "post"    // 30300
@@ -78,6 +88,12 @@ const (
 	OpRelation    = "relation"    // 30104
 	OpObservation = "observation" // 30105
 
+	// KeyToken operation types
+	OpKeyIssue    = "key_issue"    // 30420
+	OpKeyTransfer = "key_transfer" // 30421
+	OpKeyBind     = "key_bind"     // 30422
+	OpKeyRetire   = "key_retire"   // 30423
+
 	// Business operation types
 	OpModel        = "model"        // 30404
 	OpDataset      = "dataset"      // 30405
@@ -127,8 +143,11 @@ const (
 	// Default operations string for subspace creation
 	DefaultSubspaceOps = "post=30300,propose=30301,vote=30302,invite=30303,mint=30304"
 
+	// KeyToken operations string
+	KeyTokenSubspaceOps = "key_issue=30420,key_transfer=30421,key_bind=30422,key_retire=30423"
+
 	// Modelgraph operations string for model
-	ModelGraphSubspaceOps = "dataset=30405,finetune=30409,conversation=30410,session=30411"
+	ModelGraphSubspaceOps = "model=30404,dataset=30405,compute=30406,algo=30407,valid=30408,finetune=30409,conversation=30410,session=30411"
 
 	// OpenResearch operations string
 	OpenResearchSubspaceOps = "paper=30501,annotation=30502,review=30503,ai_analysis=30504,discussion=30505,read_paper=30506,co_create_paper=30507"
@@ -0,0 +1,7 @@
+// Package cip defines the event kinds, operations, vector logical clocks and rules shared by the
+// subspace CIPs.
+//
+// The state builders of the CIP packages (state.Subspace, capability.Verifier, the cip02
+// trackers, cip03.Lineage and cip04.Resolver) don't check event ids or signatures, callers must
+// only feed them verified events. None of them are safe for concurrent use.
+package cip
@@ -16,15 +16,11 @@ func GenerateCIP(def CIPDefinition) error {
 		return fmt.Errorf("failed to create directory: %v", err)
 	}
 
-	// Update constants and KeyOpMap
+	// Update constants, the kinds are registered by the generated package itself
 	if err := updateConstants(def); err != nil {
 		return fmt.Errorf("failed to update constants: %v", err)
 	}
 
-	if err := updateKeyOpMap(def); err != nil {
-		return fmt.Errorf("failed to update KeyOpMap: %v", err)
-	}
-
 	// Generate the main implementation file
 	if err := generateImplementationFile(def); err != nil {
 		return err
@@ -137,56 +133,23 @@ func updateConstants(def CIPDefinition) error {
 	return nil
 }
 
-// updateKeyOpMap updates the keys.go file with new operation mappings
-func updateKeyOpMap(def CIPDefinition) error {
-	keysFile := filepath.Join("cip", "keys.go")
-	content, err := os.ReadFile(keysFile)
-	if err != nil {
-		return fmt.Errorf("failed to read keys file: %v", err)
-	}
-
-	// Find the start of the KeyOpMap
-	mapStart := strings.Index(string(content), "var KeyOpMap = map[int]string{")
-	if mapStart == -1 {
-		return fmt.Errorf("could not find KeyOpMap")
-	}
-
-	// Find the closing } of the map
-	mapEnd := strings.Index(string(content[mapStart:]), "}")
-	if mapEnd == -1 {
-		return fmt.Errorf("could not find end of KeyOpMap")
-	}
-	mapEnd += mapStart
-
-	// Prepare new operation mappings
-	var newOps strings.Builder
-	newOps.WriteString("\n\t// " + def.CIPName + " operations\n")
-	for _, event := range def.Events {
-		name := strings.TrimSuffix(event.EventName, "Event")
-		pascalCIP := pascalCase(def.CIPName)
-		pascalName := pascalCase(name)
-		newOps.WriteString(fmt.Sprintf("\tKind%s%s: Op%s,\n", pascalCIP, pascalName, pascalName))
-	}
-
-	// Insert new operations before the closing }
-	newContent := string(content[:mapEnd]) + newOps.String() + string(content[mapEnd:])
-
-	// Write back to file
-	if err := os.WriteFile(keysFile, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write keys file: %v", err)
-	}
-
-	return nil
-}
-
 const eventTemplate = `package {{.Package}}
 
 import (
 	"fmt"
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/nbd-wtf/go-nostr/cip/registry"
 )
 
+func init() {
+	registry.MustRegister("{{.CIPName}}", Parse{{pascalCase .CIPName}}Event, map[int]string{
+{{- range .Events}}
+		cip.Kind{{pascalCase $.CIPName}}{{pascalCase (trimSuffix .EventName "Event")}}: cip.Op{{pascalCase (trimSuffix .EventName "Event")}},
+{{- end}}
+	})
+}
+
 {{- range .Events}}
 {{$event := .}}
 // {{.EventName}} represents a {{.Operation}} operation in {{$.CIPName}} subspace
@@ -234,6 +197,7 @@ func Parse{{pascalCase .CIPName}}Event(evt nostr.Event) (nostr.SubspaceOpEventPt
 	subspaceID := ""
 	parents := []string{}
 	var authTag cip.AuthTag
+	var clock *cip.VLC
 	for _, tag := range evt.Tags {
 		if len(tag) < 2 {
 			continue
@@ -247,6 +211,12 @@ func Parse{{pascalCase .CIPName}}Event(evt nostr.Event) (nostr.SubspaceOpEventPt
 				return nil, fmt.Errorf("failed to parse auth tag: %v", err)
 			}
 			authTag = auth
+		case "vlc":
+			vlc, err := cip.ParseVLC(tag[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse vlc tag: %v", err)
+			}
+			clock = vlc
 		case "parent":
 			parents = append(parents, tag[1:]...)
 		}
@@ -258,7 +228,7 @@ func Parse{{pascalCase .CIPName}}Event(evt nostr.Event) (nostr.SubspaceOpEventPt
 	switch operation {
 {{- range .Events}}
 	case cip.Op{{pascalCase (trimSuffix .EventName "Event")}}:
-		return parse{{.EventName}}(evt, subspaceID, operation, authTag, parents)
+		return parse{{.EventName}}(evt, subspaceID, operation, authTag, parents, clock)
 {{- end}}
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operation)
@@ -267,7 +237,7 @@ func Parse{{pascalCase .CIPName}}Event(evt nostr.Event) (nostr.SubspaceOpEventPt
 
 {{- range .Events}}
 
-func parse{{.EventName}}(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string) (*{{.EventName}}, error) {
+func parse{{.EventName}}(evt nostr.Event, subspaceID, operation string, authTag cip.AuthTag, parents []string, clock *cip.VLC) (*{{.EventName}}, error) {
 	event := &{{.EventName}}{
 		SubspaceOpEvent: &nostr.SubspaceOpEvent{
 			SubspaceID: subspaceID,
@@ -275,6 +245,7 @@ func parse{{.EventName}}(evt nostr.Event, subspaceID, operation string, authTag
 			AuthTag:    authTag,
 			Event:      evt,
 			Parents:    parents,
+			Clock:      clock,
 		},
 	}
 	for _, tag := range evt.Tags {
@@ -0,0 +1,92 @@
+package cip
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// LegacyOpIdentifier is the d tag that every subspace operation used to carry.
+// All CIP kinds are addressable, so relays only kept the latest operation per author and kind.
+const LegacyOpIdentifier = "subspace_op"
+
+// IdentifierForm tells how the d tag of a subspace event was built
+type IdentifierForm int
+
+const (
+	// IdentifierLegacy is a constant d tag: LegacyOpIdentifier or the name of the operation
+	IdentifierLegacy IdentifierForm = iota
+	// IdentifierUnique is a random d tag of an append-only operation
+	IdentifierUnique
+	// IdentifierObject is "<sid>:<object id>", or just "<sid>" for subspace create and join,
+	// so that a newer version of the object replaces the previous one
+	IdentifierObject
+)
+
+func (f IdentifierForm) String() string {
+	switch f {
+	case IdentifierLegacy:
+		return "legacy"
+	case IdentifierUnique:
+		return "unique"
+	case IdentifierObject:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// replaceableOps are the operations describing an object whose latest version is the only one
+// that matters. Every other operation is append-only.
+var replaceableOps = map[string]bool{
+	OpProject:         true,
+	OpTask:            true,
+	OpEntity:          true,
+	OpPaper:           true,
+	OpCommunityCreate: true,
+	OpChannelCreate:   true,
+}
+
+// IsReplaceableOp checks if a newer event of the operation replaces the older ones
+// for the same object
+func IsReplaceableOp(op string) bool {
+	return replaceableOps[op]
+}
+
+// UniqueIdentifier returns a random d tag for an append-only operation
+func UniqueIdentifier() string {
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	return hex.EncodeToString(nonce[:])
+}
+
+// ObjectIdentifier returns the d tag addressing an object of a subspace
+func ObjectIdentifier(sid, objectID string) string {
+	if objectID == "" {
+		return sid
+	}
+	return sid + ":" + objectID
+}
+
+// ParseIdentifier tells the form of a d tag and, for the object form, the subspace and
+// object it addresses
+func ParseIdentifier(d string) (form IdentifierForm, sid, objectID string) {
+	if d == LegacyOpIdentifier {
+		return IdentifierLegacy, "", ""
+	}
+	if _, ok := GetKindFromOp(d); ok {
+		return IdentifierLegacy, "", ""
+	}
+	if len(d) >= 66 && isValidSubspaceID(d[:66]) {
+		if len(d) == 66 {
+			return IdentifierObject, d, ""
+		}
+		if d[66] == ':' {
+			return IdentifierObject, d[:66], d[67:]
+		}
+	}
+	if strings.TrimSpace(d) == "" {
+		return IdentifierLegacy, "", ""
+	}
+	return IdentifierUnique, "", ""
+}
@@ -0,0 +1,37 @@
+package cip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIdentifier(t *testing.T) {
+	sid := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	for _, tc := range []struct {
+		d        string
+		form     IdentifierForm
+		sid      string
+		objectID string
+	}{
+		{LegacyOpIdentifier, IdentifierLegacy, "", ""},
+		{OpSubspaceCreate, IdentifierLegacy, "", ""},
+		{OpSubspaceJoin, IdentifierLegacy, "", ""},
+		{"", IdentifierLegacy, "", ""},
+		{UniqueIdentifier(), IdentifierUnique, "", ""},
+		{ObjectIdentifier(sid, ""), IdentifierObject, sid, ""},
+		{ObjectIdentifier(sid, "proj_001"), IdentifierObject, sid, "proj_001"},
+		{ObjectIdentifier(sid, "10.1234/a:b"), IdentifierObject, sid, "10.1234/a:b"},
+		{sid + "proj_001", IdentifierUnique, "", ""},
+	} {
+		form, gotSID, objectID := ParseIdentifier(tc.d)
+		assert.Equal(t, tc.form, form, tc.d)
+		assert.Equal(t, tc.sid, gotSID, tc.d)
+		assert.Equal(t, tc.objectID, objectID, tc.d)
+	}
+
+	assert.NotEqual(t, UniqueIdentifier(), UniqueIdentifier())
+	assert.True(t, IsReplaceableOp(OpProject))
+	assert.False(t, IsReplaceableOp(OpPost))
+}
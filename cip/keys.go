@@ -1,10 +1,20 @@
 package cip
 
-// KeyOpMap maps kind values to operation names
+import (
+	"fmt"
+	"sync"
+)
+
+var keyOpMu sync.RWMutex
+
+// KeyOpMap maps kind values to operation names.
+// Use RegisterOp to add new operations instead of modifying it directly.
 var KeyOpMap = map[int]string{
 	// common operations
 	KindSubspaceCreate: OpSubspaceCreate,
 	KindSubspaceJoin:   OpSubspaceJoin,
+	KindSubspaceGrant:  OpGrant,
+	KindSubspaceRevoke: OpRevoke,
 
 	// Governance operations
 	KindGovernancePost:    OpPost,
@@ -20,6 +30,12 @@ var KeyOpMap = map[int]string{
 	KindCommonGraphRelation:    OpRelation,
 	KindCommonGraphObservation: OpObservation,
 
+	// KeyToken operations
+	KindKeyIssue:    OpKeyIssue,
+	KindKeyTransfer: OpKeyTransfer,
+	KindKeyBind:     OpKeyBind,
+	KindKeyRetire:   OpKeyRetire,
+
 	// ModelGraph operations
 	KindModelgraphModel:        OpModel,
 	KindModelgraphDataset:      OpDataset,
@@ -58,14 +74,56 @@ var KeyOpMap = map[int]string{
 	KindCommunityChannelMessage: OpChannelMessage,
 }
 
+// RegisterOp registers a new operation for the given kind.
+// Registering the same kind and operation twice is a no-op, reusing a kind or an operation fails.
+func RegisterOp(kind int, op string) error {
+	return RegisterOps(map[int]string{kind: op})
+}
+
+// RegisterOps registers several kind/operation pairs at once, following the rules of RegisterOp.
+// Either all the pairs are registered or, if one of them is rejected, none is.
+func RegisterOps(ops map[int]string) error {
+	keyOpMu.Lock()
+	defer keyOpMu.Unlock()
+
+	kinds := make(map[string]int, len(ops))
+	for kind, op := range ops {
+		if other, exists := kinds[op]; exists {
+			return fmt.Errorf("operation %s is registered with both kinds %d and %d", op, other, kind)
+		}
+		kinds[op] = kind
+	}
+	for kind, op := range ops {
+		if existing, exists := KeyOpMap[kind]; exists && existing != op {
+			return fmt.Errorf("kind %d is already registered as %s", kind, existing)
+		}
+	}
+	for k, operation := range KeyOpMap {
+		if kind, exists := kinds[operation]; exists && kind != k {
+			return fmt.Errorf("operation %s is already registered with kind %d", operation, k)
+		}
+	}
+
+	for kind, op := range ops {
+		KeyOpMap[kind] = op
+	}
+	return nil
+}
+
 // GetOpFromKind returns the operation name for a given kind value
 func GetOpFromKind(kind int) (string, bool) {
+	keyOpMu.RLock()
+	defer keyOpMu.RUnlock()
+
 	op, exists := KeyOpMap[kind]
 	return op, exists
 }
 
 // GetKindFromOp returns the kind value for a given operation name
 func GetKindFromOp(op string) (int, bool) {
+	keyOpMu.RLock()
+	defer keyOpMu.RUnlock()
+
 	for kind, operation := range KeyOpMap {
 		if operation == op {
 			return kind, true
@@ -0,0 +1,37 @@
+package cip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterOp(t *testing.T) {
+	// re-registering an existing pair is a no-op
+	assert.NoError(t, RegisterOp(KindGovernancePost, OpPost))
+
+	assert.Error(t, RegisterOp(KindGovernancePost, "other"))
+	assert.Error(t, RegisterOp(39999, OpPost))
+
+	assert.NoError(t, RegisterOp(39998, "test_op"))
+	op, ok := GetOpFromKind(39998)
+	assert.True(t, ok)
+	assert.Equal(t, "test_op", op)
+	kind, ok := GetKindFromOp("test_op")
+	assert.True(t, ok)
+	assert.Equal(t, 39998, kind)
+}
+
+func TestRegisterOps(t *testing.T) {
+	// a rejected pair leaves the others unregistered
+	assert.Error(t, RegisterOps(map[int]string{39997: "test_op_a", 39996: OpPost}))
+	assert.Error(t, RegisterOps(map[int]string{39997: "test_op_a", KindGovernancePost: "test_op_b"}))
+	assert.Error(t, RegisterOps(map[int]string{39997: "test_op_a", 39996: "test_op_a"}))
+	_, ok := GetOpFromKind(39997)
+	assert.False(t, ok)
+
+	assert.NoError(t, RegisterOps(map[int]string{39997: "test_op_a", 39996: "test_op_b"}))
+	kind, ok := GetKindFromOp("test_op_b")
+	assert.True(t, ok)
+	assert.Equal(t, 39996, kind)
+}
@@ -0,0 +1,112 @@
+package cip
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OpsSpec is the set of operations enabled in a subspace, mapping each operation to its kind
+type OpsSpec map[string]int
+
+// ParseOpsSpec parses an ops string like "post=30300,propose=30301". Whitespace around parts
+// is ignored and repeated pairs are merged, but an operation or a kind may only appear once.
+func ParseOpsSpec(ops string) (OpsSpec, error) {
+	if strings.TrimSpace(ops) == "" {
+		return nil, fmt.Errorf("empty ops")
+	}
+
+	spec := make(OpsSpec)
+	kinds := make(map[int]string)
+	for _, part := range strings.Split(ops, ",") {
+		op, kindStr, found := strings.Cut(strings.TrimSpace(part), "=")
+		op, kindStr = strings.TrimSpace(op), strings.TrimSpace(kindStr)
+		if !found || op == "" || kindStr == "" {
+			return nil, fmt.Errorf("invalid ops part: %q", part)
+		}
+		kind, err := strconv.Atoi(kindStr)
+		if err != nil || kind < 0 {
+			return nil, fmt.Errorf("invalid kind in ops part: %q", part)
+		}
+
+		if existing, ok := spec[op]; ok && existing != kind {
+			return nil, fmt.Errorf("operation %s is mapped to kinds %d and %d", op, existing, kind)
+		}
+		if existing, ok := kinds[kind]; ok && existing != op {
+			return nil, fmt.Errorf("kind %d is mapped to operations %s and %s", kind, existing, op)
+		}
+		spec[op] = kind
+		kinds[kind] = op
+	}
+	return spec, nil
+}
+
+// CanonicalOps parses an ops string and formats it back in its canonical form
+func CanonicalOps(ops string) (string, error) {
+	spec, err := ParseOpsSpec(ops)
+	if err != nil {
+		return "", err
+	}
+	return spec.String(), nil
+}
+
+// String formats the spec in its canonical form, sorted by kind
+func (s OpsSpec) String() string {
+	parts := make([]string, 0, len(s))
+	for _, op := range s.ops() {
+		parts = append(parts, op+"="+strconv.Itoa(s[op]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Validate checks every pair against the registered operations
+func (s OpsSpec) Validate() error {
+	for _, op := range s.ops() {
+		kind := s[op]
+		registered, exists := GetOpFromKind(kind)
+		if !exists {
+			return fmt.Errorf("unknown kind %d for operation %s", kind, op)
+		}
+		if registered != op {
+			return fmt.Errorf("kind %d is %s, not %s", kind, registered, op)
+		}
+	}
+	return nil
+}
+
+// ops returns the enabled operations sorted by kind, kinds being unique
+func (s OpsSpec) ops() []string {
+	ops := make([]string, 0, len(s))
+	for op := range s {
+		ops = append(ops, op)
+	}
+	slices.SortFunc(ops, func(a, b string) int { return s[a] - s[b] })
+	return ops
+}
+
+// Allows checks if the given kind is one of the enabled operations
+func (s OpsSpec) Allows(kind int) bool {
+	for _, k := range s {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Kind returns the kind of an enabled operation
+func (s OpsSpec) Kind(op string) (int, bool) {
+	kind, ok := s[op]
+	return kind, ok
+}
+
+// Kinds returns the enabled kinds in ascending order
+func (s OpsSpec) Kinds() []int {
+	kinds := make([]int, 0, len(s))
+	for _, kind := range s {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
@@ -0,0 +1,63 @@
+package cip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpsSpec(t *testing.T) {
+	spec, err := ParseOpsSpec(" vote=30302, post=30300,propose=30301,post=30300")
+	assert.NoError(t, err)
+	assert.Len(t, spec, 3)
+	assert.Equal(t, "post=30300,propose=30301,vote=30302", spec.String())
+	assert.Equal(t, []int{KindGovernancePost, KindGovernancePropose, KindGovernanceVote}, spec.Kinds())
+	assert.NoError(t, spec.Validate())
+
+	assert.True(t, spec.Allows(KindGovernanceVote))
+	assert.False(t, spec.Allows(KindGovernanceMint))
+	kind, ok := spec.Kind(OpPropose)
+	assert.True(t, ok)
+	assert.Equal(t, KindGovernancePropose, kind)
+
+	for _, ops := range []string{
+		"",
+		"post",
+		"post=",
+		"=30300",
+		"post=abc",
+		"post=30300,post=30301",
+		"post=30300,vote=30300",
+		"post=30300,",
+	} {
+		_, err := ParseOpsSpec(ops)
+		assert.Error(t, err, ops)
+	}
+
+	// pairs must match the registered kinds
+	spec, _ = ParseOpsSpec("post=30301")
+	assert.Error(t, spec.Validate())
+	spec, _ = ParseOpsSpec("teleport=39999")
+	assert.Error(t, spec.Validate())
+
+	// the builtin ops strings are canonical and registered
+	for _, ops := range []string{
+		DefaultSubspaceOps, CommonPrjOps, CommonGraphOps, KeyTokenSubspaceOps, ModelGraphSubspaceOps,
+		OpenResearchSubspaceOps, SocialSubspaceOps, CommunitySubspaceOps,
+	} {
+		canonical, err := CanonicalOps(ops)
+		assert.NoError(t, err)
+		assert.Equal(t, ops, canonical)
+		spec, _ := ParseOpsSpec(ops)
+		assert.NoError(t, spec.Validate(), ops)
+	}
+	spec, _ = ParseOpsSpec(ModelGraphSubspaceOps)
+	assert.True(t, spec.Allows(KindModelgraphModel))
+}
+
+func TestSubspaceIDIgnoresOpsOrder(t *testing.T) {
+	sid := CalculateSubspaceID("test", "vote=30302,post=30300", "")
+	assert.Equal(t, sid, CalculateSubspaceID("test", "post=30300, vote=30302", ""))
+	assert.Equal(t, sid, LegacySubspaceID("test", "post=30300,vote=30302", ""))
+	assert.NotEqual(t, sid, LegacySubspaceID("test", "vote=30302,post=30300", ""))
+}
@@ -0,0 +1,185 @@
+// Package registry maps CIP event kinds to their typed parsers.
+//
+// The builtin CIPs are registered when the package is loaded, third-party CIPs register their
+// kinds from an init function:
+//
+//	func init() {
+//		registry.MustRegister("chat", ParseChatEvent, map[int]string{
+//			KindChatMessage: OpMessage,
+//		})
+//	}
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/nbd-wtf/go-nostr/cip/capability"
+	"github.com/nbd-wtf/go-nostr/cip/cip01"
+	"github.com/nbd-wtf/go-nostr/cip/cip02"
+	"github.com/nbd-wtf/go-nostr/cip/cip03"
+	"github.com/nbd-wtf/go-nostr/cip/cip04"
+	cip05 "github.com/nbd-wtf/go-nostr/cip/cip05"
+	"github.com/nbd-wtf/go-nostr/cip/cip06"
+	"github.com/nbd-wtf/go-nostr/cip/cip07"
+)
+
+// Parser parses a raw event into a typed subspace operation
+type Parser func(evt nostr.Event) (nostr.SubspaceOpEventPtr, error)
+
+// Entry describes a registered kind
+type Entry struct {
+	CIP       string
+	Kind      int
+	Operation string
+	Parser    Parser
+}
+
+var (
+	mu      sync.RWMutex
+	entries = make(map[int]Entry)
+)
+
+func init() {
+	MustRegister("capability", capability.ParseCapabilityEvent, map[int]string{
+		cip.KindSubspaceGrant:  cip.OpGrant,
+		cip.KindSubspaceRevoke: cip.OpRevoke,
+	})
+	MustRegister("governance", cip01.ParseGovernanceEvent, map[int]string{
+		cip.KindGovernancePost:    cip.OpPost,
+		cip.KindGovernancePropose: cip.OpPropose,
+		cip.KindGovernanceVote:    cip.OpVote,
+		cip.KindGovernanceInvite:  cip.OpInvite,
+		cip.KindGovernanceMint:    cip.OpMint,
+	})
+	MustRegister("commongraph", cip02.ParseCommonGraphEvent, map[int]string{
+		cip.KindCommonGraphProject:     cip.OpProject,
+		cip.KindCommonGraphTask:        cip.OpTask,
+		cip.KindCommonGraphEntity:      cip.OpEntity,
+		cip.KindCommonGraphRelation:    cip.OpRelation,
+		cip.KindCommonGraphObservation: cip.OpObservation,
+	})
+	MustRegister("modelgraph", cip03.ParseModelGraphEvent, map[int]string{
+		cip.KindModelgraphModel:        cip.OpModel,
+		cip.KindModelgraphDataset:      cip.OpDataset,
+		cip.KindModelgraphCompute:      cip.OpCompute,
+		cip.KindModelgraphAlgo:         cip.OpAlgo,
+		cip.KindModelgraphValid:        cip.OpValid,
+		cip.KindModelgraphFinetune:     cip.OpFinetune,
+		cip.KindModelgraphConversation: cip.OpConversation,
+		cip.KindModelgraphSession:      cip.OpSession,
+	})
+	MustRegister("keytoken", cip04.ParseKeyTokenEvent, map[int]string{
+		cip.KindKeyIssue:    cip.OpKeyIssue,
+		cip.KindKeyTransfer: cip.OpKeyTransfer,
+		cip.KindKeyBind:     cip.OpKeyBind,
+		cip.KindKeyRetire:   cip.OpKeyRetire,
+	})
+	MustRegister("openresearch", cip05.ParseOpenResearchEvent, map[int]string{
+		cip.KindOpenResearchPaper:      cip.OpPaper,
+		cip.KindOpenResearchAnnotation: cip.OpAnnotation,
+		cip.KindOpenResearchReview:     cip.OpReview,
+		cip.KindOpenResearchAIAnalysis: cip.OpAIAnalysis,
+		cip.KindOpenResearchDiscussion: cip.OpDiscussion,
+		cip.KindOpenResearchReadPaper:  cip.OpReadPaper,
+		cip.KindOpenResearchCoCreate:   cip.OpCoCreate,
+	})
+	MustRegister("social", cip06.ParseSocialEvent, map[int]string{
+		cip.KindSocialLike:     cip.OpLike,
+		cip.KindSocialCollect:  cip.OpCollect,
+		cip.KindSocialShare:    cip.OpShare,
+		cip.KindSocialComment:  cip.OpComment,
+		cip.KindSocialTag:      cip.OpTag,
+		cip.KindSocialFollow:   cip.OpFollow,
+		cip.KindSocialUnfollow: cip.OpUnfollow,
+		cip.KindSocialQuestion: cip.OpQuestion,
+		cip.KindSocialRoom:     cip.OpRoom,
+		cip.KindSocialMessage:  cip.OpMessage,
+	})
+	MustRegister("community", cip07.ParseCommunityEvent, map[int]string{
+		cip.KindCommunityCreate:         cip.OpCommunityCreate,
+		cip.KindCommunityInvite:         cip.OpCommunityInvite,
+		cip.KindCommunityChannelCreate:  cip.OpChannelCreate,
+		cip.KindCommunityChannelMessage: cip.OpChannelMessage,
+	})
+}
+
+// Register registers the kinds of a CIP together with the parser that handles them.
+// The kind/operation pairs are also registered in cip.KeyOpMap. Nothing is registered if one
+// of the kinds or operations is already taken.
+func Register(cipName string, parser Parser, ops map[int]string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for kind := range ops {
+		if existing, exists := entries[kind]; exists {
+			return fmt.Errorf("kind %d is already registered by %s", kind, existing.CIP)
+		}
+	}
+	if err := cip.RegisterOps(ops); err != nil {
+		return err
+	}
+	for kind, op := range ops {
+		entries[kind] = Entry{
+			CIP:       cipName,
+			Kind:      kind,
+			Operation: op,
+			Parser:    parser,
+		}
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error, meant to be called from init functions
+func MustRegister(cipName string, parser Parser, ops map[int]string) {
+	if err := Register(cipName, parser, ops); err != nil {
+		panic(fmt.Sprintf("failed to register %s: %v", cipName, err))
+	}
+}
+
+// Lookup returns the registration of a kind
+func Lookup(kind int) (Entry, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	entry, exists := entries[kind]
+	return entry, exists
+}
+
+// Kinds returns all the registered kinds in ascending order
+func Kinds() []int {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	kinds := make([]int, 0, len(entries))
+	for kind := range entries {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// Parse parses an event with the parser registered for its kind
+func Parse(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
+	entry, exists := Lookup(evt.Kind)
+	if !exists {
+		return nil, fmt.Errorf("no parser registered for kind %d", evt.Kind)
+	}
+	return entry.Parser(evt)
+}
+
+// ParseStrict parses an event like Parse and then validates it, an invalid event is rejected
+// with a *cip.ValidationError listing every offending field
+func ParseStrict(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
+	op, err := Parse(evt)
+	if err != nil {
+		return nil, err
+	}
+	if err := op.Validate(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
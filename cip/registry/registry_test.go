@@ -0,0 +1,196 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/nbd-wtf/go-nostr/cip/cip01"
+	"github.com/nbd-wtf/go-nostr/cip/cip02"
+	"github.com/nbd-wtf/go-nostr/cip/cip03"
+	cip05 "github.com/nbd-wtf/go-nostr/cip/cip05"
+	"github.com/nbd-wtf/go-nostr/cip/cip06"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSID = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+func TestBuiltinKinds(t *testing.T) {
+	for kind, op := range cip.KeyOpMap {
+		if kind == cip.KindSubspaceCreate || kind == cip.KindSubspaceJoin {
+			continue
+		}
+		entry, ok := Lookup(kind)
+		assert.True(t, ok, "kind %d is not registered", kind)
+		assert.Equal(t, op, entry.Operation)
+	}
+	assert.Contains(t, Kinds(), cip.KindGovernanceVote)
+	assert.NotContains(t, Kinds(), cip.KindSubspaceCreate)
+}
+
+func TestParse(t *testing.T) {
+	vote, _ := cip01.NewVoteEvent(testSID)
+	vote.SetVote("prop_001", "yes")
+	parsed, err := Parse(vote.Event)
+	assert.NoError(t, err)
+	assert.IsType(t, &cip01.VoteEvent{}, parsed)
+	assert.Equal(t, "prop_001", parsed.(*cip01.VoteEvent).ProposalID)
+
+	relation, _ := cip02.NewRelationEvent(testSID)
+	relation.SetRelationInfo("a", "b", "knows", "", 0.5, "")
+	parsed, err = Parse(relation.Event)
+	assert.NoError(t, err)
+	assert.IsType(t, &cip02.RelationEvent{}, parsed)
+
+	like, _ := cip06.NewLikeEvent(testSID)
+	parsed, err = Parse(like.Event)
+	assert.NoError(t, err)
+	assert.Equal(t, cip.OpLike, parsed.GetOperation())
+
+	_, err = Parse(nostr.Event{Kind: 1})
+	assert.Error(t, err)
+}
+
+func TestParseStrict(t *testing.T) {
+	vote, _ := cip01.NewVoteEvent(testSID)
+	vote.SetVote("prop_001", "yes")
+	_, err := ParseStrict(vote.Event)
+	assert.NoError(t, err)
+
+	// vote without proposal
+	vote, _ = cip01.NewVoteEvent(testSID)
+	vote.SetVote("", "maybe")
+	_, err = ParseStrict(vote.Event)
+	var verr *cip.ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, cip.KindGovernanceVote, verr.Kind)
+	assert.True(t, verr.Has("proposal_id"))
+	assert.True(t, verr.Has("vote"))
+	assert.ErrorIs(t, err, cip01.ErrInvalidVote)
+
+	// the lenient parser accepts a weight with trailing garbage
+	relation, _ := cip02.NewRelationEvent(testSID)
+	relation.Tags = append(relation.Tags, nostr.Tag{"from", "a"}, nostr.Tag{"to", "b"},
+		nostr.Tag{"relation_type", "knows"}, nostr.Tag{"weight", "0.5kg"})
+	_, err = Parse(relation.Event)
+	assert.NoError(t, err)
+	_, err = ParseStrict(relation.Event)
+	assert.True(t, errors.As(err, &verr))
+	assert.True(t, verr.Has("weight"))
+
+	paper, _ := cip05.NewPaperEvent(testSID)
+	paper.SetPaperInfo("10.1234/example.2023", "pdf", nil, nil, "2023", "")
+	_, err = ParseStrict(paper.Event)
+	assert.NoError(t, err)
+
+	paper, _ = cip05.NewPaperEvent(testSID)
+	paper.SetPaperInfo("doi:example", "pdf", nil, nil, "23", "")
+	_, err = ParseStrict(paper.Event)
+	assert.True(t, errors.As(err, &verr))
+	assert.True(t, verr.Has("doi"))
+	assert.True(t, verr.Has("year"))
+
+	// mixed case addresses must have a valid checksum
+	invite, _ := cip01.NewInviteEvent(testSID)
+	assert.NoError(t, invite.SetInviter("0x52908400098527886E0F7030069857D2E4169EE7", ""))
+	_, err = ParseStrict(invite.Event)
+	assert.NoError(t, err)
+	invite, _ = cip01.NewInviteEvent(testSID)
+	assert.Error(t, invite.SetInviter("0x52908400098527886E0F7030069857D2E4169Ee7", ""))
+	invite.Tags = append(invite.Tags, nostr.Tag{"inviter_addr", "0x52908400098527886E0F7030069857D2E4169Ee7"})
+	_, err = ParseStrict(invite.Event)
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, []string{"inviter_addr"}, fieldNames(verr))
+
+	// common fields are checked on every cip
+	dataset, _ := cip03.NewDatasetEvent("0x1234")
+	dataset.SetDatasetInfo("proj_001", "task_001", "training", "jsonl", nil)
+	_, err = ParseStrict(dataset.Event)
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, []string{"sid"}, fieldNames(verr))
+}
+
+func TestParseIdentifiers(t *testing.T) {
+	// append-only operations never share a d tag
+	first, _ := cip01.NewPostEvent(testSID)
+	second, _ := cip01.NewPostEvent(testSID)
+	assert.NotEqual(t, first.Tags.GetD(), second.Tags.GetD())
+	assert.Empty(t, first.ObjectID())
+
+	// replaceable operations are addressed by their object
+	project, _ := cip02.NewProjectEvent(testSID)
+	project.SetProjectInfo("proj_001", "Project", "", nil, "active")
+	assert.Equal(t, testSID+":proj_001", project.Tags.GetD())
+	parsed, err := ParseStrict(project.Event)
+	assert.NoError(t, err)
+	assert.Equal(t, "proj_001", parsed.(*cip02.ProjectEvent).ObjectID())
+
+	// events with the legacy d tag are still understood
+	legacy, _ := cip02.NewProjectEvent(testSID)
+	legacy.SetProjectInfo("proj_001", "Project", "", nil, "active")
+	legacy.Tags[0] = nostr.Tag{"d", cip.LegacyOpIdentifier}
+	parsed, err = ParseStrict(legacy.Event)
+	assert.NoError(t, err)
+	assert.Equal(t, "proj_001", parsed.(*cip02.ProjectEvent).ProjectID)
+	assert.Empty(t, parsed.(*cip02.ProjectEvent).ObjectID())
+
+	// the d tag must agree with the object and the subspace
+	var verr *cip.ValidationError
+	project.Tags[0] = nostr.Tag{"d", cip.ObjectIdentifier(testSID, "proj_002")}
+	_, err = ParseStrict(project.Event)
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, []string{"d"}, fieldNames(verr))
+
+	otherSID := "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
+	project.Tags[0] = nostr.Tag{"d", cip.ObjectIdentifier(otherSID, "proj_001")}
+	_, err = ParseStrict(project.Event)
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, []string{"d"}, fieldNames(verr))
+}
+
+func fieldNames(err *cip.ValidationError) []string {
+	names := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		names[i] = field.Field
+	}
+	return names
+}
+
+type customEvent struct {
+	*nostr.SubspaceOpEvent
+}
+
+func TestRegisterCustomCIP(t *testing.T) {
+	parser := func(evt nostr.Event) (nostr.SubspaceOpEventPtr, error) {
+		return &customEvent{&nostr.SubspaceOpEvent{Event: evt, Operation: "custom"}}, nil
+	}
+
+	assert.NoError(t, Register("custom", parser, map[int]string{39990: "custom"}))
+	op, ok := cip.GetOpFromKind(39990)
+	assert.True(t, ok)
+	assert.Equal(t, "custom", op)
+
+	evt, err := nostr.NewSubspaceOpEvent(testSID, 39990)
+	assert.NoError(t, err)
+	parsed, err := Parse(evt.Event)
+	assert.NoError(t, err)
+	assert.IsType(t, &customEvent{}, parsed)
+
+	// kinds and operations can't be taken over
+	assert.Error(t, Register("other", parser, map[int]string{39990: "custom"}))
+	assert.Error(t, Register("other", parser, map[int]string{cip.KindSocialLike: "like2"}))
+	assert.Error(t, Register("other", parser, map[int]string{39991: cip.OpLike}))
+	_, ok = Lookup(39991)
+	assert.False(t, ok)
+
+	// a taken operation doesn't leave the other kinds half registered
+	assert.Error(t, Register("other", parser, map[int]string{39992: "other", 39993: cip.OpLike}))
+	_, ok = Lookup(39992)
+	assert.False(t, ok)
+	_, ok = cip.GetOpFromKind(39992)
+	assert.False(t, ok)
+	assert.NoError(t, Register("other", parser, map[int]string{39992: "other"}))
+
+	assert.Panics(t, func() { MustRegister("other", parser, map[int]string{39990: "custom"}) })
+}
@@ -0,0 +1,460 @@
+package cip
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Well known rule variables, their values are provided by the caller when evaluating rules
+const (
+	RuleVarEnergy    = "energy"     // energy of the user
+	RuleVarBalance   = "balance"    // subspace token balance of the user
+	RuleVarMemberAge = "member_age" // seconds since the user joined the subspace
+)
+
+// Known rule settings, they hold numbers
+const (
+	RuleSettingQuorum       = "quorum"        // share of the members that must vote on a proposal
+	RuleSettingThreshold    = "threshold"     // share of the votes a proposal needs to pass
+	RuleSettingVotingPeriod = "voting_period" // seconds a proposal is open for votes
+)
+
+// RuleSettings are the names of the known rule settings
+var RuleSettings = []string{RuleSettingQuorum, RuleSettingThreshold, RuleSettingVotingPeriod}
+
+// RulesEnv holds the values of the variables referenced by a rules expression
+type RulesEnv map[string]float64
+
+// Rules represents a parsed subspace rules string.
+//
+// A rules string is a list of clauses separated by ";". Clauses of the form "name=value" are settings
+// (e.g. "quorum=0.6") and name must be one of RuleSettings, every other clause is a boolean
+// expression and all of them must hold:
+//
+//	energy>10000 && (balance>=50 || member_age>86400); quorum=0.6
+//
+// Expressions support the comparisons > >= < <= == !=, the boolean operators && || ! (or and, or, not),
+// parentheses, numbers, true/false and variables. Equality is ==, a single = always starts a setting.
+type Rules struct {
+	Source   string
+	Settings map[string]string
+	exprs    []ruleNode
+}
+
+// ParseRules parses a rules string, an empty string yields rules that always hold
+func ParseRules(rules string) (*Rules, error) {
+	r := &Rules{
+		Source:   rules,
+		Settings: make(map[string]string),
+	}
+
+	for _, clause := range strings.Split(rules, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		if name, value, ok := parseRuleSetting(clause); ok {
+			if !slices.Contains(RuleSettings, name) {
+				return nil, fmt.Errorf("unknown rule setting %q, comparisons are written with ==", name)
+			}
+			if _, err := ParseNumber(value); err != nil {
+				return nil, fmt.Errorf("invalid rule setting %s: %w", name, err)
+			}
+			if _, exists := r.Settings[name]; exists {
+				return nil, fmt.Errorf("duplicate rule setting: %s", name)
+			}
+			r.Settings[name] = value
+			continue
+		}
+
+		p := &ruleParser{src: clause}
+		if err := p.tokenize(); err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", clause, err)
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", clause, err)
+		}
+		if p.pos < len(p.tokens) {
+			return nil, fmt.Errorf("invalid rule %q: unexpected %q", clause, p.tokens[p.pos].text)
+		}
+		if !node.boolean() {
+			return nil, fmt.Errorf("invalid rule %q: expression is not a condition", clause)
+		}
+		r.exprs = append(r.exprs, node)
+	}
+
+	return r, nil
+}
+
+// ValidateRules checks that a rules string is well formed
+func ValidateRules(rules string) error {
+	_, err := ParseRules(rules)
+	return err
+}
+
+// EvaluateRules parses and evaluates a rules string against the given environment
+func EvaluateRules(rules string, env RulesEnv) (bool, error) {
+	r, err := ParseRules(rules)
+	if err != nil {
+		return false, err
+	}
+	return r.Evaluate(env)
+}
+
+// Evaluate checks if all the rule expressions hold for the given environment.
+// It fails if an expression references a variable missing from the environment.
+func (r *Rules) Evaluate(env RulesEnv) (bool, error) {
+	for _, expr := range r.exprs {
+		v, err := expr.eval(env)
+		if err != nil {
+			return false, err
+		}
+		if v == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Variables returns the sorted names of the variables referenced by the rule expressions
+func (r *Rules) Variables() []string {
+	var vars []string
+	for _, expr := range r.exprs {
+		expr.collect(&vars)
+	}
+	slices.Sort(vars)
+	return slices.Compact(vars)
+}
+
+// parseRuleSetting checks if a clause is a "name=value" setting
+func parseRuleSetting(clause string) (string, string, bool) {
+	idx := strings.IndexByte(clause, '=')
+	if idx <= 0 || idx == len(clause)-1 || clause[idx+1] == '=' {
+		return "", "", false
+	}
+	name := strings.TrimSpace(clause[:idx])
+	for i, c := range name {
+		if !isRuleIdentChar(c, i == 0) {
+			return "", "", false
+		}
+	}
+	return name, strings.TrimSpace(clause[idx+1:]), true
+}
+
+func isRuleIdentChar(c rune, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && ((c >= '0' && c <= '9') || c == '.')
+}
+
+// ruleNode is a node of a parsed rule expression, booleans evaluate to 1 or 0
+type ruleNode interface {
+	eval(env RulesEnv) (float64, error)
+	boolean() bool
+	collect(vars *[]string)
+}
+
+type ruleNumber float64
+
+func (n ruleNumber) eval(RulesEnv) (float64, error) { return float64(n), nil }
+func (n ruleNumber) boolean() bool                  { return false }
+func (n ruleNumber) collect(*[]string)              {}
+
+type ruleBool bool
+
+func (b ruleBool) eval(RulesEnv) (float64, error) { return boolToFloat(bool(b)), nil }
+func (b ruleBool) boolean() bool                  { return true }
+func (b ruleBool) collect(*[]string)              {}
+
+type ruleVar string
+
+func (v ruleVar) eval(env RulesEnv) (float64, error) {
+	value, ok := env[string(v)]
+	if !ok {
+		return 0, fmt.Errorf("missing rule variable: %s", string(v))
+	}
+	return value, nil
+}
+func (v ruleVar) boolean() bool          { return false }
+func (v ruleVar) collect(vars *[]string) { *vars = append(*vars, string(v)) }
+
+type ruleNot struct{ operand ruleNode }
+
+func (n ruleNot) eval(env RulesEnv) (float64, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	return boolToFloat(v == 0), nil
+}
+func (n ruleNot) boolean() bool          { return true }
+func (n ruleNot) collect(vars *[]string) { n.operand.collect(vars) }
+
+type ruleBinary struct {
+	op          string
+	left, right ruleNode
+}
+
+func (b ruleBinary) eval(env RulesEnv) (float64, error) {
+	l, err := b.left.eval(env)
+	if err != nil {
+		return 0, err
+	}
+
+	// short-circuit boolean operators
+	switch b.op {
+	case "&&":
+		if l == 0 {
+			return 0, nil
+		}
+	case "||":
+		if l != 0 {
+			return 1, nil
+		}
+	}
+
+	r, err := b.right.eval(env)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case "&&", "||":
+		return boolToFloat(r != 0), nil
+	case ">":
+		return boolToFloat(l > r), nil
+	case ">=":
+		return boolToFloat(l >= r), nil
+	case "<":
+		return boolToFloat(l < r), nil
+	case "<=":
+		return boolToFloat(l <= r), nil
+	case "==":
+		return boolToFloat(l == r), nil
+	case "!=":
+		return boolToFloat(l != r), nil
+	default:
+		return 0, fmt.Errorf("unknown rule operator: %s", b.op)
+	}
+}
+func (b ruleBinary) boolean() bool { return true }
+func (b ruleBinary) collect(vars *[]string) {
+	b.left.collect(vars)
+	b.right.collect(vars)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type ruleTokenKind int
+
+const (
+	ruleTokenNumber ruleTokenKind = iota
+	ruleTokenIdent
+	ruleTokenOp
+	ruleTokenLParen
+	ruleTokenRParen
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+}
+
+type ruleParser struct {
+	src    string
+	tokens []ruleToken
+	pos    int
+}
+
+func (p *ruleParser) tokenize() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			p.tokens = append(p.tokens, ruleToken{ruleTokenLParen, "("})
+			i++
+		case c == ')':
+			p.tokens = append(p.tokens, ruleToken{ruleTokenRParen, ")"})
+			i++
+		case (c >= '0' && c <= '9') || c == '.':
+			j := i
+			for j < len(s) && ((s[j] >= '0' && s[j] <= '9') || s[j] == '.') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(s[i:j], 64); err != nil {
+				return fmt.Errorf("invalid number %q", s[i:j])
+			}
+			p.tokens = append(p.tokens, ruleToken{ruleTokenNumber, s[i:j]})
+			i = j
+		case isRuleIdentChar(rune(c), true):
+			j := i
+			for j < len(s) && isRuleIdentChar(rune(s[j]), false) {
+				j++
+			}
+			word := s[i:j]
+			switch strings.ToLower(word) {
+			case "and":
+				p.tokens = append(p.tokens, ruleToken{ruleTokenOp, "&&"})
+			case "or":
+				p.tokens = append(p.tokens, ruleToken{ruleTokenOp, "||"})
+			case "not":
+				p.tokens = append(p.tokens, ruleToken{ruleTokenOp, "!"})
+			default:
+				p.tokens = append(p.tokens, ruleToken{ruleTokenIdent, word})
+			}
+			i = j
+		default:
+			var op string
+			for _, candidate := range []string{">=", "<=", "==", "!=", "&&", "||", ">", "<", "!"} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("unexpected character %q", c)
+			}
+			p.tokens = append(p.tokens, ruleToken{ruleTokenOp, op})
+			i += len(op)
+		}
+	}
+	if len(p.tokens) == 0 {
+		return fmt.Errorf("empty expression")
+	}
+	return nil
+}
+
+func (p *ruleParser) peekOp(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != ruleTokenOp {
+		return "", false
+	}
+	if slices.Contains(ops, p.tokens[p.pos].text) {
+		return p.tokens[p.pos].text, true
+	}
+	return "", false
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.peekOp("||"); !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if !left.boolean() || !right.boolean() {
+			return nil, fmt.Errorf("operands of || must be conditions")
+		}
+		left = ruleBinary{op: "||", left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.peekOp("&&"); !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if !left.boolean() || !right.boolean() {
+			return nil, fmt.Errorf("operands of && must be conditions")
+		}
+		left = ruleBinary{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseNot() (ruleNode, error) {
+	if _, ok := p.peekOp("!"); ok {
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if !operand.boolean() {
+			return nil, fmt.Errorf("operand of ! is not a condition")
+		}
+		return ruleNot{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.peekOp(">", ">=", "<", "<=", "==", "!=")
+	if !ok {
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if left.boolean() || right.boolean() {
+		if op != "==" && op != "!=" || left.boolean() != right.boolean() {
+			return nil, fmt.Errorf("cannot compare a condition with %s", op)
+		}
+	}
+	return ruleBinary{op: op, left: left, right: right}, nil
+}
+
+func (p *ruleParser) parsePrimary() (ruleNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case ruleTokenNumber:
+		v, _ := strconv.ParseFloat(tok.text, 64)
+		return ruleNumber(v), nil
+	case ruleTokenIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return ruleBool(true), nil
+		case "false":
+			return ruleBool(false), nil
+		}
+		return ruleVar(tok.text), nil
+	case ruleTokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != ruleTokenRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+}
@@ -0,0 +1,77 @@
+package cip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateRules(t *testing.T) {
+	env := RulesEnv{
+		RuleVarEnergy:    12000,
+		RuleVarBalance:   40,
+		RuleVarMemberAge: 3600,
+	}
+
+	cases := []struct {
+		rules    string
+		expected bool
+	}{
+		{"", true},
+		{"energy>10000", true},
+		{"energy>=12000", true},
+		{"energy<12000", false},
+		{"energy == 12000", true},
+		{"energy != 12000", false},
+		{"energy>10000 && balance>=50", false},
+		{"energy>10000 && (balance>=50 || member_age>60)", true},
+		{"energy>10000 and not (balance>=50 or member_age>60)", false},
+		{"!(energy<100)", true},
+		{"true", true},
+		{"false || balance>39.5", true},
+		{"energy>10000; balance>100", false},
+		{"energy>10000; quorum=0.6", true},
+		{"0.5 < 1", true},
+	}
+	for _, c := range cases {
+		ok, err := EvaluateRules(c.rules, env)
+		assert.NoError(t, err, c.rules)
+		assert.Equal(t, c.expected, ok, c.rules)
+	}
+
+	_, err := EvaluateRules("reputation>10", env)
+	assert.Error(t, err)
+
+	// short-circuit doesn't need the missing variable
+	ok, err := EvaluateRules("energy>1 || reputation>10", env)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestParseRules(t *testing.T) {
+	r, err := ParseRules("energy>10000 && (balance>=50 || member_age>86400); quorum=0.6; threshold = 0.66")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"balance", "energy", "member_age"}, r.Variables())
+	assert.Equal(t, map[string]string{"quorum": "0.6", "threshold": "0.66"}, r.Settings)
+
+	invalid := []string{
+		"energy>",             // missing operand
+		"energy>>10",          // double operator
+		"(energy>10",          // unclosed parenthesis
+		"energy>10)",          // dangling parenthesis
+		"energy",              // not a condition
+		"energy && balance",   // operands are not conditions
+		"!energy",             // not a condition
+		"energy>10 > 5",       // chained comparison
+		"energy>1.2.3",        // invalid number
+		"energy # 10",         // invalid character
+		"quorum=1; quorum=2",  // duplicate setting
+		"energy=10000",        // unknown setting, meant ==
+		"min_stake=5",         // unknown setting
+		"quorum=most",         // not a number
+		"energy>10 balance>1", // missing operator
+	}
+	for _, rules := range invalid {
+		assert.Error(t, ValidateRules(rules), rules)
+	}
+}
@@ -0,0 +1,320 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/nbd-wtf/go-nostr/cip/cip01"
+	"github.com/nbd-wtf/go-nostr/cip/registry"
+)
+
+var (
+	ErrNotCreated     = errors.New("state: subspace has not been created yet")
+	ErrAlreadyCreated = errors.New("state: subspace was already created")
+	ErrWrongSubspace  = errors.New("state: event belongs to another subspace")
+	ErrDuplicateEvent = errors.New("state: event was already applied")
+	ErrOpNotAllowed   = errors.New("state: operation is not allowed in this subspace")
+	ErrNotMember      = errors.New("state: author is not a member of the subspace")
+	ErrAlreadyMember  = errors.New("state: address is already a member of the subspace")
+	ErrUnauthorized   = errors.New("state: author is not allowed to perform this operation")
+	ErrInvalidInvite  = errors.New("state: invalid invite")
+	ErrRulesNotMet    = errors.New("state: subspace rules are not satisfied")
+	ErrAlreadyMinted  = errors.New("state: subspace token was already minted")
+)
+
+// TransitionError is returned when an event is rejected by the state machine
+type TransitionError struct {
+	EventID string
+	Kind    int
+	Err     error
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("event %s (kind %d) rejected: %v", e.EventID, e.Kind, e.Err)
+}
+
+func (e *TransitionError) Unwrap() error { return e.Err }
+
+// Member represents a member of a subspace
+type Member struct {
+	PubKey    string
+	JoinedAt  nostr.Timestamp
+	InvitedBy string // empty if the member joined on its own
+}
+
+// Subspace is the materialized state of a subspace, built by applying its events in order.
+type Subspace struct {
+	ID        string
+	Name      string
+	Creator   string
+	Ops       cip.OpsSpec
+	Rules     string
+	Members   map[string]*Member
+	Proposals *cip01.ProposalTracker // open proposals
+	Ledger    *cip01.Ledger          // token balances, nil until the token is minted
+	Clock     *cip.VLC               // merge of the clocks of all applied operations
+
+	// Env, when set, provides the rule variables of a user (energy, balance...).
+	// Joins and invites are then checked against the subspace and invite rules.
+	Env func(pubkey string) cip.RulesEnv
+
+	seen map[string]struct{}
+}
+
+// NewSubspace creates an empty subspace state, waiting for its create event
+func NewSubspace() *Subspace {
+	return &Subspace{
+		Ops:       make(cip.OpsSpec),
+		Members:   make(map[string]*Member),
+		Proposals: cip01.NewProposalTracker(cip01.DefaultVotingPolicy),
+		Clock:     cip.NewVLC(),
+		seen:      make(map[string]struct{}),
+	}
+}
+
+// IsMember checks if the given pubkey is a member of the subspace
+func (s *Subspace) IsMember(pubkey string) bool {
+	_, ok := s.Members[pubkey]
+	return ok
+}
+
+// Allows checks if the given kind is one of the operations enabled in the subspace
+func (s *Subspace) Allows(kind int) bool {
+	return s.Ops.Allows(kind)
+}
+
+// Replay applies all the events in order and returns the errors of the rejected ones
+func (s *Subspace) Replay(events []nostr.Event) []error {
+	var errs []error
+	for _, evt := range events {
+		if err := s.Apply(evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Apply applies a single event to the subspace state.
+// Rejected events leave the state untouched and return a *TransitionError.
+func (s *Subspace) Apply(evt nostr.Event) error {
+	if _, ok := s.seen[evt.ID]; ok && evt.ID != "" {
+		return reject(evt, ErrDuplicateEvent)
+	}
+
+	var err error
+	switch evt.Kind {
+	case cip.KindSubspaceCreate:
+		err = s.applyCreate(evt)
+	case cip.KindSubspaceJoin:
+		err = s.applyJoin(evt)
+	default:
+		err = s.applyOp(evt)
+	}
+	if err != nil {
+		return reject(evt, err)
+	}
+
+	if evt.ID != "" {
+		s.seen[evt.ID] = struct{}{}
+	}
+	return nil
+}
+
+func reject(evt nostr.Event, err error) error {
+	return &TransitionError{EventID: evt.ID, Kind: evt.Kind, Err: err}
+}
+
+func (s *Subspace) applyCreate(evt nostr.Event) error {
+	if s.ID != "" {
+		return ErrAlreadyCreated
+	}
+	create, err := nostr.ParseSubspaceCreateEvent(evt)
+	if err != nil {
+		return err
+	}
+	ops, err := create.OpsSpec()
+	if err != nil {
+		return err
+	}
+	policy, err := cip01.ParseVotingPolicy(create.Rules)
+	if err != nil {
+		return err
+	}
+
+	s.ID = create.SubspaceID
+	s.Name = create.SubspaceName
+	s.Creator = evt.PubKey
+	s.Ops = ops
+	s.Rules = create.Rules
+	s.Proposals.Policy = policy
+	s.Members[evt.PubKey] = &Member{PubKey: evt.PubKey, JoinedAt: evt.CreatedAt}
+	return nil
+}
+
+func (s *Subspace) applyJoin(evt nostr.Event) error {
+	if s.ID == "" {
+		return ErrNotCreated
+	}
+	join, err := nostr.ParseSubspaceJoinEvent(evt)
+	if err != nil {
+		return err
+	}
+	if join.SubspaceID != s.ID {
+		return ErrWrongSubspace
+	}
+	if s.IsMember(evt.PubKey) {
+		return ErrAlreadyMember
+	}
+	if err := s.checkRules(s.Rules, evt.PubKey); err != nil {
+		return err
+	}
+
+	s.Members[evt.PubKey] = &Member{PubKey: evt.PubKey, JoinedAt: evt.CreatedAt}
+	return nil
+}
+
+func (s *Subspace) applyOp(evt nostr.Event) error {
+	if s.ID == "" {
+		return ErrNotCreated
+	}
+	if sid := evt.Tags.Find("sid"); sid == nil || sid[1] != s.ID {
+		return ErrWrongSubspace
+	}
+	if !s.Allows(evt.Kind) {
+		return fmt.Errorf("%w: kind %d", ErrOpNotAllowed, evt.Kind)
+	}
+	if !s.IsMember(evt.PubKey) {
+		return ErrNotMember
+	}
+
+	var clock *cip.VLC
+	var minted bool
+	switch evt.Kind {
+	case cip.KindGovernancePost, cip.KindGovernancePropose, cip.KindGovernanceVote,
+		cip.KindGovernanceInvite, cip.KindGovernanceMint:
+		op, err := registry.ParseStrict(evt)
+		if err != nil {
+			return err
+		}
+		if err := s.applyGovernance(evt, op); err != nil {
+			return err
+		}
+		clock = op.GetClock()
+		minted = evt.Kind == cip.KindGovernanceMint
+	default:
+		// business operations only need to be allowed, well formed and authored by a member
+		op, err := registry.ParseStrict(evt)
+		if err != nil {
+			return err
+		}
+		clock = op.GetClock()
+	}
+
+	s.Clock.Merge(clock)
+	if s.Ledger != nil && !minted {
+		s.Ledger.Apply(evt)
+	}
+	return nil
+}
+
+func (s *Subspace) applyGovernance(evt nostr.Event, op nostr.SubspaceOpEventPtr) error {
+	switch e := op.(type) {
+	case *cip01.InviteEvent:
+		if e.InviterAddr == "" {
+			return fmt.Errorf("%w: missing invitee address", ErrInvalidInvite)
+		}
+		if s.IsMember(e.InviterAddr) {
+			return ErrAlreadyMember
+		}
+		if err := s.checkRules(e.Rules, e.InviterAddr); err != nil {
+			return err
+		}
+		s.Members[e.InviterAddr] = &Member{PubKey: e.InviterAddr, JoinedAt: evt.CreatedAt, InvitedBy: evt.PubKey}
+
+	case *cip01.ProposeEvent:
+		if e.Rules != "" {
+			if _, err := cip01.ParseVotingPolicy(e.Rules); err != nil {
+				return err
+			}
+		}
+		return s.Proposals.AddProposal(e)
+
+	case *cip01.VoteEvent:
+		return s.Proposals.AddVote(e)
+
+	case *cip01.MintEvent:
+		if s.Ledger != nil {
+			return ErrAlreadyMinted
+		}
+		if evt.PubKey != s.Creator {
+			return ErrUnauthorized
+		}
+		ledger, err := cip01.NewLedger(e)
+		if err != nil {
+			return err
+		}
+		s.Ledger = ledger
+	}
+
+	return nil
+}
+
+// CanJoin checks if the given user satisfies the subspace rules.
+// Without an Env every user may join.
+func (s *Subspace) CanJoin(pubkey string) (bool, error) {
+	if s.Env == nil {
+		return true, nil
+	}
+	return cip.EvaluateRules(s.Rules, s.Env(pubkey))
+}
+
+func (s *Subspace) checkRules(rules, pubkey string) error {
+	if s.Env == nil || rules == "" {
+		return nil
+	}
+	ok, err := cip.EvaluateRules(rules, s.Env(pubkey))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRulesNotMet, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRulesNotMet, rules)
+	}
+	return nil
+}
+
+// TallyProposal tallies the votes on a proposal of the members who had joined by the time it
+// was made, so that later members can't swing it.
+// Once the proposal is decided it is closed, and if it passed its rules become the subspace rules.
+func (s *Subspace) TallyProposal(proposalID string, now nostr.Timestamp) (cip01.ProposalResult, error) {
+	proposal, ok := s.Proposals.Proposals[proposalID]
+	if !ok {
+		return cip01.ProposalResult{}, fmt.Errorf("%w: %s", cip01.ErrUnknownProposal, proposalID)
+	}
+	members := make([]string, 0, len(s.Members))
+	for pubkey, member := range s.Members {
+		if member.JoinedAt <= proposal.CreatedAt {
+			members = append(members, pubkey)
+		}
+	}
+
+	result, err := s.Proposals.Tally(proposalID, members, now)
+	if err != nil {
+		return result, err
+	}
+	if result.Status == cip01.ProposalOpen {
+		return result, nil
+	}
+
+	s.Proposals.Remove(proposalID)
+	if result.Status == cip01.ProposalPassed && result.Rules != "" {
+		policy, err := cip01.ParseVotingPolicy(result.Rules)
+		if err != nil {
+			return result, err
+		}
+		s.Rules = result.Rules
+		s.Proposals.Policy = policy
+	}
+	return result, nil
+}
@@ -0,0 +1,205 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/cip"
+	"github.com/nbd-wtf/go-nostr/cip/cip01"
+	"github.com/nbd-wtf/go-nostr/cip/cip02"
+	"github.com/nbd-wtf/go-nostr/cip/cip03"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	alice = "1111111111111111111111111111111111111111"
+	bob   = "2222222222222222222222222222222222222222"
+	carol = "3333333333333333333333333333333333333333"
+)
+
+func withAuthor(evt nostr.Event, pubkey, id string) nostr.Event {
+	evt.PubKey = pubkey
+	evt.ID = id
+	return evt
+}
+
+func newTestSubspace(t *testing.T) (*Subspace, *nostr.SubspaceCreateEvent) {
+	create, err := nostr.NewSubspaceCreateEvent("test", cip.DefaultSubspaceOps+","+cip.ModelGraphSubspaceOps, "energy>1000", "Test Subspace", "")
+	assert.NoError(t, err)
+	s := NewSubspace()
+	assert.NoError(t, s.Apply(withAuthor(create.Event, alice, "create")))
+	return s, create
+}
+
+func TestSubspaceCreateAndJoin(t *testing.T) {
+	s, create := newTestSubspace(t)
+	assert.Equal(t, create.SubspaceID, s.ID)
+	assert.Equal(t, alice, s.Creator)
+	assert.Equal(t, "energy>1000", s.Rules)
+	assert.Equal(t, cip.KindGovernancePost, s.Ops[cip.OpPost])
+	assert.True(t, s.IsMember(alice))
+
+	// create can only happen once
+	err := s.Apply(withAuthor(create.Event, alice, "create2"))
+	assert.ErrorIs(t, err, ErrAlreadyCreated)
+
+	join := nostr.NewSubspaceJoinEvent(create.SubspaceID)
+	assert.NoError(t, s.Apply(withAuthor(join.Event, bob, "join")))
+	assert.True(t, s.IsMember(bob))
+
+	// same event twice
+	assert.ErrorIs(t, s.Apply(withAuthor(join.Event, bob, "join")), ErrDuplicateEvent)
+	// same member twice
+	assert.ErrorIs(t, s.Apply(withAuthor(join.Event, bob, "join2")), ErrAlreadyMember)
+
+	other := nostr.NewSubspaceJoinEvent(cip.CalculateSubspaceID("other", cip.DefaultSubspaceOps, ""))
+	assert.ErrorIs(t, s.Apply(withAuthor(other.Event, carol, "join3")), ErrWrongSubspace)
+}
+
+func TestSubspaceRequiresCreate(t *testing.T) {
+	s := NewSubspace()
+	join := nostr.NewSubspaceJoinEvent("0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef")
+	err := s.Apply(withAuthor(join.Event, bob, "join"))
+
+	var terr *TransitionError
+	assert.True(t, errors.As(err, &terr))
+	assert.Equal(t, "join", terr.EventID)
+	assert.Equal(t, cip.KindSubspaceJoin, terr.Kind)
+	assert.ErrorIs(t, err, ErrNotCreated)
+}
+
+func TestSubspaceGovernance(t *testing.T) {
+	s, create := newTestSubspace(t)
+	sid := create.SubspaceID
+
+	invite, _ := cip01.NewInviteEvent(sid)
+	invite.SetInviter(bob, "")
+	assert.NoError(t, s.Apply(withAuthor(invite.Event, alice, "invite")))
+	assert.Equal(t, alice, s.Members[bob].InvitedBy)
+
+	// non-members can't operate
+	post, _ := cip01.NewPostEvent(sid)
+	assert.ErrorIs(t, s.Apply(withAuthor(post.Event, carol, "post")), ErrNotMember)
+
+	propose, _ := cip01.NewProposeEvent(sid)
+	propose.SetProposal("prop_001", "energy>2000")
+	assert.NoError(t, s.Apply(withAuthor(propose.Event, bob, "propose")))
+	assert.ErrorIs(t, s.Apply(withAuthor(propose.Event, bob, "propose2")), cip01.ErrDuplicateProposal)
+
+	vote, _ := cip01.NewVoteEvent(sid)
+	vote.SetVote("prop_001", "yes")
+	assert.NoError(t, s.Apply(withAuthor(vote.Event, alice, "vote")))
+	assert.Equal(t, "yes", s.Proposals.Proposals["prop_001"].Votes[alice].Vote)
+
+	unknown, _ := cip01.NewVoteEvent(sid)
+	unknown.SetVote("prop_404", "yes")
+	assert.ErrorIs(t, s.Apply(withAuthor(unknown.Event, alice, "vote2")), cip01.ErrUnknownProposal)
+
+	invalid, _ := cip01.NewVoteEvent(sid)
+	invalid.SetVote("prop_001", "maybe")
+	assert.ErrorIs(t, s.Apply(withAuthor(invalid.Event, bob, "vote3")), cip01.ErrInvalidVote)
+
+	result, err := s.TallyProposal("prop_001", propose.CreatedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, cip01.ProposalPassed, result.Status)
+	assert.Equal(t, "energy>2000", s.Rules)
+	assert.Empty(t, s.Proposals.Proposals)
+
+	mint, _ := cip01.NewMintEvent(sid)
+	mint.SetTokenInfo("DesciToken", "DST", "18", "100", "30300:2,30301:2")
+	assert.ErrorIs(t, s.Apply(withAuthor(mint.Event, bob, "mint")), ErrUnauthorized)
+	assert.NoError(t, s.Apply(withAuthor(mint.Event, alice, "mint2")))
+	assert.Equal(t, 18, s.Ledger.Decimals)
+	assert.Equal(t, "100", s.Ledger.Format(s.Ledger.BalanceOf(alice)))
+	assert.ErrorIs(t, s.Apply(withAuthor(mint.Event, alice, "mint3")), ErrAlreadyMinted)
+
+	post2, _ := cip01.NewPostEvent(sid)
+	post2.Content = "hello"
+	assert.NoError(t, s.Apply(withAuthor(post2.Event, bob, "post2")))
+	assert.Equal(t, "2", s.Ledger.Format(s.Ledger.BalanceOf(bob)))
+}
+
+func TestSubspaceLateMembersDontVote(t *testing.T) {
+	s, create := newTestSubspace(t)
+	sid := create.SubspaceID
+
+	propose, _ := cip01.NewProposeEvent(sid)
+	propose.SetProposal("prop_001", "energy>2000")
+	propose.CreatedAt = create.CreatedAt + 10
+	assert.NoError(t, s.Apply(withAuthor(propose.Event, alice, "propose")))
+
+	// bob and carol join once the proposal is made and vote it down
+	for i, member := range []string{bob, carol} {
+		invite, _ := cip01.NewInviteEvent(sid)
+		invite.SetInviter(member, "")
+		invite.CreatedAt = propose.CreatedAt + 10
+		assert.NoError(t, s.Apply(withAuthor(invite.Event, alice, fmt.Sprintf("invite%d", i))))
+
+		vote, _ := cip01.NewVoteEvent(sid)
+		vote.SetVote("prop_001", "no")
+		vote.CreatedAt = propose.CreatedAt + 20
+		assert.NoError(t, s.Apply(withAuthor(vote.Event, member, fmt.Sprintf("vote%d", i))))
+	}
+	vote, _ := cip01.NewVoteEvent(sid)
+	vote.SetVote("prop_001", "yes")
+	vote.CreatedAt = propose.CreatedAt + 20
+	assert.NoError(t, s.Apply(withAuthor(vote.Event, alice, "vote")))
+
+	result, err := s.TallyProposal("prop_001", vote.CreatedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Eligible)
+	assert.Equal(t, 1, result.Yes)
+	assert.Equal(t, 0, result.No)
+	assert.Equal(t, cip01.ProposalPassed, result.Status)
+
+	_, err = s.TallyProposal("prop_404", vote.CreatedAt)
+	assert.ErrorIs(t, err, cip01.ErrUnknownProposal)
+}
+
+func TestSubspaceBusinessOps(t *testing.T) {
+	s, create := newTestSubspace(t)
+
+	dataset, _ := cip03.NewDatasetEvent(create.SubspaceID)
+	dataset.SetDatasetInfo("proj_001", "task_001", "training", "jsonl", nil)
+	clock := cip.NewVLC()
+	clock.Increment(cip.KindModelgraphDataset)
+	dataset.SetClock(clock)
+	assert.NoError(t, s.Apply(withAuthor(dataset.Event, alice, "dataset")))
+	assert.True(t, s.Clock.Equal(clock))
+
+	// entity is not part of the subspace ops
+	entity, _ := cip02.NewEntityEvent(create.SubspaceID)
+	entity.SetEntityInfo("alice", "person")
+	assert.ErrorIs(t, s.Apply(withAuthor(entity.Event, alice, "entity")), ErrOpNotAllowed)
+
+	errs := s.Replay([]nostr.Event{
+		withAuthor(dataset.Event, alice, "dataset"),
+		withAuthor(entity.Event, alice, "entity2"),
+	})
+	assert.Len(t, errs, 2)
+}
+
+func TestSubspaceJoinRules(t *testing.T) {
+	s, create := newTestSubspace(t)
+	s.Env = func(pubkey string) cip.RulesEnv {
+		if pubkey == bob {
+			return cip.RulesEnv{cip.RuleVarEnergy: 5000}
+		}
+		return cip.RulesEnv{cip.RuleVarEnergy: 10}
+	}
+
+	ok, err := s.CanJoin(bob)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	join := nostr.NewSubspaceJoinEvent(create.SubspaceID)
+	assert.NoError(t, s.Apply(withAuthor(join.Event, bob, "join")))
+	assert.ErrorIs(t, s.Apply(withAuthor(join.Event, carol, "join2")), ErrRulesNotMet)
+
+	invite, _ := cip01.NewInviteEvent(create.SubspaceID)
+	assert.NoError(t, invite.SetInviter(carol, "energy>=10"))
+	assert.NoError(t, s.Apply(withAuthor(invite.Event, bob, "invite")))
+	assert.True(t, s.IsMember(carol))
+}
@@ -0,0 +1,159 @@
+package cip
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FieldError reports a single invalid field of an event
+type FieldError struct {
+	Field  string
+	Reason string
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// ValidationError collects all the field errors found on an event
+type ValidationError struct {
+	Kind   int
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Error()
+	}
+	return fmt.Sprintf("invalid event of kind %d: %s", e.Kind, strings.Join(reasons, "; "))
+}
+
+// Unwrap exposes the field errors, and the errors they wrap, to errors.Is and errors.As
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, field := range e.Fields {
+		errs[i] = field
+	}
+	return errs
+}
+
+// Has reports whether the given field was rejected
+func (e *ValidationError) Has(field string) bool {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Validator accumulates field errors while checking an event
+type Validator struct {
+	kind   int
+	fields []*FieldError
+}
+
+// NewValidator creates a validator for an event of the given kind
+func NewValidator(kind int) *Validator {
+	return &Validator{kind: kind}
+}
+
+// Fail records an error on a field
+func (v *Validator) Fail(field, format string, args ...any) {
+	v.fields = append(v.fields, &FieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+}
+
+// Check records an error on a field when ok is false
+func (v *Validator) Check(ok bool, field, format string, args ...any) {
+	if !ok {
+		v.Fail(field, format, args...)
+	}
+}
+
+// CheckErr records err on a field when it is not nil
+func (v *Validator) CheckErr(field string, err error) {
+	if err != nil {
+		v.fields = append(v.fields, &FieldError{Field: field, Reason: err.Error(), Err: err})
+	}
+}
+
+// Required checks that a field is present, it reports whether the value can be checked further
+func (v *Validator) Required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.Fail(field, "is required")
+		return false
+	}
+	return true
+}
+
+// RequiredList checks that a list field has at least one non-empty value
+func (v *Validator) RequiredList(field string, values []string) bool {
+	if len(values) == 0 {
+		v.Fail(field, "is required")
+		return false
+	}
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			v.Fail(field, "contains an empty value")
+			return false
+		}
+	}
+	return true
+}
+
+// Number checks that an optional field holds a finite decimal number
+func (v *Validator) Number(field, value string) {
+	if value == "" {
+		return
+	}
+	_, err := ParseNumber(value)
+	v.CheckErr(field, err)
+}
+
+// Integer checks that an optional field holds a non-negative integer
+func (v *Validator) Integer(field, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		v.Fail(field, "not a non-negative integer: %q", value)
+	}
+}
+
+// Hash checks that an optional field holds a 64 character lowercase hex event id
+func (v *Validator) Hash(field, value string) {
+	if value == "" {
+		return
+	}
+	if !IsValidEventID(value) {
+		v.Fail(field, "not a 64 character hex id: %q", value)
+	}
+}
+
+// Err returns a *ValidationError with everything recorded so far, or nil
+func (v *Validator) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Kind: v.kind, Fields: v.fields}
+}
+
+// ParseNumber parses a finite decimal number
+func ParseNumber(value string) (float64, error) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("not a number: %q", value)
+	}
+	return n, nil
+}
+
+// IsValidEventID checks if s is a 64 character lowercase hex string
+func IsValidEventID(s string) bool {
+	return len(s) == 64 && s == strings.ToLower(s) && isValidHexString(s)
+}
@@ -0,0 +1,43 @@
+package cip
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	v := NewValidator(KindGovernanceVote)
+	assert.NoError(t, v.Err())
+
+	assert.True(t, v.Required("a", "x"))
+	assert.False(t, v.Required("b", " "))
+	v.RequiredList("c", []string{"x", ""})
+	v.Number("d", "1.5e3")
+	v.Number("e", "NaN")
+	v.Integer("f", "-1")
+	v.Hash("g", "ABCDEF")
+	v.Check(false, "j", "bad %d", 1)
+
+	sentinel := errors.New("sentinel")
+	v.CheckErr("k", sentinel)
+
+	err := v.Err()
+	var verr *ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, KindGovernanceVote, verr.Kind)
+	fields := make([]string, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.Equal(t, []string{"b", "c", "e", "f", "g", "j", "k"}, fields)
+	assert.True(t, verr.Has("j"))
+	assert.False(t, verr.Has("a"))
+	assert.ErrorIs(t, err, sentinel)
+	assert.Contains(t, err.Error(), "j: bad 1")
+
+	var ferr *FieldError
+	assert.True(t, errors.As(err, &ferr))
+	assert.Equal(t, "b", ferr.Field)
+}
@@ -4,6 +4,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // CausalityKey represents a causality key with its identifier and counter
@@ -53,8 +56,19 @@ func (s *Subspacekey) UpdateCounter(keyID uint32, counter uint64) {
 	}
 }
 
-// CalculateSubspaceID generates a unique subspace ID based on subspace_name, ops, and rules
+// CalculateSubspaceID generates a unique subspace ID based on subspace_name, ops, and rules.
+// Well formed ops are hashed in their canonical form, so the order of the operations does not
+// change the ID.
 func CalculateSubspaceID(subspaceName, ops, rules string) string {
+	if canonical, err := CanonicalOps(ops); err == nil {
+		ops = canonical
+	}
+	return LegacySubspaceID(subspaceName, ops, rules)
+}
+
+// LegacySubspaceID hashes the ops string as written, which is how subspace IDs were calculated
+// before ops were canonicalized
+func LegacySubspaceID(subspaceName, ops, rules string) string {
 	// Concatenate the components
 	input := subspaceName + ops + rules
 	// Calculate SHA256 hash
@@ -91,3 +105,205 @@ func isValidHexString(s string) bool {
 	}
 	return true
 }
+
+// Ordering describes the causal relationship between two clocks
+type Ordering int
+
+const (
+	OrderEqual      Ordering = iota // both clocks have identical counters
+	OrderBefore                     // the clock happened before the other one
+	OrderAfter                      // the clock happened after the other one
+	OrderConcurrent                 // neither clock dominates the other
+)
+
+// String returns the string representation of an Ordering
+func (o Ordering) String() string {
+	switch o {
+	case OrderEqual:
+		return "equal"
+	case OrderBefore:
+		return "before"
+	case OrderAfter:
+		return "after"
+	case OrderConcurrent:
+		return "concurrent"
+	default:
+		return fmt.Sprintf("ordering(%d)", int(o))
+	}
+}
+
+// VLC represents a verifiable logical clock, a vector of Lamport counters indexed by causality key
+type VLC struct {
+	Values map[uint32]uint64 // causality key identifier -> counter
+}
+
+// NewVLC creates a new empty clock
+func NewVLC() *VLC {
+	return &VLC{
+		Values: make(map[uint32]uint64),
+	}
+}
+
+// Get returns the counter for the given key, zero if the key was never incremented
+func (v *VLC) Get(key uint32) uint64 {
+	return v.Values[key]
+}
+
+// Set sets the counter for the given key
+func (v *VLC) Set(key uint32, counter uint64) {
+	if v.Values == nil {
+		v.Values = make(map[uint32]uint64)
+	}
+	v.Values[key] = counter
+}
+
+// Increment advances the counter of the given key and returns the new value
+func (v *VLC) Increment(key uint32) uint64 {
+	if v.Values == nil {
+		v.Values = make(map[uint32]uint64)
+	}
+	v.Values[key]++
+	return v.Values[key]
+}
+
+// Merge sets every counter to the maximum of both clocks
+func (v *VLC) Merge(other *VLC) {
+	if other == nil {
+		return
+	}
+	if v.Values == nil {
+		v.Values = make(map[uint32]uint64, len(other.Values))
+	}
+	for key, counter := range other.Values {
+		if counter > v.Values[key] {
+			v.Values[key] = counter
+		}
+	}
+}
+
+// Clone returns a deep copy of the clock
+func (v *VLC) Clone() *VLC {
+	c := &VLC{
+		Values: make(map[uint32]uint64, len(v.Values)),
+	}
+	for key, counter := range v.Values {
+		c.Values[key] = counter
+	}
+	return c
+}
+
+// Keys returns the causality keys present in the clock in ascending order
+func (v *VLC) Keys() []uint32 {
+	keys := make([]uint32, 0, len(v.Values))
+	for key := range v.Values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Compare returns the partial order of v relative to other.
+// Missing keys count as zero, so a clock is never concurrent with the empty clock.
+func (v *VLC) Compare(other *VLC) Ordering {
+	if other == nil {
+		other = &VLC{}
+	}
+
+	less, greater := false, false
+	for key, counter := range v.Values {
+		o := other.Values[key]
+		if counter < o {
+			less = true
+		} else if counter > o {
+			greater = true
+		}
+	}
+	for key, o := range other.Values {
+		if _, exists := v.Values[key]; !exists && o > 0 {
+			less = true
+		}
+	}
+
+	switch {
+	case less && greater:
+		return OrderConcurrent
+	case less:
+		return OrderBefore
+	case greater:
+		return OrderAfter
+	default:
+		return OrderEqual
+	}
+}
+
+// HappensBefore checks if v causally precedes other
+func (v *VLC) HappensBefore(other *VLC) bool {
+	return v.Compare(other) == OrderBefore
+}
+
+// IsConcurrent checks if neither clock causally precedes the other
+func (v *VLC) IsConcurrent(other *VLC) bool {
+	return v.Compare(other) == OrderConcurrent
+}
+
+// Equal checks if both clocks have identical counters
+func (v *VLC) Equal(other *VLC) bool {
+	return v.Compare(other) == OrderEqual
+}
+
+// String returns the canonical tag encoding of the clock, e.g. "30300:2,30301:5".
+// Keys are sorted ascending and zero counters are omitted so equal clocks encode identically.
+func (v *VLC) String() string {
+	var sb strings.Builder
+	for _, key := range v.Keys() {
+		counter := v.Values[key]
+		if counter == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(key), 10))
+		sb.WriteByte(':')
+		sb.WriteString(strconv.FormatUint(counter, 10))
+	}
+	return sb.String()
+}
+
+// ParseVLC parses a clock from its canonical tag encoding
+func ParseVLC(vlcStr string) (*VLC, error) {
+	vlc := NewVLC()
+	if vlcStr == "" {
+		return vlc, nil
+	}
+
+	for _, part := range strings.Split(vlcStr, ",") {
+		kv := strings.Split(part, ":")
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid vlc part: %s", part)
+		}
+		key, err := strconv.ParseUint(kv[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vlc key: %s", kv[0])
+		}
+		counter, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vlc counter: %s", kv[1])
+		}
+		if _, exists := vlc.Values[uint32(key)]; exists {
+			return nil, fmt.Errorf("duplicate vlc key: %d", key)
+		}
+		vlc.Values[uint32(key)] = counter
+	}
+
+	return vlc, nil
+}
+
+// VLC returns the subspace operation clock as a vector clock
+func (s *Subspacekey) VLC() *VLC {
+	vlc := NewVLC()
+	for id, key := range s.Keys {
+		vlc.Values[id] = key.Counter
+	}
+	return vlc
+}
@@ -41,3 +41,78 @@ func TestValidateSubspaceID(t *testing.T) {
 		assert.Error(t, ValidateSubspaceID(sid))
 	}
 }
+
+func TestVLCIncrementAndMerge(t *testing.T) {
+	a := NewVLC()
+	assert.Equal(t, uint64(1), a.Increment(1))
+	assert.Equal(t, uint64(2), a.Increment(1))
+
+	b := NewVLC()
+	b.Increment(2)
+	b.Set(1, 1)
+
+	a.Merge(b)
+	assert.Equal(t, uint64(2), a.Get(1))
+	assert.Equal(t, uint64(1), a.Get(2))
+	assert.Equal(t, []uint32{1, 2}, a.Keys())
+
+	// merging must not alias the other clock
+	c := a.Clone()
+	c.Increment(3)
+	assert.Equal(t, uint64(0), a.Get(3))
+}
+
+func TestVLCCompare(t *testing.T) {
+	a, _ := ParseVLC("1:1")
+	b, _ := ParseVLC("1:2,2:1")
+	c, _ := ParseVLC("1:1,3:1")
+	d, _ := ParseVLC("1:1,2:0")
+
+	assert.Equal(t, OrderBefore, a.Compare(b))
+	assert.Equal(t, OrderAfter, b.Compare(a))
+	assert.Equal(t, OrderConcurrent, b.Compare(c))
+	assert.Equal(t, OrderEqual, a.Compare(d))
+	assert.Equal(t, OrderAfter, a.Compare(NewVLC()))
+	assert.Equal(t, OrderEqual, NewVLC().Compare(nil))
+
+	assert.True(t, a.HappensBefore(b))
+	assert.False(t, b.HappensBefore(a))
+	assert.True(t, b.IsConcurrent(c))
+	assert.True(t, a.Equal(d))
+}
+
+func TestVLCEncoding(t *testing.T) {
+	vlc := NewVLC()
+	vlc.Set(30301, 5)
+	vlc.Set(30300, 2)
+	vlc.Set(30302, 0)
+	assert.Equal(t, "30300:2,30301:5", vlc.String())
+
+	parsed, err := ParseVLC(vlc.String())
+	assert.NoError(t, err)
+	assert.True(t, parsed.Equal(vlc))
+
+	empty, err := ParseVLC("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", empty.String())
+
+	invalid := []string{
+		"30300",           // missing counter
+		"30300:x",         // invalid counter
+		"x:1",             // invalid key
+		"30300:1,30300:2", // duplicate key
+		"30300:1,",        // trailing comma
+		"99999999999:1",   // key overflows uint32
+	}
+	for _, s := range invalid {
+		_, err := ParseVLC(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestSubspacekeyVLC(t *testing.T) {
+	s := NewSubspace(1)
+	s.AddKey(NewCausalityKey(30300, 3))
+	s.AddKey(NewCausalityKey(30301, 1))
+	assert.Equal(t, "30300:3,30301:1", s.VLC().String())
+}
@@ -0,0 +1,68 @@
+// Package enckey lets address identities take part in encrypted messaging.
+//
+// Events signed with EIP-191 or EIP-712 have a 20 byte address as their pubkey, while NIP-44,
+// NIP-59 and NIP-17 encrypt to a secp256k1 public key. An address is the hash of the key that
+// signs for it and that key can be recovered from any of its signatures, so an address holder
+// announces it with a binding event of kind nostr.KindEncryptionKey:
+//
+//	{"kind": 10044, "tags": [["key", "<compressed public key>"]], ...}
+//
+// Bindings are only valid when the key is the one that signed them.
+package enckey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	nostr "github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrNotBinding      = errors.New("enckey: not an encryption key binding")
+	ErrKeyMismatch     = errors.New("enckey: binding key is not the one that signed it")
+	ErrNoEncryptionKey = errors.New("enckey: no encryption key found")
+)
+
+// Binding maps an address to the public key it encrypts and decrypts with
+type Binding struct {
+	Address   string
+	PublicKey string // compressed, as hex
+	CreatedAt nostr.Timestamp
+}
+
+// EncryptionKey returns the x-only form of the public key, which is what NIP-44 takes
+func (b Binding) EncryptionKey() string {
+	return b.PublicKey[2:]
+}
+
+// NewBindingEvent creates the binding event for a compressed public key, as returned by
+// [nostr.Event.RecoverPublicKey]. It must be signed with the matching secret key.
+func NewBindingEvent(publicKey string) nostr.Event {
+	return nostr.Event{
+		Kind:      nostr.KindEncryptionKey,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{{"key", strings.ToLower(publicKey)}},
+	}
+}
+
+// ParseBinding checks that evt is a binding signed by the key it declares
+func ParseBinding(evt nostr.Event) (Binding, error) {
+	if evt.Kind != nostr.KindEncryptionKey {
+		return Binding{}, fmt.Errorf("%w: kind %d", ErrNotBinding, evt.Kind)
+	}
+	tag := evt.Tags.Find("key")
+	if tag == nil {
+		return Binding{}, fmt.Errorf("%w: missing key tag", ErrNotBinding)
+	}
+
+	signer, err := evt.RecoverPublicKey()
+	if err != nil {
+		return Binding{}, fmt.Errorf("enckey: %w", err)
+	}
+	if !strings.EqualFold(signer, tag[1]) {
+		return Binding{}, ErrKeyMismatch
+	}
+
+	return Binding{Address: evt.PubKey, PublicKey: signer, CreatedAt: evt.CreatedAt}, nil
+}
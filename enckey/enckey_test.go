@@ -0,0 +1,166 @@
+package enckey
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	nostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/nbd-wtf/go-nostr/nip59"
+	"github.com/stretchr/testify/require"
+)
+
+// compressedKey returns the compressed public key of sk, as announced by bindings
+func compressedKey(t *testing.T, sk string) string {
+	s, err := crypto.HexToECDSA(sk)
+	require.NoError(t, err)
+	return hex.EncodeToString(crypto.CompressPubkey(&s.PublicKey))
+}
+
+func binding(t *testing.T, sk string) nostr.Event {
+	evt := NewBindingEvent(compressedKey(t, sk))
+	require.NoError(t, evt.Sign(sk))
+	return evt
+}
+
+// fetchFrom answers with the first of events matching the filter, counting the calls
+func fetchFrom(calls *int, events ...nostr.Event) Fetcher {
+	return func(ctx context.Context, filter nostr.Filter) (*nostr.Event, error) {
+		*calls++
+		for _, evt := range events {
+			if filter.Matches(&evt) {
+				return &evt, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func TestParseBinding(t *testing.T) {
+	alice := nostr.GeneratePrivateKey()
+	aliceAddr, _ := nostr.GetAddress(alice)
+	aliceKey := compressedKey(t, alice)
+
+	b, err := ParseBinding(binding(t, alice))
+	require.NoError(t, err)
+	require.Equal(t, aliceAddr, b.Address)
+	require.Equal(t, aliceKey, b.PublicKey)
+	require.Equal(t, aliceKey[2:], b.EncryptionKey())
+
+	// the key must be the one that signed the binding
+	forged := NewBindingEvent(compressedKey(t, nostr.GeneratePrivateKey()))
+	require.NoError(t, forged.Sign(alice))
+	_, err = ParseBinding(forged)
+	require.ErrorIs(t, err, ErrKeyMismatch)
+
+	tampered := binding(t, alice)
+	tampered.CreatedAt++
+	_, err = ParseBinding(tampered)
+	require.Error(t, err)
+
+	note := nostr.Event{Kind: nostr.KindTextNote, Tags: nostr.Tags{{"key", aliceKey}}}
+	require.NoError(t, note.Sign(alice))
+	_, err = ParseBinding(note)
+	require.ErrorIs(t, err, ErrNotBinding)
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.Background()
+	alice, bob, carol := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	aliceAddr, _ := nostr.GetAddress(alice)
+	aliceKey := compressedKey(t, alice)
+	bobAddr, _ := nostr.GetAddress(bob)
+	bobKey := compressedKey(t, bob)
+	carolAddr, _ := nostr.GetAddress(carol)
+	carolKey := compressedKey(t, carol)
+
+	note := nostr.Event{Kind: nostr.KindTextNote, CreatedAt: 1712345678, Content: "hi"}
+	require.NoError(t, note.Sign(bob))
+
+	var calls int
+	r := NewResolver(fetchFrom(&calls, binding(t, alice), note))
+
+	// from the binding
+	key, err := r.ResolveEncryptionKey(ctx, aliceAddr)
+	require.NoError(t, err)
+	require.Equal(t, aliceKey[2:], key)
+	require.Equal(t, 1, calls)
+
+	// cached, whatever the case of the address
+	key, err = r.ResolveEncryptionKey(ctx, "0x"+aliceAddr)
+	require.NoError(t, err)
+	require.Equal(t, aliceKey[2:], key)
+	require.Equal(t, 1, calls)
+
+	// from any other event
+	key, err = r.ResolveEncryptionKey(ctx, bobAddr)
+	require.NoError(t, err)
+	require.Equal(t, bobKey[2:], key)
+	require.Equal(t, 3, calls)
+
+	_, err = r.ResolveEncryptionKey(ctx, carolAddr)
+	require.ErrorIs(t, err, ErrNoEncryptionKey)
+
+	// learnt from an event seen elsewhere
+	require.True(t, r.Learn(binding(t, carol)))
+	key, err = r.ResolveEncryptionKey(ctx, carolAddr)
+	require.NoError(t, err)
+	require.Equal(t, carolKey[2:], key)
+
+	// x-only keys are already encryption keys
+	pubkey, _ := nostr.GetPublicKey(alice)
+	key, err = r.ResolveEncryptionKey(ctx, pubkey)
+	require.NoError(t, err)
+	require.Equal(t, pubkey, key)
+	require.Equal(t, aliceKey[2:], key)
+}
+
+func TestGiftWrapToAddress(t *testing.T) {
+	ctx := context.Background()
+	alice, bob := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	aliceAddr, _ := nostr.GetAddress(alice)
+	aliceKey := compressedKey(t, alice)
+	bobAddr, _ := nostr.GetAddress(bob)
+
+	var calls int
+	r := NewResolver(fetchFrom(&calls, binding(t, bob)))
+
+	rumor := nostr.Event{Kind: nostr.KindDirectMessage, Content: "hello bob", CreatedAt: nostr.Now(), PubKey: aliceAddr}
+	gw, err := nip59.GiftWrapTo(ctx, r, rumor, bobAddr,
+		func(plaintext string, recipientKey string) (string, error) {
+			ck, err := nip44.GenerateConversationKey(recipientKey, alice)
+			if err != nil {
+				return "", err
+			}
+			return nip44.Encrypt(plaintext, ck)
+		},
+		func(evt *nostr.Event) error { return evt.Sign(alice) },
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, bobAddr, gw.Tags.Find("p")[1])
+
+	// bob recovers the keys of the gift-wrap and of alice from their signatures
+	received, err := nip59.GiftUnwrap(gw, func(otherpubkey, ciphertext string) (string, error) {
+		ck, err := nip44.GenerateConversationKey(otherpubkey, bob)
+		if err != nil {
+			return "", err
+		}
+		return nip44.Decrypt(ciphertext, ck)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hello bob", received.Content)
+	require.Equal(t, aliceAddr, received.PubKey)
+
+	// the same conversation key both ways
+	toBob, err := nip44.GenerateConversationKeyFor(ctx, r, bobAddr, alice)
+	require.NoError(t, err)
+	r.Learn(received)
+	_, err = nip44.GenerateConversationKeyFor(ctx, r, aliceAddr, bob)
+	require.ErrorIs(t, err, ErrNoEncryptionKey) // the rumor isn't signed
+	fromAlice, err := nip44.GenerateConversationKey(aliceKey[2:], bob)
+	require.NoError(t, err)
+	require.Equal(t, toBob, fromAlice)
+}
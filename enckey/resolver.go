@@ -0,0 +1,121 @@
+package enckey
+
+import (
+	"context"
+	"fmt"
+
+	nostr "github.com/nbd-wtf/go-nostr"
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+// Fetcher loads the first event matching a filter, or nil when there is none
+type Fetcher func(ctx context.Context, filter nostr.Filter) (*nostr.Event, error)
+
+// PoolFetcher queries the given relays, taking the first answer
+func PoolFetcher(pool *nostr.SimplePool, urls []string) Fetcher {
+	return func(ctx context.Context, filter nostr.Filter) (*nostr.Event, error) {
+		if ie := pool.QuerySingle(ctx, urls, filter); ie != nil {
+			return ie.Event, nil
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// StoreFetcher queries a RelayStore
+func StoreFetcher(store nostr.RelayStore) Fetcher {
+	return func(ctx context.Context, filter nostr.Filter) (*nostr.Event, error) {
+		events, err := store.QuerySync(ctx, filter)
+		if err != nil || len(events) == 0 {
+			return nil, err
+		}
+		return events[0], nil
+	}
+}
+
+var _ nostr.EncryptionKeyResolver = (*Resolver)(nil)
+
+// Resolver finds the encryption keys of addresses: from the cache, else from their binding, else
+// from any other event they signed. An address can only ever have one key, so keys are cached
+// for good.
+type Resolver struct {
+	fetch Fetcher
+	keys  *xsync.MapOf[string, string] // lowercase address -> x-only key
+}
+
+// NewResolver creates a Resolver, fetch can be nil to only use the keys it is told with Learn
+func NewResolver(fetch Fetcher) *Resolver {
+	return &Resolver{
+		fetch: fetch,
+		keys:  xsync.NewMapOf[string, string](),
+	}
+}
+
+// Learn caches the key that signed evt when its pubkey is an address. Any valid EIP-191 or
+// EIP-712 event will do, bindings or not. It returns false if there was nothing to learn.
+func (r *Resolver) Learn(evt nostr.Event) bool {
+	if scheme := evt.Scheme(); scheme != nostr.SchemeEIP191 && scheme != nostr.SchemeEIP712 {
+		return false
+	}
+	if evt.Kind == nostr.KindEncryptionKey {
+		if _, err := ParseBinding(evt); err != nil {
+			return false
+		}
+	}
+
+	key, err := evt.RecoverPublicKey()
+	if err != nil {
+		return false
+	}
+	addr, err := nostr.LowerAddress(evt.PubKey)
+	if err != nil {
+		return false
+	}
+	r.keys.Store(addr, key[2:])
+	return true
+}
+
+// ResolveEncryptionKey returns x-only keys as they are and the key bound to addresses
+func (r *Resolver) ResolveEncryptionKey(ctx context.Context, pubkey string) (string, error) {
+	if len(pubkey) == 64 {
+		if !nostr.IsValidPublicKey(pubkey) {
+			return "", fmt.Errorf("enckey: invalid public key '%s'", pubkey)
+		}
+		return pubkey, nil
+	}
+
+	addr, err := nostr.LowerAddress(pubkey)
+	if err != nil {
+		return "", fmt.Errorf("enckey: %w", err)
+	}
+	if key, ok := r.keys.Load(addr); ok {
+		return key, nil
+	}
+	if r.fetch == nil {
+		return "", fmt.Errorf("%w for %s", ErrNoEncryptionKey, pubkey)
+	}
+
+	// events are signed with the checksummed form, but relays may have any
+	checksummed, _ := nostr.ChecksumAddress(addr)
+	authors := []string{checksummed}
+	if checksummed != addr {
+		authors = append(authors, addr)
+	}
+
+	// the binding is where the key is announced, then any other event will do
+	for _, filter := range []nostr.Filter{
+		{Kinds: []int{nostr.KindEncryptionKey}, Authors: authors, Limit: 1},
+		{Authors: authors, Limit: 1},
+	} {
+		evt, err := r.fetch(ctx, filter)
+		if err != nil {
+			return "", fmt.Errorf("enckey: fetching events of %s: %w", pubkey, err)
+		}
+		if evt != nil && r.Learn(*evt) {
+			if key, ok := r.keys.Load(addr); ok {
+				return key, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w for %s", ErrNoEncryptionKey, pubkey)
+}
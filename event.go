@@ -1,12 +1,10 @@
 package nostr
 
 import (
-    "fmt"
 	"encoding/hex"
 	"strconv"
 
 	"github.com/mailru/easyjson"
-	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // Event represents a Nostr event.
@@ -26,18 +24,26 @@ func (evt Event) String() string {
 }
 
 // GetID computes the event ID and returns it as a hex string.
+// Schnorr events are hashed with sha256, events declaring EIP-712 get their typed data digest
+// and everything else is hashed with EIP-191.
+// It returns an empty string if the EIP-712 domain declared by the event is invalid.
 func (evt *Event) GetID() string {
-    message := evt.Serialize()
-	prefixedMessage := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
-	h:= crypto.Keccak256Hash([]byte(prefixedMessage))
-	return hex.EncodeToString(h.Bytes())
+	h, err := evt.hash()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h[:])
 }
 
 // CheckID checks if the implied ID matches the given ID more efficiently.
 func (evt *Event) CheckID() bool {
-    message := evt.Serialize()
-	prefixedMessage := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
-	h:= crypto.Keccak256Hash([]byte(prefixedMessage))
+	if len(evt.ID) != 64 {
+		return false
+	}
+	h, err := evt.hash()
+	if err != nil {
+		return false
+	}
 
 	const hextable = "0123456789abcdef"
 
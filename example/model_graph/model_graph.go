@@ -12,16 +12,20 @@ import (
 func main() {
 	relays := []string{"ws://161.97.129.166:10547"}
 	sk := nostr.GeneratePrivateKey()
-	pub, _ := nostr.GetPublicKey(sk)
+	pub, _ := nostr.GetAddress(sk)
 
 	// Create a subspace with modelgraph operations
-	createEvent := nostr.NewSubspaceCreateEvent(
+	createEvent, err := nostr.NewSubspaceCreateEvent(
 		"model_graph",
 		cip.CommonPrjOps + cip.DefaultSubspaceOps + "," + cip.ModelGraphSubspaceOps, // Use modelgraph operations string
 		"energy>10000",
 		"Model Graph Example Subspace",
 		"https://example.com/images/model_subspace.png",
 	)
+	if err != nil {
+		fmt.Printf("err: %s", err)
+		return
+	}
 	createEvent.PubKey = pub
 	createEvent.Sign(sk)
 
@@ -12,16 +12,20 @@ import (
 func main() {
 	relays := []string{"ws://161.97.129.166:10547"}
 	sk := nostr.GeneratePrivateKey()
-	pub, _ := nostr.GetPublicKey(sk)
+	pub, _ := nostr.GetAddress(sk)
 
 	// Create a subspace with OpenResearch operations
-	createEvent := nostr.NewSubspaceCreateEvent(
+	createEvent, err := nostr.NewSubspaceCreateEvent(
 		"openresearch",
 		cip.OpenResearchSubspaceOps,
 		"energy>10000",
 		"OpenResearch Example Subspace",
 		"https://example.com/images/subspace.png",
 	)
+	if err != nil {
+		fmt.Printf("err: %s", err)
+		return
+	}
 	createEvent.PubKey = pub
 	createEvent.Sign(sk)
 
@@ -10,7 +10,7 @@ import (
 func main() {
 	relays := []string{"ws://161.97.129.166:10547"}
 	sk := nostr.GeneratePrivateKey()
-	pub, _ := nostr.GetPublicKey(sk)
+	pub, _ := nostr.GetAddress(sk)
 	ev := nostr.Event{
 		PubKey:    pub,
 		CreatedAt: nostr.Now(),
@@ -18,16 +18,20 @@ func AllOps() string {
 func main() {
 	relays := []string{"ws://161.97.129.166:10547"}
 	sk := nostr.GeneratePrivateKey()
-	pub, _ := nostr.GetPublicKey(sk)
+	pub, _ := nostr.GetAddress(sk)
 
 	// Create a subspace with all operations (basic + business)
-	createEvent := nostr.NewSubspaceCreateEvent(
+	createEvent, err := nostr.NewSubspaceCreateEvent(
 		"modelgraph",
 		AllOps(), // Use combined operations string
 		"energy>10000",
 		"Desci AI Model collaboration subspace",
 		"https://causality-graph.com/images/subspace.png",
 	)
+	if err != nil {
+		fmt.Printf("err: %s", err)
+		return
+	}
 	createEvent.PubKey = pub
 	createEvent.Sign(sk)
 
@@ -50,7 +54,10 @@ func main() {
 	// Create a proposal (basic operation)
 	proposeEvent, err := cip01.NewProposeEvent(createEvent.SubspaceID)
 	proposeEvent.PubKey = pub
-	proposeEvent.SetProposal("prop_001", "energy>2000")
+	if err := proposeEvent.SetProposal("prop_001", "energy>2000"); err != nil {
+		fmt.Printf("err: %s", err)
+		return
+	}
 	proposeEvent.Content = "Increase the energy requirement for subspace addition to 2000"
 	proposeEvent.Sign(sk)
 
@@ -64,7 +71,11 @@ func main() {
 	// Create an invite (basic operation)
 	inviteEvent, err := cip01.NewInviteEvent(createEvent.SubspaceID)
 	inviteEvent.PubKey = pub
-	inviteEvent.SetInviter("<Charlie's ETH Address>", "energy>1000")
+	charlie, _ := nostr.GetAddress(nostr.GeneratePrivateKey())
+	if err := inviteEvent.SetInviter(charlie, "energy>1000"); err != nil {
+		fmt.Printf("err: %s", err)
+		return
+	}
 	inviteEvent.Content = "Invite Charlie join into Desci AI subspace"
 	inviteEvent.Sign(sk)
 
@@ -41,3 +41,10 @@ type Cipher interface {
 	// Returns the decrypted plaintext.
 	Decrypt(ctx context.Context, base64ciphertext string, senderPublicKey string) (plaintext string, err error)
 }
+
+// EncryptionKeyResolver finds the key to encrypt messages to a pubkey with. That is the pubkey
+// itself for x-only keys, but addresses must be resolved to the key that signs for them.
+type EncryptionKeyResolver interface {
+	// ResolveEncryptionKey returns the x-only public key, as hex, to use for the given pubkey.
+	ResolveEncryptionKey(ctx context.Context, pubkey string) (string, error)
+}
@@ -41,15 +41,19 @@ func (es *EncryptedKeySigner) GetPublicKey(ctx context.Context) (string, error)
 }
 
 // SignEvent signs the provided event by first decrypting the private key
-// using the password callback, then signing the event with the decrypted key.
+// using the password callback, then signing the event with the decrypted key
+// using Schnorr signatures, like KeySigner.
 func (es *EncryptedKeySigner) SignEvent(ctx context.Context, evt *nostr.Event) error {
 	password := es.callback(ctx)
 	sk, err := nip49.Decrypt(es.ncryptsec, password)
 	if err != nil {
 		return fmt.Errorf("invalid password: %w", err)
 	}
+	if err := evt.Sign(sk, nostr.WithScheme(nostr.SchemeSchnorr)); err != nil {
+		return err
+	}
 	es.pk = evt.PubKey
-	return evt.Sign(sk)
+	return nil
 }
 
 // Encrypt encrypts a plaintext message for a recipient using NIP-44.
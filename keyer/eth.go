@@ -0,0 +1,67 @@
+package keyer
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip06"
+)
+
+var _ nostr.Keyer = (*EthKeySigner)(nil)
+
+// EthKeySigner is like KeySigner, but signs with EIP-191 and is identified by its Ethereum address.
+// Encrypt and Decrypt take x-only keys, addresses can be resolved to them with package enckey.
+type EthKeySigner struct {
+	KeySigner
+	address string
+}
+
+// NewEthKeySigner creates a new EthKeySigner from a hex private key.
+// Returns an error if the private key is invalid.
+func NewEthKeySigner(sec string) (EthKeySigner, error) {
+	ks, err := NewPlainKeySigner(sec)
+	if err != nil {
+		return EthKeySigner{}, err
+	}
+	address, err := nostr.GetAddress(sec)
+	if err != nil {
+		return EthKeySigner{}, err
+	}
+	return EthKeySigner{ks, address}, nil
+}
+
+// NewKeystoreSigner creates a new EthKeySigner from a go-ethereum keystore v3 JSON file,
+// decrypting it with the given password.
+func NewKeystoreSigner(keyjson []byte, password string) (EthKeySigner, error) {
+	key, err := keystore.DecryptKey(keyjson, password)
+	if err != nil {
+		return EthKeySigner{}, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return NewEthKeySigner(hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)))
+}
+
+// NewHDSigner creates a new EthKeySigner from a BIP-39 mnemonic, with the key of the account at
+// index on the Ethereum path m/44'/60'/0'/0/index.
+func NewHDSigner(words string, index uint32) (EthKeySigner, error) {
+	if !nip06.ValidateWords(words) {
+		return EthKeySigner{}, fmt.Errorf("invalid mnemonic")
+	}
+	sec, err := nip06.EthereumPrivateKeyFromSeed(nip06.SeedFromWords(words), index)
+	if err != nil {
+		return EthKeySigner{}, err
+	}
+	return NewEthKeySigner(sec)
+}
+
+// SignEvent signs the provided event with EIP-191.
+// It sets the event's ID, PubKey, and Sig fields.
+func (es EthKeySigner) SignEvent(ctx context.Context, evt *nostr.Event) error {
+	return evt.Sign(es.sk, nostr.WithScheme(nostr.SchemeEIP191))
+}
+
+// GetPublicKey returns the address of this signer, which is the pubkey of the events it signs.
+func (es EthKeySigner) GetPublicKey(ctx context.Context) (string, error) { return es.address, nil }
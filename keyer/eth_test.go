@@ -0,0 +1,143 @@
+package keyer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/require"
+)
+
+func signAndCheck(t *testing.T, kr nostr.Keyer) nostr.Event {
+	t.Helper()
+	ctx := context.Background()
+
+	evt := nostr.Event{Kind: nostr.KindTextNote, CreatedAt: 1712345678, Content: "hello"}
+	require.NoError(t, kr.SignEvent(ctx, &evt))
+	require.Equal(t, nostr.SchemeEIP191, evt.Scheme())
+	require.True(t, evt.CheckID())
+	ok, err := evt.CheckSignature()
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	pk, err := kr.GetPublicKey(ctx)
+	require.NoError(t, err)
+	require.Equal(t, evt.PubKey, pk)
+	return evt
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	sk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyjson, err := keystore.EncryptKey(&keystore.Key{
+		Address:    crypto.PubkeyToAddress(sk.PublicKey),
+		PrivateKey: sk,
+	}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+
+	kr, err := New(context.Background(), nil, string(keyjson), &SignerOptions{Password: "secret"})
+	require.NoError(t, err)
+	evt := signAndCheck(t, kr)
+	require.Equal(t, strings.TrimPrefix(crypto.PubkeyToAddress(sk.PublicKey).Hex(), "0x"), evt.PubKey)
+
+	_, err = NewKeystoreSigner(keyjson, "wrong")
+	require.Error(t, err)
+}
+
+func TestHDSigner(t *testing.T) {
+	// the standard test mnemonic and the addresses wallets derive from it
+	words := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	for index, address := range []string{
+		"9858EfFD232B4033E47d90003D41EC34EcaEda94",
+		"6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0",
+	} {
+		kr, err := New(context.Background(), nil, words, &SignerOptions{AccountIndex: uint32(index)})
+		require.NoError(t, err)
+		evt := signAndCheck(t, kr)
+		require.Equal(t, address, evt.PubKey)
+	}
+
+	_, err := NewHDSigner("abandon abandon abandon", 0)
+	require.Error(t, err)
+}
+
+// newRPCServer stands in for an Ethereum node holding the given key
+func newRPCServer(t *testing.T, hexkey string) *httptest.Server {
+	sk, err := crypto.HexToECDSA(hexkey)
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(sk.PublicKey)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int64    `json:"id"`
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result any
+		switch req.Method {
+		case "eth_accounts":
+			result = []string{strings.ToLower(address.Hex())}
+		case "personal_sign", "eth_sign":
+			// it signs with its key whatever the account asked for
+			data := req.Params[0]
+			if req.Method == "eth_sign" {
+				data = req.Params[1]
+			}
+
+			msg, err := hexutil.Decode(data)
+			require.NoError(t, err)
+			sig, err := crypto.Sign(accounts.TextHash(msg), sk)
+			require.NoError(t, err)
+			sig[64] += 27
+			result = hexutil.Encode(sig)
+		default:
+			json.NewEncoder(w).Encode(map[string]any{
+				"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]any{"code": -32601, "message": "method not found"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+}
+
+func TestRPCSigner(t *testing.T) {
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	address, _ := nostr.GetAddress(sk)
+
+	server := newRPCServer(t, sk)
+	defer server.Close()
+
+	// the account is found with eth_accounts
+	kr, err := New(ctx, nil, server.URL, nil)
+	require.NoError(t, err)
+	evt := signAndCheck(t, kr)
+	require.Equal(t, address, evt.PubKey)
+
+	rs, err := NewRPCSigner(ctx, server.URL, "0x"+strings.ToLower(address), WithEthSign())
+	require.NoError(t, err)
+	evt = signAndCheck(t, rs)
+	require.Equal(t, address, evt.PubKey)
+
+	_, err = rs.Encrypt(ctx, "hello", hex.EncodeToString(make([]byte, 32)))
+	require.Error(t, err)
+
+	// signatures by another key are rejected
+	other := newRPCServer(t, nostr.GeneratePrivateKey())
+	defer other.Close()
+	rs, err = NewRPCSigner(ctx, other.URL, address)
+	require.NoError(t, err)
+	require.Error(t, rs.SignEvent(ctx, &nostr.Event{Kind: nostr.KindTextNote}))
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip05"
+	"github.com/nbd-wtf/go-nostr/nip06"
 	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/nbd-wtf/go-nostr/nip46"
 	"github.com/nbd-wtf/go-nostr/nip49"
@@ -20,6 +21,8 @@ var (
 	_ nostr.Keyer = (*EncryptedKeySigner)(nil)
 	_ nostr.Keyer = (*KeySigner)(nil)
 	_ nostr.Keyer = (*ManualSigner)(nil)
+	_ nostr.Keyer = (*EthKeySigner)(nil)
+	_ nostr.Keyer = (*RPCSigner)(nil)
 )
 
 // SignerOptions contains configuration options for creating a new signer.
@@ -38,14 +41,24 @@ type SignerOptions struct {
 	// every time an operation needs access to the key so the user can be prompted.
 	PasswordHandler func(context.Context) string
 
-	// Password is used along with ncryptsec to decrypt the key.
+	// Password is used along with ncryptsec or a keystore v3 JSON to decrypt the key.
 	// If provided, the key will be decrypted and stored in plaintext.
 	Password string
+
+	// AccountIndex picks the account derived from a mnemonic, on the path m/44'/60'/0'/0/index
+	AccountIndex uint32
+
+	// RPCAccount is the address of the account a JSON-RPC endpoint signs with.
+	// If empty the first one it has is used.
+	RPCAccount string
 }
 
 // New creates a new Keyer implementation based on the input string format.
 // It supports various input formats:
 // - ncryptsec: Creates an EncryptedKeySigner or KeySigner depending on options
+// - keystore v3 JSON: Creates an EthKeySigner, decrypted with the password
+// - BIP-39 mnemonic: Creates an EthKeySigner for the account at AccountIndex
+// - http(s) URL: Creates an RPCSigner for an Ethereum JSON-RPC endpoint
 // - NIP-46 bunker URL or NIP-05 identifier: Creates a BunkerSigner
 // - nsec: Creates a KeySigner
 // - hex private key: Creates a KeySigner
@@ -71,6 +84,12 @@ func New(ctx context.Context, pool *nostr.SimplePool, input string, opts *Signer
 		}
 		pk, _ := nostr.GetPublicKey(sec)
 		return KeySigner{sec, pk, xsync.NewMapOf[string, [32]byte]()}, nil
+	} else if strings.HasPrefix(strings.TrimSpace(input), "{") {
+		return NewKeystoreSigner([]byte(input), opts.Password)
+	} else if nip06.ValidateWords(input) {
+		return NewHDSigner(input, opts.AccountIndex)
+	} else if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		return NewRPCSigner(ctx, input, opts.RPCAccount)
 	} else if nip46.IsValidBunkerURL(input) || nip05.IsValidIdentifier(input) {
 		bcsk := nostr.GeneratePrivateKey()
 		oa := func(url string) { println("auth_url received but not handled") }
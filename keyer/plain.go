@@ -28,11 +28,14 @@ func NewPlainKeySigner(sec string) (KeySigner, error) {
 	return KeySigner{sec, pk, xsync.NewMapOf[string, [32]byte]()}, nil
 }
 
-// SignEvent signs the provided event with the signer's private key.
+// SignEvent signs the provided event with the signer's private key using Schnorr signatures, so
+// the events have the x-only pubkey of the signer. EthKeySigner signs with EIP-191 instead.
 // It sets the event's ID, PubKey, and Sig fields.
-func (ks KeySigner) SignEvent(ctx context.Context, evt *nostr.Event) error { return evt.Sign(ks.sk) }
+func (ks KeySigner) SignEvent(ctx context.Context, evt *nostr.Event) error {
+	return evt.Sign(ks.sk, nostr.WithScheme(nostr.SchemeSchnorr))
+}
 
-// GetPublicKey returns the public key associated with this signer.
+// GetPublicKey returns the x-only public key associated with this signer, which is the pubkey of the events it signs.
 func (ks KeySigner) GetPublicKey(ctx context.Context) (string, error) { return ks.pk, nil }
 
 // Encrypt encrypts a plaintext message for a recipient using NIP-44.
@@ -0,0 +1,59 @@
+package keyer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/nbd-wtf/go-nostr/nip49"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignersPublicKey(t *testing.T) {
+	ctx := context.Background()
+	sk := "040cbf11f24b080ad9d8669d7514d9f3b7b1f58e5a6dcb75549352b041656537"
+	nsec, err := nip19.EncodePrivateKey(sk)
+	require.NoError(t, err)
+	ncryptsec, err := nip49.Encrypt(sk, "secret", 1, nip49.ClientDoesNotTrackThisData)
+	require.NoError(t, err)
+
+	plain, err := NewPlainKeySigner(sk)
+	require.NoError(t, err)
+	eth, err := NewEthKeySigner(sk)
+	require.NoError(t, err)
+	fromHex, err := New(ctx, nil, sk, nil)
+	require.NoError(t, err)
+	fromNsec, err := New(ctx, nil, nsec, nil)
+	require.NoError(t, err)
+	decrypted, err := New(ctx, nil, ncryptsec, &SignerOptions{Password: "secret"})
+	require.NoError(t, err)
+	encrypted, err := New(ctx, nil, ncryptsec, &SignerOptions{
+		PasswordHandler: func(context.Context) string { return "secret" },
+	})
+	require.NoError(t, err)
+
+	for name, kr := range map[string]nostr.Keyer{
+		"plain":     plain,
+		"eth":       eth,
+		"hex":       fromHex,
+		"nsec":      fromNsec,
+		"decrypted": decrypted,
+		"encrypted": encrypted,
+	} {
+		// before and after signing, encrypted signers cache the pubkey when signing
+		pk, err := kr.GetPublicKey(ctx)
+		require.NoError(t, err, name)
+
+		evt := nostr.Event{Kind: nostr.KindTextNote, CreatedAt: 1712345678, Content: "hello"}
+		require.NoError(t, kr.SignEvent(ctx, &evt), name)
+		ok, err := evt.CheckSignature()
+		require.NoError(t, err, name)
+		require.True(t, ok, name)
+		require.Equal(t, pk, evt.PubKey, name)
+
+		pk, err = kr.GetPublicKey(ctx)
+		require.NoError(t, err, name)
+		require.Equal(t, pk, evt.PubKey, name)
+	}
+}
@@ -0,0 +1,163 @@
+package keyer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var _ nostr.Keyer = (*RPCSigner)(nil)
+
+// RPCSigner is a signer that asks an Ethereum JSON-RPC endpoint, such as a node with unlocked
+// accounts or a wallet bridge, to sign events with personal_sign or eth_sign. Both produce
+// EIP-191 signatures, so its public key is the address of the account.
+//
+// The secret key never leaves the endpoint, so it can't encrypt or decrypt NIP-44 payloads.
+type RPCSigner struct {
+	url     string
+	account string // checksummed, without 0x
+	method  string
+	client  *http.Client
+	nextID  atomic.Int64
+}
+
+// RPCSignerOption changes how an RPCSigner talks to its endpoint
+type RPCSignerOption func(*RPCSigner)
+
+// WithEthSign makes the signer use eth_sign instead of personal_sign
+func WithEthSign() RPCSignerOption {
+	return func(rs *RPCSigner) { rs.method = "eth_sign" }
+}
+
+// WithHTTPClient sets the HTTP client used to reach the endpoint
+func WithHTTPClient(client *http.Client) RPCSignerOption {
+	return func(rs *RPCSigner) { rs.client = client }
+}
+
+// NewRPCSigner creates a new RPCSigner for the given account of the endpoint at url.
+// If account is empty the first one returned by eth_accounts is used.
+func NewRPCSigner(ctx context.Context, url string, account string, opts ...RPCSignerOption) (*RPCSigner, error) {
+	rs := &RPCSigner{
+		url:    url,
+		method: "personal_sign",
+		client: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(rs)
+	}
+
+	if account == "" {
+		var accounts []string
+		if err := rs.call(ctx, "eth_accounts", []any{}, &accounts); err != nil {
+			return nil, err
+		}
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("no accounts available at %s", url)
+		}
+		account = accounts[0]
+	}
+
+	address, err := nostr.ChecksumAddress(account)
+	if err != nil {
+		return nil, err
+	}
+	rs.account = address
+
+	return rs, nil
+}
+
+// SignEvent sends the serialized event to be signed by the endpoint.
+// It sets the event's ID, PubKey, and Sig fields, and fails if the signature doesn't verify.
+func (rs *RPCSigner) SignEvent(ctx context.Context, evt *nostr.Event) error {
+	if evt.Tags == nil {
+		evt.Tags = make(nostr.Tags, 0)
+	}
+	evt.PubKey = rs.account
+
+	data := "0x" + hex.EncodeToString(evt.Serialize())
+	params := []any{data, "0x" + rs.account}
+	if rs.method == "eth_sign" {
+		params = []any{"0x" + rs.account, data}
+	}
+
+	var sig string
+	if err := rs.call(ctx, rs.method, params, &sig); err != nil {
+		return err
+	}
+	evt.Sig = strings.TrimPrefix(sig, "0x")
+	evt.ID = evt.GetID()
+
+	if ok, err := evt.CheckSignature(); !ok {
+		return fmt.Errorf("%s returned an invalid signature: %v", rs.method, err)
+	}
+	return nil
+}
+
+// GetPublicKey returns the address of the account, which is the pubkey of the events it signs.
+func (rs *RPCSigner) GetPublicKey(ctx context.Context) (string, error) { return rs.account, nil }
+
+// Encrypt returns an error.
+func (rs *RPCSigner) Encrypt(ctx context.Context, plaintext string, recipient string) (string, error) {
+	return "", fmt.Errorf("json-rpc signer can't encrypt, the key is not available")
+}
+
+// Decrypt returns an error.
+func (rs *RPCSigner) Decrypt(ctx context.Context, base64ciphertext string, sender string) (string, error) {
+	return "", fmt.Errorf("json-rpc signer can't decrypt, the key is not available")
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (rs *RPCSigner) call(ctx context.Context, method string, params []any, result any) error {
+	body, err := json.Marshal(rpcRequest{"2.0", rs.nextID.Add(1), method, params})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rs.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rs.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s call failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s call failed: http status %d", method, resp.StatusCode)
+	}
+
+	var res rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("%s returned invalid json: %w", method, err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("%s failed: %s (%d)", method, res.Error.Message, res.Error.Code)
+	}
+	if err := json.Unmarshal(res.Result, result); err != nil {
+		return fmt.Errorf("%s returned an unexpected result: %w", method, err)
+	}
+	return nil
+}
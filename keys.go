@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"strings"
 
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func GeneratePrivateKey() string {
@@ -27,6 +30,8 @@ func GeneratePrivateKey() string {
 	return fmt.Sprintf("%064x", k.Bytes())
 }
 
+// GetPublicKey returns the x-only public key of a secret key, which is the pubkey of events
+// signed with SchemeSchnorr. Use GetAddress for events signed with SchemeEIP191.
 func GetPublicKey(sk string) (string, error) {
 	b, err := hex.DecodeString(sk)
 	if err != nil {
@@ -34,16 +39,99 @@ func GetPublicKey(sk string) (string, error) {
 	}
 
 	_, pk := btcec.PrivKeyFromBytes(b)
-	return hex.EncodeToString(pk.SerializeUncompressed()), nil
+	return hex.EncodeToString(schnorr.SerializePubKey(pk)), nil
 }
 
+// GetAddress returns the EIP-55 checksummed address of a secret key without the 0x prefix,
+// which is the pubkey of events signed with SchemeEIP191
+func GetAddress(sk string) (string, error) {
+	s, err := crypto.HexToECDSA(sk)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(crypto.PubkeyToAddress(s.PublicKey).Hex(), "0x"), nil
+}
+
+// GetPublicKeyFor returns the pubkey a secret key signs events with under the given scheme
+func GetPublicKeyFor(sk string, scheme SignatureScheme) (string, error) {
+	switch scheme {
+	case SchemeEIP191, SchemeEIP712:
+		return GetAddress(sk)
+	case SchemeSchnorr:
+		return GetPublicKey(sk)
+	default:
+		return "", fmt.Errorf("unsupported signature scheme %s", scheme)
+	}
+}
+
+// IsValidPublicKey checks if pk is either a lowercase hex x-only public key or an address
+// as accepted by IsValidAddress, without the 0x prefix
 func IsValidPublicKey(pk string) bool {
-	if !isLowerHex(pk) {
+	switch len(pk) {
+	case 64:
+		if !isLowerHex(pk) {
+			return false
+		}
+		v, _ := hex.DecodeString(pk)
+		_, err := schnorr.ParsePubKey(v)
+		return err == nil
+	case 40:
+		return IsValidAddress(pk)
+	default:
 		return false
 	}
+}
 
-	v, _ := hex.DecodeString(pk)
-    _, err := btcec.ParsePubKey(v)
+// IsValidAddress checks if addr is a 20 byte hex address, with or without 0x prefix.
+// Mixed case addresses must carry a valid EIP-55 checksum.
+func IsValidAddress(addr string) bool {
+	addr = strings.TrimPrefix(addr, "0x")
+	if len(addr) != 40 {
+		return false
+	}
+	if _, err := hex.DecodeString(addr); err != nil {
+		return false
+	}
+	if addr == strings.ToLower(addr) || addr == strings.ToUpper(addr) {
+		return true
+	}
+	return addr == checksumAddress(addr)
+}
+
+// ChecksumAddress converts an address to its EIP-55 checksummed form without the 0x prefix
+func ChecksumAddress(addr string) (string, error) {
+	if !IsValidAddress(addr) {
+		return "", fmt.Errorf("invalid address '%s'", addr)
+	}
+	return checksumAddress(strings.TrimPrefix(addr, "0x")), nil
+}
 
-	return err == nil
+// LowerAddress converts an address to its lowercase hex form without the 0x prefix
+func LowerAddress(addr string) (string, error) {
+	if !IsValidAddress(addr) {
+		return "", fmt.Errorf("invalid address '%s'", addr)
+	}
+	return strings.ToLower(strings.TrimPrefix(addr, "0x")), nil
+}
+
+// checksumAddress applies EIP-55 to 40 hex characters: a letter is uppercased when the
+// matching nibble of keccak256(lowercase address) is 8 or more
+func checksumAddress(addr string) string {
+	lower := strings.ToLower(addr)
+	hash := crypto.Keccak256([]byte(lower))
+
+	result := []byte(lower)
+	for i, c := range result {
+		if c < 'a' {
+			continue
+		}
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			result[i] = c - 'a' + 'A'
+		}
+	}
+	return string(result)
 }
@@ -0,0 +1,68 @@
+package nostr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAddress(t *testing.T) {
+	address, err := GetAddress("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	assert.NoError(t, err)
+	assert.Equal(t, "2c7536E3605D9C16a7a3D7b1898e529396a65c23", address)
+
+	sk := GeneratePrivateKey()
+	for _, scheme := range []SignatureScheme{SchemeEIP191, SchemeSchnorr} {
+		pubkey, err := GetPublicKeyFor(sk, scheme)
+		assert.NoError(t, err)
+		assert.True(t, IsValidPublicKey(pubkey))
+
+		evt := Event{Kind: KindTextNote}
+		assert.NoError(t, evt.Sign(sk, WithScheme(scheme)))
+		assert.Equal(t, pubkey, evt.PubKey)
+	}
+
+	_, err = GetAddress("zz")
+	assert.Error(t, err)
+}
+
+func TestChecksumAddress(t *testing.T) {
+	// test vectors from EIP-55
+	for _, expected := range []string{
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"fB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"dbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"D1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		assert.True(t, IsValidAddress(expected))
+		assert.True(t, IsValidAddress("0x"+expected))
+
+		checksummed, err := ChecksumAddress("0x" + strings.ToLower(expected))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, checksummed)
+
+		lower, err := LowerAddress(expected)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.ToLower(expected), lower)
+	}
+
+	// a single flipped letter breaks the checksum
+	assert.False(t, IsValidAddress("5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"))
+	assert.True(t, IsValidAddress("5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"))
+	assert.False(t, IsValidAddress("5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA"))
+	assert.False(t, IsValidAddress("zzAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"))
+	_, err := ChecksumAddress("0x1234")
+	assert.Error(t, err)
+}
+
+func TestIsValidPublicKey(t *testing.T) {
+	assert.True(t, IsValidPublicKey("3bf0c63fcb93463407af97a5e5ee64fa883d107ef9e558472c4eb9aaaefa459d"))
+	assert.True(t, IsValidPublicKey("5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"))
+	assert.True(t, IsValidPublicKey("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+
+	assert.False(t, IsValidPublicKey("3BF0C63FCB93463407AF97A5E5EE64FA883D107EF9E558472C4EB9AAAEFA459D"))
+	assert.False(t, IsValidPublicKey("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"))
+	assert.False(t, IsValidPublicKey("5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"))
+	assert.False(t, IsValidPublicKey(""))
+}
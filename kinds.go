@@ -76,6 +76,7 @@ const (
 	KindInterestList             int = 10015
 	KindNutZapInfo               int = 10019
 	KindEmojiList                int = 10030
+	KindEncryptionKey            int = 10044
 	KindDMRelayList              int = 10050
 	KindUserServerList           int = 10063
 	KindFileStorageServerList    int = 10096
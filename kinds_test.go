@@ -1,8 +1,13 @@
 package nostr
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
 	"testing"
 
+	"github.com/nbd-wtf/go-nostr/cip"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,3 +22,30 @@ func KindKindTest(t *testing.T) {
 	require.True(t, IsAddressableKind(30023))
 	require.True(t, IsAddressableKind(39000))
 }
+
+func TestCIPKindsDontCollide(t *testing.T) {
+	// the kinds declared in kinds.go
+	file, err := parser.ParseFile(token.NewFileSet(), "kinds.go", nil, 0)
+	require.NoError(t, err)
+	nip := make(map[int]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, name := range spec.Names {
+			if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.INT {
+				kind, err := strconv.Atoi(lit.Value)
+				require.NoError(t, err)
+				nip[kind] = name.Name
+			}
+		}
+		return false
+	})
+	require.Contains(t, nip, KindTextNote)
+
+	for kind, op := range cip.KeyOpMap {
+		name, ok := nip[kind]
+		require.False(t, ok, "cip kind %d (%s) collides with %s", kind, op, name)
+	}
+}
@@ -26,17 +26,31 @@ func SeedFromWords(words string) []byte {
 }
 
 func PrivateKeyFromSeed(seed []byte) (string, error) {
-	key, err := bip32.NewMasterKey(seed)
-	if err != nil {
-		return "", err
-	}
-
-	derivationPath := []uint32{
+	return deriveKey(seed, []uint32{
 		bip32.FirstHardenedChild + 44,
 		bip32.FirstHardenedChild + 1237,
 		bip32.FirstHardenedChild + 0,
 		0,
 		0,
+	})
+}
+
+// EthereumPrivateKeyFromSeed derives the key of the account at index on the standard Ethereum
+// path m/44'/60'/0'/0/index, the one wallets use for the same mnemonic.
+func EthereumPrivateKeyFromSeed(seed []byte, index uint32) (string, error) {
+	return deriveKey(seed, []uint32{
+		bip32.FirstHardenedChild + 44,
+		bip32.FirstHardenedChild + 60,
+		bip32.FirstHardenedChild + 0,
+		0,
+		index,
+	})
+}
+
+func deriveKey(seed []byte, derivationPath []uint32) (string, error) {
+	key, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return "", err
 	}
 
 	next := key
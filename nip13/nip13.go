@@ -2,13 +2,14 @@ package nip13
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/bits"
 	"runtime"
 	"strconv"
 
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	nostr "github.com/nbd-wtf/go-nostr"
 )
 
@@ -16,8 +17,74 @@ var (
 	ErrDifficultyTooLow = errors.New("nip13: insufficient difficulty")
 	ErrGenerateTimeout  = errors.New("nip13: generating proof of work took too long")
 	ErrMissingPubKey    = errors.New("nip13: attempting to work on an event without a pubkey, which makes no sense")
+	ErrIDMismatch       = errors.New("nip13: event id does not match its content")
 )
 
+// IDFunc computes the id of an event, proof of work is measured on it.
+// The id depends on the signature scheme, so work must be done with the same function
+// that the event will be signed and verified with.
+type IDFunc func(evt *nostr.Event) ([32]byte, error)
+
+// EventID computes the id like [nostr.Event.GetID]: keccak256 of the EIP-191 message for
+// address pubkeys, sha256 of the serialized event for x-only pubkeys and the typed data digest
+// for events that declare the EIP-712 scheme.
+func EventID(evt *nostr.Event) ([32]byte, error) {
+	var id [32]byte
+	_, err := hex.Decode(id[:], []byte(evt.GetID()))
+	return id, err
+}
+
+// EIP712ID computes the id events get when signed with [nostr.Event.Sign_eip712] in the given
+// domain, which declares it with a scheme tag. Once they are signed EventID gives the same id.
+func EIP712ID(domain ...apitypes.TypedDataDomain) IDFunc {
+	return func(evt *nostr.Event) ([32]byte, error) {
+		var id [32]byte
+
+		d := nostr.DefaultEIP712Domain
+		if len(domain) > 0 {
+			d = domain[0]
+		} else if evt.Scheme() == nostr.SchemeEIP712 {
+			declared, err := evt.EIP712Domain()
+			if err != nil {
+				return id, err
+			}
+			d = declared
+		}
+
+		// copy the tags so the scheme tag isn't added to the event itself
+		signed := *evt
+		signed.Tags = append(make(nostr.Tags, 0, len(evt.Tags)+1), evt.Tags...)
+		signed.SetEIP712Domain(d)
+
+		h, err := signed.GetID_eip712(d)
+		if err != nil {
+			return id, err
+		}
+		_, err = hex.Decode(id[:], []byte(h))
+		return id, err
+	}
+}
+
+// WorkOption configures DoWork and Verify
+type WorkOption func(*workOptions)
+
+type workOptions struct {
+	id IDFunc
+}
+
+// WithIDFunc sets how event ids are computed, EventID is used by default
+func WithIDFunc(id IDFunc) WorkOption {
+	return func(o *workOptions) { o.id = id }
+}
+
+func newWorkOptions(opts []WorkOption) workOptions {
+	o := workOptions{id: EventID}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // CommittedDifficulty returns the Difficulty but checks the "nonce" tag for a target.
 //
 // if the target is smaller than the actual difficulty then the value of the target is used.
@@ -78,13 +145,35 @@ func Check(id string, minDifficulty int) error {
 	return nil
 }
 
+// Verify is meant for relays: it recomputes the id of the event instead of trusting it, then
+// checks that the committed proof of work reaches minDifficulty.
+// The signature is not checked, see [nostr.Event.CheckSignature].
+func Verify(event *nostr.Event, minDifficulty int, opts ...WorkOption) error {
+	o := newWorkOptions(opts)
+	id, err := o.id(event)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrIDMismatch, err)
+	}
+	if hex.EncodeToString(id[:]) != event.ID {
+		return ErrIDMismatch
+	}
+	if CommittedDifficulty(event) < minDifficulty {
+		return ErrDifficultyTooLow
+	}
+	return nil
+}
+
 // DoWork() performs work in multiple threads (given by runtime.NumCPU()) and returns the first
-// nonce (as a nostr.Tag) that yields the required work.
+// nonce (as a nostr.Tag) that yields the required work on the id computed by the IDFunc.
 // Returns an error if the context expires before that.
-func DoWork(ctx context.Context, event nostr.Event, targetDifficulty int) (nostr.Tag, error) {
+func DoWork(ctx context.Context, event nostr.Event, targetDifficulty int, opts ...WorkOption) (nostr.Tag, error) {
 	if event.PubKey == "" {
 		return nil, ErrMissingPubKey
 	}
+	o := newWorkOptions(opts)
+	if _, err := o.id(&event); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -106,7 +195,7 @@ func DoWork(ctx context.Context, event nostr.Event, targetDifficulty int) (nostr
 				for n := 0; n < 10000; n++ {
 					tag[1] = strconv.FormatUint(nonce, 10)
 
-					if difficultyBytes(sha256.Sum256(event.Serialize())) >= targetDifficulty {
+					if id, err := o.id(&event); err == nil && difficultyBytes(id) >= targetDifficulty {
 						// must select{} here otherwise a goroutine that finds a good nonce
 						// right after the first will get stuck in the ch forever
 						select {
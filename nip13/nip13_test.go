@@ -129,3 +129,42 @@ func TestDoWorkTimeout(t *testing.T) {
 		}
 	}
 }
+
+func TestDoWorkSchemes(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	address, _ := nostr.GetAddress(sk)
+	pubkey, _ := nostr.GetPublicKey(sk)
+	domain := nostr.NewEIP712Domain("Test", "1", 1, "")
+
+	for _, tc := range []struct {
+		name   string
+		pubkey string
+		opts   []WorkOption
+		sign   func(evt *nostr.Event) error
+	}{
+		{"eip191", address, nil, func(evt *nostr.Event) error { return evt.Sign(sk) }},
+		{"schnorr", pubkey, nil, func(evt *nostr.Event) error { return evt.Sign(sk, nostr.WithScheme(nostr.SchemeSchnorr)) }},
+		{"eip712", address, []WorkOption{WithIDFunc(EIP712ID(domain))}, func(evt *nostr.Event) error { return evt.Sign_eip712(sk, domain) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event := nostr.Event{
+				Kind:      nostr.KindTextNote,
+				CreatedAt: 1712345678,
+				Content:   "It's just me mining my own business",
+				PubKey:    tc.pubkey,
+			}
+			pow, err := DoWork(context.Background(), event, 8, tc.opts...)
+			require.NoError(t, err)
+			event.Tags = append(event.Tags, pow)
+			require.NoError(t, tc.sign(&event))
+
+			require.GreaterOrEqual(t, Difficulty(event.ID), 8)
+			require.NoError(t, Verify(&event, 8, tc.opts...))
+			require.ErrorIs(t, Verify(&event, 9, tc.opts...), ErrDifficultyTooLow)
+
+			forged := event
+			forged.Content += "!"
+			require.ErrorIs(t, Verify(&forged, 8, tc.opts...), ErrIDMismatch)
+		})
+	}
+}
@@ -46,7 +46,39 @@ func PublishMessage(
 	if err != nil {
 		return fmt.Errorf("failed to prepare message: %w", err)
 	}
+	return publishMessage(ctx, pool, ourRelays, theirRelays, kr, toUs, toThem)
+}
 
+// PublishMessageTo is like PublishMessage, but we or the recipient can be identified by an
+// address, see PrepareMessageTo.
+func PublishMessageTo(
+	ctx context.Context,
+	content string,
+	tags nostr.Tags,
+	pool *nostr.SimplePool,
+	ourRelays []string,
+	theirRelays []string,
+	kr nostr.Keyer,
+	resolver nostr.EncryptionKeyResolver,
+	recipient string,
+	modify func(*nostr.Event),
+) error {
+	toUs, toThem, err := PrepareMessageTo(ctx, content, tags, kr, resolver, recipient, modify)
+	if err != nil {
+		return fmt.Errorf("failed to prepare message: %w", err)
+	}
+	return publishMessage(ctx, pool, ourRelays, theirRelays, kr, toUs, toThem)
+}
+
+func publishMessage(
+	ctx context.Context,
+	pool *nostr.SimplePool,
+	ourRelays []string,
+	theirRelays []string,
+	kr nostr.Keyer,
+	toUs nostr.Event,
+	toThem nostr.Event,
+) error {
 	sendErr := fmt.Errorf("failed to send event to ourselves in any of %v", ourRelays)
 	publishOrAuth := func(ctx context.Context, url string, event nostr.Event) {
 		r, err := pool.EnsureRelay(url)
@@ -94,6 +126,20 @@ func PrepareMessage(
 	kr nostr.Keyer,
 	recipientPubKey string,
 	modify func(*nostr.Event),
+) (toUs nostr.Event, toThem nostr.Event, err error) {
+	return PrepareMessageTo(ctx, content, tags, kr, pubKeysAsIs{}, recipientPubKey, modify)
+}
+
+// PrepareMessageTo is like PrepareMessage, but we or the recipient can be identified by an
+// address: messages are tagged with the address and encrypted to the key the resolver finds for it.
+func PrepareMessageTo(
+	ctx context.Context,
+	content string,
+	tags nostr.Tags,
+	kr nostr.Keyer,
+	resolver nostr.EncryptionKeyResolver,
+	recipient string,
+	modify func(*nostr.Event),
 ) (toUs nostr.Event, toThem nostr.Event, err error) {
 	ourPubkey, err := kr.GetPublicKey(ctx)
 	if err != nil {
@@ -103,30 +149,30 @@ func PrepareMessage(
 	rumor := nostr.Event{
 		Kind:      nostr.KindDirectMessage,
 		Content:   content,
-		Tags:      append(tags, nostr.Tag{"p", recipientPubKey}),
+		Tags:      append(tags, nostr.Tag{"p", recipient}),
 		CreatedAt: nostr.Now(),
 		PubKey:    ourPubkey,
 	}
 	rumor.ID = rumor.GetID()
 
-	toUs, err = nip59.GiftWrap(
-		rumor,
-		ourPubkey,
-		func(s string) (string, error) { return kr.Encrypt(ctx, s, ourPubkey) },
-		func(e *nostr.Event) error { return kr.SignEvent(ctx, e) },
-		modify,
-	)
+	wrap := func(to string) (nostr.Event, error) {
+		return nip59.GiftWrapTo(
+			ctx,
+			resolver,
+			rumor,
+			to,
+			func(s string, key string) (string, error) { return kr.Encrypt(ctx, s, key) },
+			func(e *nostr.Event) error { return kr.SignEvent(ctx, e) },
+			modify,
+		)
+	}
+
+	toUs, err = wrap(ourPubkey)
 	if err != nil {
 		return nostr.Event{}, nostr.Event{}, err
 	}
 
-	toThem, err = nip59.GiftWrap(
-		rumor,
-		recipientPubKey,
-		func(s string) (string, error) { return kr.Encrypt(ctx, s, recipientPubKey) },
-		func(e *nostr.Event) error { return kr.SignEvent(ctx, e) },
-		modify,
-	)
+	toThem, err = wrap(recipient)
 	if err != nil {
 		return nostr.Event{}, nostr.Event{}, err
 	}
@@ -134,6 +180,13 @@ func PrepareMessage(
 	return toUs, toThem, nil
 }
 
+// pubKeysAsIs encrypts to pubkeys directly, which works when they are x-only keys
+type pubKeysAsIs struct{}
+
+func (pubKeysAsIs) ResolveEncryptionKey(ctx context.Context, pubkey string) (string, error) {
+	return pubkey, nil
+}
+
 // ListenForMessages returns a channel with the rumors already decrypted and checked
 func ListenForMessages(
 	ctx context.Context,
@@ -2,6 +2,11 @@ package nip19
 
 import (
 	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
 )
 
 const (
@@ -32,3 +37,31 @@ func writeTLVEntry(buf *bytes.Buffer, typ uint8, value []byte) {
 	buf.WriteByte(uint8(length))
 	buf.Write(value)
 }
+
+// decodeKey turns a 32 byte public key into lowercase hex and a 20 byte address
+// into its EIP-55 checksummed form, as they appear in event pubkeys
+func decodeKey(v []byte) (string, error) {
+	switch len(v) {
+	case 32:
+		return hex.EncodeToString(v), nil
+	case 20:
+		return nostr.ChecksumAddress(hex.EncodeToString(v))
+	default:
+		return "", fmt.Errorf("pubkey should be 32 or 20 bytes (%d)", len(v))
+	}
+}
+
+// encodeKey accepts a hex public key or an address, with or without 0x prefix
+func encodeKey(pk string) ([]byte, error) {
+	if nostr.IsValidAddress(pk) {
+		return hex.DecodeString(strings.TrimPrefix(pk, "0x"))
+	}
+	b, err := hex.DecodeString(pk)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 32 {
+		return nil, fmt.Errorf("pubkey should be 32 or 20 bytes (%d)", len(b))
+	}
+	return b, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/btcsuite/btcd/btcutil/bech32"
 	"github.com/nbd-wtf/go-nostr"
@@ -28,6 +29,13 @@ func Decode(bech32string string) (prefix string, value any, err error) {
 		}
 
 		return prefix, hex.EncodeToString(data[0:32]), nil
+	case "neth":
+		if len(data) != 20 {
+			return prefix, nil, fmt.Errorf("data should be 20 bytes (%d)", len(data))
+		}
+
+		address, err := nostr.ChecksumAddress(hex.EncodeToString(data))
+		return prefix, address, err
 	case "nprofile":
 		var result nostr.ProfilePointer
 		curr := 0
@@ -44,10 +52,11 @@ func Decode(bech32string string) (prefix string, value any, err error) {
 
 			switch t {
 			case TLVDefault:
-				if len(v) != 32 {
-					return prefix, nil, fmt.Errorf("pubkey should be 32 bytes (%d)", len(v))
+				pubkey, err := decodeKey(v)
+				if err != nil {
+					return prefix, nil, err
 				}
-				result.PublicKey = hex.EncodeToString(v)
+				result.PublicKey = pubkey
 			case TLVRelay:
 				result.Relays = append(result.Relays, string(v))
 			default:
@@ -79,10 +88,11 @@ func Decode(bech32string string) (prefix string, value any, err error) {
 			case TLVRelay:
 				result.Relays = append(result.Relays, string(v))
 			case TLVAuthor:
-				if len(v) != 32 {
-					return prefix, nil, fmt.Errorf("author should be 32 bytes (%d)", len(v))
+				author, err := decodeKey(v)
+				if err != nil {
+					return prefix, nil, err
 				}
-				result.Author = hex.EncodeToString(v)
+				result.Author = author
 			case TLVKind:
 				if len(v) != 4 {
 					return prefix, nil, fmt.Errorf("invalid uint32 value for integer (%v)", v)
@@ -114,10 +124,11 @@ func Decode(bech32string string) (prefix string, value any, err error) {
 			case TLVRelay:
 				result.Relays = append(result.Relays, string(v))
 			case TLVAuthor:
-				if len(v) != 32 {
-					return prefix, nil, fmt.Errorf("author should be 32 bytes (%d)", len(v))
+				author, err := decodeKey(v)
+				if err != nil {
+					return prefix, nil, err
 				}
-				result.PublicKey = hex.EncodeToString(v)
+				result.PublicKey = author
 			case TLVKind:
 				result.Kind = int(binary.BigEndian.Uint32(v))
 			default:
@@ -159,6 +170,21 @@ func EncodePublicKey(publicKeyHex string) (string, error) {
 	return bech32.Encode("npub", bits5)
 }
 
+// EncodeAddress encodes a 20 byte address identity as "neth"
+func EncodeAddress(address string) (string, error) {
+	if !nostr.IsValidAddress(address) {
+		return "", fmt.Errorf("invalid address '%s'", address)
+	}
+	b, _ := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+
+	bits5, err := bech32.ConvertBits(b, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	return bech32.Encode("neth", bits5)
+}
+
 func EncodeNote(eventIDHex string) (string, error) {
 	b, err := hex.DecodeString(eventIDHex)
 	if err != nil {
@@ -175,7 +201,7 @@ func EncodeNote(eventIDHex string) (string, error) {
 
 func EncodeProfile(publicKeyHex string, relays []string) (string, error) {
 	buf := &bytes.Buffer{}
-	pubkey, err := hex.DecodeString(publicKeyHex)
+	pubkey, err := encodeKey(publicKeyHex)
 	if err != nil {
 		return "", fmt.Errorf("invalid pubkey '%s': %w", publicKeyHex, err)
 	}
@@ -205,7 +231,7 @@ func EncodeEvent(eventIDHex string, relays []string, author string) (string, err
 		writeTLVEntry(buf, TLVRelay, []byte(url))
 	}
 
-	if pubkey, _ := hex.DecodeString(author); len(pubkey) == 32 {
+	if pubkey, err := encodeKey(author); err == nil {
 		writeTLVEntry(buf, TLVAuthor, pubkey)
 	}
 
@@ -226,9 +252,9 @@ func EncodeEntity(publicKey string, kind int, identifier string, relays []string
 		writeTLVEntry(buf, TLVRelay, []byte(url))
 	}
 
-	pubkey, err := hex.DecodeString(publicKey)
+	pubkey, err := encodeKey(publicKey)
 	if err != nil {
-		return "", fmt.Errorf("invalid pubkey '%s': %w", pubkey, err)
+		return "", fmt.Errorf("invalid pubkey '%s': %w", publicKey, err)
 	}
 	writeTLVEntry(buf, TLVAuthor, pubkey)
 
@@ -145,3 +145,53 @@ func TestFailDecodeBadlyFormattedPubkey(t *testing.T) {
 	_, _, err := Decode("nevent1qqsgaj0la08u0vl2ecmlmrg4xl0vjcz647yx7jgvgzfr566ael4hmjgpp4mhxue69uhhjctzw5hx6egzgqurswpc8qurswpexq6rjvm9xp3nvcfkv56xzv35v9jnxve389snqephve3n2wf4vdsnxepcv56kxct9xyunjdf5v5cnzveexqcrsepnk6yu5r")
 	require.Error(t, err, "should fail to decode this because the author is hex as bytes garbage")
 }
+
+func TestEncodeDecodeAddress(t *testing.T) {
+	neth, err := EncodeAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	assert.NoError(t, err)
+	assert.True(t, len(neth) > 5 && neth[:5] == "neth1")
+
+	prefix, address, err := Decode(neth)
+	assert.NoError(t, err)
+	assert.Equal(t, "neth", prefix)
+	assert.Equal(t, "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", address)
+
+	pointer, err := ToPointer(neth)
+	assert.NoError(t, err)
+	assert.Equal(t, nostr.ProfilePointer{PublicKey: "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}, pointer)
+	assert.Equal(t, neth, EncodePointer(pointer))
+
+	_, err = EncodeAddress("5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	assert.Error(t, err, "bad checksum")
+
+	// an npub can't carry an address
+	npub, err := EncodePublicKey("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	assert.NoError(t, err)
+	_, _, err = Decode(npub)
+	assert.Error(t, err)
+}
+
+func TestEncodeDecodeAddressPointers(t *testing.T) {
+	address := "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+	nprofile, err := EncodeProfile(address, []string{"wss://relay.example.com"})
+	assert.NoError(t, err)
+	_, res, err := Decode(nprofile)
+	assert.NoError(t, err)
+	assert.Equal(t, nostr.ProfilePointer{PublicKey: address, Relays: []string{"wss://relay.example.com"}}, res)
+
+	nevent, err := EncodeEvent("45326f5d6962ab1e3cd424e758c3002b8665f7b0d8dcee9fe9e288d7751ac194", nil, "0x"+address)
+	assert.NoError(t, err)
+	_, res, err = Decode(nevent)
+	assert.NoError(t, err)
+	assert.Equal(t, address, res.(nostr.EventPointer).Author)
+
+	naddr, err := EncodeEntity(address, 30023, "banana", nil)
+	assert.NoError(t, err)
+	_, res, err = Decode(naddr)
+	assert.NoError(t, err)
+	assert.Equal(t, address, res.(nostr.EntityPointer).PublicKey)
+
+	_, err = EncodeProfile("abcd", nil)
+	assert.Error(t, err)
+}
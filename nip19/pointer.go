@@ -9,7 +9,10 @@ import (
 func EncodePointer(pointer nostr.Pointer) string {
 	switch v := pointer.(type) {
 	case nostr.ProfilePointer:
-		if v.Relays == nil {
+		if v.Relays == nil && nostr.IsValidAddress(v.PublicKey) {
+			res, _ := EncodeAddress(v.PublicKey)
+			return res
+		} else if v.Relays == nil {
 			res, _ := EncodePublicKey(v.PublicKey)
 			return res
 		} else {
@@ -17,7 +20,10 @@ func EncodePointer(pointer nostr.Pointer) string {
 			return res
 		}
 	case *nostr.ProfilePointer:
-		if v.Relays == nil {
+		if v.Relays == nil && nostr.IsValidAddress(v.PublicKey) {
+			res, _ := EncodeAddress(v.PublicKey)
+			return res
+		} else if v.Relays == nil {
 			res, _ := EncodePublicKey(v.PublicKey)
 			return res
 		} else {
@@ -47,7 +53,7 @@ func ToPointer(code string) (nostr.Pointer, error) {
 	}
 
 	switch prefix {
-	case "npub":
+	case "npub", "neth":
 		return nostr.ProfilePointer{PublicKey: data.(string)}, nil
 	case "nprofile":
 		return data.(nostr.ProfilePointer), nil
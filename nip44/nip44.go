@@ -2,6 +2,7 @@ package nip44
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/nbd-wtf/go-nostr"
 	"golang.org/x/crypto/chacha20"
 	"golang.org/x/crypto/hkdf"
 )
@@ -171,6 +173,16 @@ func GenerateConversationKey(pub string, sk string) ([32]byte, error) {
 	return ck, nil
 }
 
+// GenerateConversationKeyFor is like GenerateConversationKey, but pub can also be an address,
+// in which case the key it encrypts with is found by the resolver.
+func GenerateConversationKeyFor(ctx context.Context, resolver nostr.EncryptionKeyResolver, pub string, sk string) ([32]byte, error) {
+	key, err := resolver.ResolveEncryptionKey(ctx, pub)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return GenerateConversationKey(key, sk)
+}
+
 func chacha(key []byte, nonce []byte, message []byte) ([]byte, error) {
 	cipher, err := chacha20.NewUnauthenticatedCipher(key, nonce)
 	if err != nil {
@@ -251,7 +251,7 @@ func (bunker *BunkerClient) RPC(ctx context.Context, method string, params []str
 		Kind:      nostr.KindNostrConnect,
 		Tags:      nostr.Tags{{"p", bunker.target}},
 	}
-	if err := evt.Sign(bunker.clientSecretKey); err != nil {
+	if err := evt.Sign(bunker.clientSecretKey, nostr.WithScheme(nostr.SchemeSchnorr)); err != nil {
 		return "", fmt.Errorf("failed to sign request event: %w", err)
 	}
 
@@ -237,7 +237,7 @@ func (p *DynamicSigner) HandleRequest(ctx context.Context, event *nostr.Event) (
 		return req, resp, eventResponse, err
 	}
 
-	err = eventResponse.Sign(handlerSecret)
+	err = eventResponse.Sign(handlerSecret, nostr.WithScheme(nostr.SchemeSchnorr))
 	if err != nil {
 		return req, resp, eventResponse, err
 	}
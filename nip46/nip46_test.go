@@ -1,9 +1,13 @@
 package nip46
 
 import (
+	"context"
 	"testing"
 
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip44"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidBunkerURL(t *testing.T) {
@@ -22,3 +26,39 @@ func TestValidBunkerURL(t *testing.T) {
 	inValid3 := IsValidBunkerURL("bunker://fa883d107ef9e558472c4eb9aaaefa459d?relay=wss%3A%2F%2Frelay.damus.io&relay=wss%3A%2F%2Frelay.snort.social&relay=wss%3A%2F%2Frelay.nsecbunker.com")
 	assert.False(t, inValid3, "should be invalid")
 }
+
+func TestStaticKeySignerPublicKey(t *testing.T) {
+	ctx := context.Background()
+	signerSecretKey := nostr.GeneratePrivateKey()
+	signerPublicKey, _ := nostr.GetPublicKey(signerSecretKey)
+	signer := NewStaticKeySigner(signerSecretKey)
+	clientSecretKey := nostr.GeneratePrivateKey()
+	clientPublicKey, _ := nostr.GetPublicKey(clientSecretKey)
+	ck, err := nip44.GenerateConversationKey(signerPublicKey, clientSecretKey)
+	require.NoError(t, err)
+
+	call := func(method string, params ...string) string {
+		req, _ := json.Marshal(Request{ID: method, Method: method, Params: params})
+		content, err := nip44.Encrypt(string(req), ck)
+		require.NoError(t, err)
+		evt := nostr.Event{Kind: nostr.KindNostrConnect, CreatedAt: nostr.Now(), Content: content}
+		require.NoError(t, evt.Sign(clientSecretKey, nostr.WithScheme(nostr.SchemeSchnorr)))
+		require.Equal(t, clientPublicKey, evt.PubKey)
+
+		_, resp, eventResponse, err := signer.HandleRequest(ctx, &evt)
+		require.NoError(t, err)
+		require.Empty(t, resp.Error)
+		// the client decrypts responses with the pubkey of the response event
+		require.Equal(t, signerPublicKey, eventResponse.PubKey)
+		return resp.Result
+	}
+
+	pubkey := call("get_public_key")
+	signed := call("sign_event", `{"kind":1,"created_at":1712345678,"tags":[],"content":"hello"}`)
+	var evt nostr.Event
+	require.NoError(t, json.Unmarshal([]byte(signed), &evt))
+	require.Equal(t, pubkey, evt.PubKey)
+	ok, err := evt.CheckSignature()
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -129,7 +129,7 @@ func (p *StaticKeySigner) HandleRequest(_ context.Context, event *nostr.Event) (
 			resultErr = fmt.Errorf("failed to decode event/2: %w", err)
 			break
 		}
-		err = evt.Sign(p.secretKey)
+		err = evt.Sign(p.secretKey, nostr.WithScheme(nostr.SchemeSchnorr))
 		if err != nil {
 			resultErr = fmt.Errorf("failed to sign event: %w", err)
 			break
@@ -251,7 +251,7 @@ func (p *StaticKeySigner) HandleRequest(_ context.Context, event *nostr.Event) (
 		return req, resp, eventResponse, err
 	}
 
-	err = eventResponse.Sign(p.secretKey)
+	err = eventResponse.Sign(p.secretKey, nostr.WithScheme(nostr.SchemeSchnorr))
 	if err != nil {
 		return req, resp, eventResponse, err
 	}
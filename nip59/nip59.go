@@ -1,6 +1,7 @@
 package nip59
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 
@@ -17,6 +18,43 @@ func GiftWrap(
 	encrypt func(plaintext string) (string, error),
 	sign func(*nostr.Event) error,
 	modify func(*nostr.Event),
+) (nostr.Event, error) {
+	return giftWrap(rumor, recipient, recipient, encrypt, sign, modify)
+}
+
+// GiftWrapTo is like GiftWrap, but the recipient can also be an address: the gift-wrap is tagged
+// with it and encrypted to the key the resolver finds for it. The encrypt function is called with
+// that key, so it must encrypt the seal to it as well.
+func GiftWrapTo(
+	ctx context.Context,
+	resolver nostr.EncryptionKeyResolver,
+	rumor nostr.Event,
+	recipient string,
+	encrypt func(plaintext string, recipientKey string) (string, error),
+	sign func(*nostr.Event) error,
+	modify func(*nostr.Event),
+) (nostr.Event, error) {
+	recipientKey, err := resolver.ResolveEncryptionKey(ctx, recipient)
+	if err != nil {
+		return nostr.Event{}, err
+	}
+	return giftWrap(
+		rumor,
+		recipient,
+		recipientKey,
+		func(plaintext string) (string, error) { return encrypt(plaintext, recipientKey) },
+		sign,
+		modify,
+	)
+}
+
+func giftWrap(
+	rumor nostr.Event,
+	recipient string,
+	recipientKey string,
+	encrypt func(plaintext string) (string, error),
+	sign func(*nostr.Event) error,
+	modify func(*nostr.Event),
 ) (nostr.Event, error) {
 	rumor.Sig = ""
 
@@ -36,7 +74,7 @@ func GiftWrap(
 	}
 
 	nonceKey := nostr.GeneratePrivateKey()
-	temporaryConversationKey, err := nip44.GenerateConversationKey(recipient, nonceKey)
+	temporaryConversationKey, err := nip44.GenerateConversationKey(recipientKey, nonceKey)
 	if err != nil {
 		return nostr.Event{}, err
 	}
@@ -57,18 +95,25 @@ func GiftWrap(
 	if modify != nil {
 		modify(&gw)
 	}
-	if err := gw.Sign(nonceKey); err != nil {
+	if err := gw.Sign(nonceKey, nostr.WithScheme(nostr.SchemeSchnorr)); err != nil {
 		return nostr.Event{}, err
 	}
 
 	return gw, nil
 }
 
+// GiftUnwrap decrypts a gift-wrap and its seal, yielding the rumor.
+// The decrypt function is called with the public keys that signed them, which are recovered
+// from their signatures when they are signed by addresses.
 func GiftUnwrap(
 	gw nostr.Event,
 	decrypt func(otherpubkey, ciphertext string) (string, error),
 ) (rumor nostr.Event, err error) {
-	jseal, err := decrypt(gw.PubKey, gw.Content)
+	gwKey, err := encryptionKey(gw)
+	if err != nil {
+		return rumor, fmt.Errorf("gift-wrap signature is invalid: %w", err)
+	}
+	jseal, err := decrypt(gwKey, gw.Content)
 	if err != nil {
 		return rumor, fmt.Errorf("failed to decrypt seal: %w", err)
 	}
@@ -79,11 +124,12 @@ func GiftUnwrap(
 		return rumor, fmt.Errorf("seal is invalid json: %w", err)
 	}
 
-	if ok, _ := seal.CheckSignature(); !ok {
-		return rumor, fmt.Errorf("seal signature is invalid")
+	sealKey, err := encryptionKey(seal)
+	if err != nil {
+		return rumor, fmt.Errorf("seal signature is invalid: %w", err)
 	}
 
-	jrumor, err := decrypt(seal.PubKey, seal.Content)
+	jrumor, err := decrypt(sealKey, seal.Content)
 	if err != nil {
 		return rumor, fmt.Errorf("failed to decrypt rumor: %w", err)
 	}
@@ -98,3 +144,12 @@ func GiftUnwrap(
 
 	return rumor, nil
 }
+
+// encryptionKey checks the signature of evt and returns the x-only key that made it
+func encryptionKey(evt nostr.Event) (string, error) {
+	key, err := evt.RecoverPublicKey()
+	if err != nil {
+		return "", err
+	}
+	return key[2:], nil
+}
@@ -124,7 +124,9 @@ func generateAuthHeader(sk, host string, fileHash hash.Hash) (string, error) {
 	if fileHash != nil {
 		event.Tags = append(event.Tags, nostr.Tag{"payload", hex.EncodeToString(fileHash.Sum(nil))})
 	}
-	event.Sign(sk)
+	if err := event.Sign(sk, nostr.WithScheme(nostr.SchemeSchnorr)); err != nil {
+		return "", fmt.Errorf("event.Sign: %w", err)
+	}
 
 	b, err := jsoniter.ConfigFastest.Marshal(event)
 	if err != nil {
@@ -36,6 +36,7 @@ type SimplePool struct {
 	penaltyBoxMu sync.Mutex
 	penaltyBox   map[string][2]float64
 	relayOptions []RelayOption
+	reconnect    RelayOption // see WithReconnect
 }
 
 // DirectedFilter combines a Filter with a specific relay URL.
@@ -158,10 +159,13 @@ var (
 	_ PoolOption = (WithEventMiddleware)(nil)
 	_ PoolOption = WithPenaltyBox()
 	_ PoolOption = WithRelayOptions(WithRequestHeader(http.Header{}))
+	_ PoolOption = WithReconnect(ReconnectPolicy{})
 )
 
 // EnsureRelay ensures that a relay connection exists and is active.
 // If the relay is not connected, it attempts to connect.
+// With WithReconnect a relay that is reconnecting is returned as it is, so the long-lived
+// subscriptions on it get resumed instead of being opened again on a new relay.
 func (pool *SimplePool) EnsureRelay(url string) (*Relay, error) {
 	nm := NormalizeURL(url)
 	defer namedLock(nm)()
@@ -176,8 +180,8 @@ func (pool *SimplePool) EnsureRelay(url string) (*Relay, error) {
 				return nil, fmt.Errorf("in penalty box, %fs remaining", v[1])
 			}
 		}
-	} else if ok && relay.IsConnected() {
-		// already connected, unlock and return
+	} else if ok && (relay.IsConnected() || relay.reconnecting.Load()) {
+		// already connected (or about to be again, with its subscriptions), unlock and return
 		return relay, nil
 	}
 
@@ -190,7 +194,11 @@ func (pool *SimplePool) EnsureRelay(url string) (*Relay, error) {
 	)
 	defer cancel()
 
-	relay = NewRelay(context.Background(), url, pool.relayOptions...)
+	opts := pool.relayOptions
+	if pool.reconnect != nil {
+		opts = append(slices.Clip(opts), pool.reconnect)
+	}
+	relay = NewRelay(context.Background(), url, opts...)
 	if err := relay.Connect(ctx); err != nil {
 		if pool.penaltyBox != nil {
 			// putting relay in penalty box
@@ -0,0 +1,169 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ConnectionState is a state of a relay connection, see WithConnectionStateHandler.
+type ConnectionState int
+
+const (
+	StateConnected    ConnectionState = iota + 1 // the websocket is open
+	StateDisconnected                            // the connection was lost or a reconnection attempt failed
+	StateReconnecting                            // a reconnection attempt is starting
+	StateClosed                                  // the relay won't connect again and its subscriptions are closed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("ConnectionState(%d)", int(s))
+	}
+}
+
+// WithConnectionStateHandler is called whenever the connection to the relay changes state,
+// with the error that caused it when there is one. It must not block.
+type WithConnectionStateHandler func(state ConnectionState, err error)
+
+func (sh WithConnectionStateHandler) ApplyRelayOption(r *Relay) {
+	r.stateHandler = sh
+}
+
+func (r *Relay) setState(state ConnectionState, err error) {
+	if r.stateHandler != nil {
+		r.stateHandler(state, err)
+	}
+}
+
+// ReconnectPolicy tells how a relay reconnects after losing its connection, see WithReconnect.
+type ReconnectPolicy struct {
+	// MinBackoff is the wait before the first attempt, doubled after every failed attempt up to
+	// MaxBackoff. They default to 1 second and 5 minutes. Each wait is randomly shortened by up
+	// to a half so that clients dropped together don't all come back at the same time.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxAttempts is the number of failed attempts in a row after which the relay gives up and
+	// closes its subscriptions, 0 means it never gives up.
+	MaxAttempts int
+}
+
+func (p ReconnectPolicy) backoff(attempt int) time.Duration {
+	wait := p.MinBackoff
+	for i := 0; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, p.MaxBackoff)
+	return wait - rand.N(wait/2+1)
+}
+
+// WithReconnect makes the relay reconnect when its connection is lost instead of closing.
+// Open subscriptions survive the gap: once reconnected they are sent again with `since` set to
+// the newest event each of them received, and events received twice are skipped.
+// Calling Close() or canceling the context given to NewRelay() stops reconnecting.
+//
+// It can also be given to NewSimplePool(), in which case EnsureRelay() keeps returning the same
+// relay while it reconnects.
+func WithReconnect(policy ReconnectPolicy) withReconnectOpt {
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = time.Second
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 5 * time.Minute
+	}
+	policy.MaxBackoff = max(policy.MaxBackoff, policy.MinBackoff)
+	return withReconnectOpt(policy)
+}
+
+type withReconnectOpt ReconnectPolicy
+
+func (o withReconnectOpt) ApplyRelayOption(r *Relay) {
+	policy := ReconnectPolicy(o)
+	r.reconnectPolicy = &policy
+}
+
+func (o withReconnectOpt) ApplyPoolOption(pool *SimplePool) {
+	pool.reconnect = o
+}
+
+// reconnect dials the relay again and again, waiting more each time, until it succeeds, the relay
+// is closed or the policy gives up. Once connected the open subscriptions are resumed.
+func (r *Relay) reconnect(cause error) {
+	r.reconnecting.Store(true)
+	defer r.reconnecting.Store(false)
+
+	r.setState(StateDisconnected, cause)
+
+	policy := r.reconnectPolicy
+	for attempt := 0; policy.MaxAttempts == 0 || attempt < policy.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-r.ctx.Done():
+			r.abandon(fmt.Errorf("relay connection closed: %w", context.Cause(r.ctx)))
+			return
+		}
+
+		r.setState(StateReconnecting, nil)
+		ctx, cancel := context.WithTimeoutCause(r.ctx, 7*time.Second, errors.New("connection took too long"))
+		conn, err := NewConnection(ctx, r.URL, r.requestHeader, r.tlsConfig)
+		cancel()
+		if err != nil {
+			cause = fmt.Errorf("error opening websocket to '%s': %w", r.URL, err)
+			r.setState(StateDisconnected, cause)
+			continue
+		}
+
+		// if the relay gets closed from now on this connection will be closed with it
+		connCtx, connCancel := context.WithCancelCause(r.ctx)
+		r.closeMutex.Lock()
+		r.Connection = conn
+		r.connectionContext = connCtx
+		r.connectionContextCancel = connCancel
+		r.ConnectionError = nil
+		r.closeMutex.Unlock()
+
+		r.serve(connCtx, connCancel, conn)
+		r.setState(StateConnected, nil)
+		r.resubscribe()
+		return
+	}
+
+	r.abandon(fmt.Errorf("gave up reconnecting after %d attempts: %w", policy.MaxAttempts, cause))
+}
+
+// resubscribe sends the REQs of the open subscriptions again, each starting from the newest
+// event it has received.
+func (r *Relay) resubscribe() {
+	for _, sub := range r.Subscriptions.Range {
+		if sub.countResult != nil || sub.resume == nil || !sub.live.Load() {
+			continue
+		}
+
+		sub.Filters = sub.resume.filters(sub.Filters)
+		reqb, _ := ReqEnvelope{sub.id, sub.Filters}.MarshalJSON()
+		if err := <-r.Write(reqb); err != nil {
+			// the connection was lost again, this will be retried on the next one
+			debugLogf("{%s} failed to resume subscription %s: %v\n", r.URL, sub.id, err)
+		}
+	}
+}
+
+// abandon closes all the subscriptions once the relay won't connect anymore.
+func (r *Relay) abandon(cause error) {
+	for _, sub := range r.Subscriptions.Range {
+		sub.unsub(cause)
+	}
+	r.setState(StateClosed, cause)
+}
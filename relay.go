@@ -29,16 +29,23 @@ type Relay struct {
 	Connection    *Connection
 	Subscriptions *xsync.MapOf[int64, *Subscription]
 
-	ConnectionError         error
+	ConnectionError         error           // last read error, guarded by closeMutex
 	connectionContext       context.Context // will be canceled when the connection closes
 	connectionContextCancel context.CancelCauseFunc
 
+	ctx       context.Context // will be canceled when the relay is closed for good
+	cancel    context.CancelCauseFunc
+	tlsConfig *tls.Config
+
 	challenge                     string       // NIP-42 challenge, we only keep the last
 	noticeHandler                 func(string) // NIP-01 NOTICEs
 	customHandler                 func(string) // nonstandard unparseable messages
 	okCallbacks                   *xsync.MapOf[string, func(bool, string)]
 	writeQueue                    chan writeRequest
 	subscriptionChannelCloseQueue chan *Subscription
+	stateHandler                  func(ConnectionState, error) // see WithConnectionStateHandler
+	reconnectPolicy               *ReconnectPolicy             // see WithReconnect
+	reconnecting                  atomic.Bool
 
 	// custom things that aren't often used
 	//
@@ -53,10 +60,13 @@ type writeRequest struct {
 // NewRelay returns a new relay. It takes a context that, when canceled, will close the relay connection.
 func NewRelay(ctx context.Context, url string, opts ...RelayOption) *Relay {
 	ctx, cancel := context.WithCancelCause(ctx)
+	connCtx, connCancel := context.WithCancelCause(ctx)
 	r := &Relay{
 		URL:                           NormalizeURL(url),
-		connectionContext:             ctx,
-		connectionContextCancel:       cancel,
+		ctx:                           ctx,
+		cancel:                        cancel,
+		connectionContext:             connCtx,
+		connectionContextCancel:       connCancel,
 		Subscriptions:                 xsync.NewMapOf[int64, *Subscription](),
 		okCallbacks:                   xsync.NewMapOf[string, func(bool, string)](),
 		writeQueue:                    make(chan writeRequest),
@@ -92,6 +102,8 @@ var (
 	_ RelayOption = (WithNoticeHandler)(nil)
 	_ RelayOption = (WithCustomHandler)(nil)
 	_ RelayOption = (WithRequestHeader)(nil)
+	_ RelayOption = (WithConnectionStateHandler)(nil)
+	_ RelayOption = WithReconnect(ReconnectPolicy{})
 )
 
 // WithNoticeHandler just takes notices and is expected to do something with them.
@@ -124,10 +136,16 @@ func (r *Relay) String() string {
 
 // Context retrieves the context that is associated with this relay connection.
 // It will be closed when the relay is disconnected.
-func (r *Relay) Context() context.Context { return r.connectionContext }
+//
+// When reconnecting (see WithReconnect) each connection has its own context.
+func (r *Relay) Context() context.Context {
+	r.closeMutex.Lock()
+	defer r.closeMutex.Unlock()
+	return r.connectionContext
+}
 
 // IsConnected returns true if the connection to this relay seems to be active.
-func (r *Relay) IsConnected() bool { return r.connectionContext.Err() == nil }
+func (r *Relay) IsConnected() bool { return r.Context().Err() == nil }
 
 // Connect tries to establish a websocket connection to r.URL.
 // If the context expires before the connection is complete, an error is returned.
@@ -161,25 +179,64 @@ func (r *Relay) ConnectWithTLS(ctx context.Context, tlsConfig *tls.Config) error
 	if err != nil {
 		return fmt.Errorf("error opening websocket to '%s': %w", r.URL, err)
 	}
+	r.tlsConfig = tlsConfig
+
+	r.closeMutex.Lock()
+	connCtx, connCancel := r.connectionContext, r.connectionContextCancel
+	if connCancel == nil {
+		r.closeMutex.Unlock()
+		conn.Close()
+		return fmt.Errorf("relay already closed")
+	}
 	r.Connection = conn
+	r.closeMutex.Unlock()
 
+	r.serve(connCtx, connCancel, conn)
+	r.setState(StateConnected, nil)
+
+	return nil
+}
+
+// serve runs the reading and writing loops of a websocket connection until connCtx is canceled.
+func (r *Relay) serve(connCtx context.Context, connCancel context.CancelCauseFunc, conn *Connection) {
 	// ping every 29 seconds
 	ticker := time.NewTicker(29 * time.Second)
 
+	// to be used when the connection breaks
+	drop := func(err error) {
+		connCancel(err)
+		conn.Close()
+	}
+
 	// to be used when the connection is closed
 	go func() {
-		<-r.connectionContext.Done()
+		<-connCtx.Done()
 
 		// stop the ticker
 		ticker.Stop()
 
-		// nil the connection
-		r.Connection = nil
+		// nil the connection (unless we already have another one)
+		r.closeMutex.Lock()
+		if r.Connection == conn {
+			r.Connection = nil
+		}
+		r.closeMutex.Unlock()
+
+		// the read error, if any, is the cause of this connection's context
+		cause := fmt.Errorf("relay connection closed: %w", context.Cause(connCtx))
+		if r.reconnectPolicy != nil && r.ctx.Err() == nil {
+			// keep subscriptions around so they can be resumed, except for counts
+			for _, sub := range r.Subscriptions.Range {
+				if sub.countResult != nil {
+					sub.unsub(cause)
+				}
+			}
+			r.reconnect(cause)
+			return
+		}
 
 		// close all subscriptions
-		for _, sub := range r.Subscriptions.Range {
-			sub.unsub(fmt.Errorf("relay connection closed: %w / %w", context.Cause(r.connectionContext), r.ConnectionError))
-		}
+		r.abandon(cause)
 	}()
 
 	// queue all write operations here so we don't do mutex spaghetti
@@ -187,22 +244,20 @@ func (r *Relay) ConnectWithTLS(ctx context.Context, tlsConfig *tls.Config) error
 		for {
 			select {
 			case <-ticker.C:
-				if r.Connection != nil {
-					err := r.Connection.Ping(r.connectionContext)
-					if err != nil && !strings.Contains(err.Error(), "failed to wait for pong") {
-						InfoLogger.Printf("{%s} error writing ping: %v; closing websocket", r.URL, err)
-						r.Close() // this should trigger a context cancelation
-						return
-					}
+				err := conn.Ping(connCtx)
+				if err != nil && !strings.Contains(err.Error(), "failed to wait for pong") {
+					InfoLogger.Printf("{%s} error writing ping: %v; closing websocket", r.URL, err)
+					drop(err) // this should trigger a context cancelation
+					return
 				}
 			case writeRequest := <-r.writeQueue:
 				// all write requests will go through this to prevent races
 				debugLogf("{%s} sending %v\n", r.URL, string(writeRequest.msg))
-				if err := r.Connection.WriteMessage(r.connectionContext, writeRequest.msg); err != nil {
+				if err := conn.WriteMessage(connCtx, writeRequest.msg); err != nil {
 					writeRequest.answer <- err
 				}
 				close(writeRequest.answer)
-			case <-r.connectionContext.Done():
+			case <-connCtx.Done():
 				// stop here
 				return
 			}
@@ -217,9 +272,13 @@ func (r *Relay) ConnectWithTLS(ctx context.Context, tlsConfig *tls.Config) error
 		for {
 			buf.Reset()
 
-			if err := conn.ReadMessage(r.connectionContext, buf); err != nil {
-				r.ConnectionError = err
-				r.close(err)
+			if err := conn.ReadMessage(connCtx, buf); err != nil {
+				r.closeMutex.Lock()
+				if r.Connection == conn {
+					r.ConnectionError = err
+				}
+				r.closeMutex.Unlock()
+				drop(err)
 				break
 			}
 
@@ -278,7 +337,7 @@ func (r *Relay) ConnectWithTLS(ctx context.Context, tlsConfig *tls.Config) error
 						continue
 					}
 
-					// check signature, ignore invalid, except from trusted (AssumeValid) relays
+					// check signature in the scheme the event declares, ignore invalid, except from trusted (AssumeValid) relays
 					if !r.AssumeValid {
 						if ok, _ := env.Event.CheckSignature(); !ok {
 							InfoLogger.Printf("{%s} bad signature on %s\n", r.URL, env.Event.ID)
@@ -286,6 +345,11 @@ func (r *Relay) ConnectWithTLS(ctx context.Context, tlsConfig *tls.Config) error
 						}
 					}
 
+					// skip what we got already before reconnecting
+					if sub.resume != nil && !sub.resume.see(&env.Event) {
+						continue
+					}
+
 					// dispatch this to the internal .events channel of the subscription
 					sub.dispatchEvent(&env.Event)
 				}
@@ -310,8 +374,6 @@ func (r *Relay) ConnectWithTLS(ctx context.Context, tlsConfig *tls.Config) error
 			}
 		}
 	}()
-
-	return nil
 }
 
 // Write queues an arbitrary message to be sent to the relay.
@@ -319,7 +381,7 @@ func (r *Relay) Write(msg []byte) <-chan error {
 	ch := make(chan error)
 	select {
 	case r.writeQueue <- writeRequest{msg: msg, answer: ch}:
-	case <-r.connectionContext.Done():
+	case <-r.Context().Done():
 		go func() { ch <- fmt.Errorf("connection closed") }()
 	}
 	return ch
@@ -382,6 +444,7 @@ func (r *Relay) publish(ctx context.Context, id string, env Envelope) error {
 		return err
 	}
 
+	connCtx := r.Context()
 	for {
 		select {
 		case <-ctx.Done():
@@ -390,7 +453,7 @@ func (r *Relay) publish(ctx context.Context, id string, env Envelope) error {
 				return err
 			}
 			return ctx.Err()
-		case <-r.connectionContext.Done():
+		case <-connCtx.Done():
 			// this is caused when we lose connectivity
 			return err
 		}
@@ -436,6 +499,9 @@ func (r *Relay) PrepareSubscription(ctx context.Context, filters Filters, opts .
 		Filters:           filters,
 		match:             filters.Match,
 	}
+	if r.reconnectPolicy != nil {
+		sub.resume = &resumePoint{}
+	}
 
 	label := ""
 	for _, opt := range opts {
@@ -559,24 +625,27 @@ func (r *Relay) countInternal(ctx context.Context, filters Filters, opts ...Subs
 
 // Close closes the relay connection.
 func (r *Relay) Close() error {
-	return r.close(errors.New("Close() called"))
+	reason := errors.New("Close() called")
+	r.cancel(reason) // this also stops reconnecting
+	return r.close(reason)
 }
 
 func (r *Relay) close(reason error) error {
 	r.closeMutex.Lock()
-	defer r.closeMutex.Unlock()
+	cancel, conn := r.connectionContextCancel, r.Connection
+	r.connectionContextCancel = nil
+	r.closeMutex.Unlock()
 
-	if r.connectionContextCancel == nil {
+	if cancel == nil {
 		return fmt.Errorf("relay already closed")
 	}
-	r.connectionContextCancel(reason)
-	r.connectionContextCancel = nil
+	cancel(reason)
 
-	if r.Connection == nil {
+	if conn == nil {
 		return fmt.Errorf("relay not connected")
 	}
 
-	err := r.Connection.Close()
+	err := conn.Close()
 	if err != nil {
 		return err
 	}
@@ -61,6 +61,54 @@ func TestPublish(t *testing.T) {
 	assert.True(t, published, "fake relay server saw no event")
 }
 
+func TestEIP712EventRoundTrip(t *testing.T) {
+	domain := NewEIP712Domain("DeSci", "1", 137, "")
+	note := Event{Kind: KindTextNote, Content: "signed by a wallet", CreatedAt: Timestamp(1672068534)}
+	require.NoError(t, note.Sign_eip712(eip712TestKey, domain))
+	forged := note
+	forged.Content = "not signed by a wallet"
+	forged.ID = forged.GetID()
+
+	ws := newWebsocketServer(func(conn *websocket.Conn) {
+		for {
+			var raw []stdjson.RawMessage
+			if err := websocket.JSON.Receive(conn, &raw); err != nil {
+				return
+			}
+			var typ string
+			require.NoError(t, json.Unmarshal(raw[0], &typ))
+
+			switch typ {
+			case "EVENT":
+				event := parseEventMessage(t, raw)
+				assert.True(t, event.CheckID())
+				websocket.JSON.Send(conn, []any{"OK", event.ID, true, ""})
+			case "REQ":
+				subid, _ := parseSubscriptionMessage(t, append(raw, raw[2]))
+				websocket.JSON.Send(conn, []any{"EVENT", subid, forged})
+				websocket.JSON.Send(conn, []any{"EVENT", subid, note})
+				websocket.JSON.Send(conn, []any{"EOSE", subid})
+			}
+		}
+	})
+	defer ws.Close()
+
+	pool := NewSimplePool(context.Background())
+	defer pool.Close("done")
+
+	for res := range pool.PublishMany(context.Background(), []string{ws.URL}, note) {
+		require.NoError(t, res.Error)
+	}
+
+	var received []Event
+	for ie := range pool.FetchMany(context.Background(), []string{ws.URL}, Filter{Kinds: []int{KindTextNote}}) {
+		received = append(received, *ie.Event)
+	}
+	require.Len(t, received, 1)
+	assert.Equal(t, note.ID, received[0].ID)
+	assert.Equal(t, SchemeEIP712, received[0].Scheme())
+}
+
 func TestPublishBlocked(t *testing.T) {
 	// test note to be sent over websocket
 	textNote := Event{Kind: KindTextNote, Content: "hello"}
@@ -237,3 +285,201 @@ func parseSubscriptionMessage(t *testing.T, raw []stdjson.RawMessage) (subid str
 	}
 	return id, ff
 }
+
+func TestReconnectResumesSubscription(t *testing.T) {
+	priv, _ := makeKeyPair(t)
+	older := Event{Kind: KindTextNote, Content: "older", CreatedAt: Timestamp(1672068534)}
+	require.NoError(t, older.Sign(priv))
+	newer := Event{Kind: KindTextNote, Content: "newer", CreatedAt: Timestamp(1672068600)}
+	require.NoError(t, newer.Sign(priv))
+
+	var mu sync.Mutex // guards connections and resumedFilters
+	var connections int
+	var resumedFilters []Filter
+	ws := newWebsocketServer(func(conn *websocket.Conn) {
+		var raw []stdjson.RawMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &raw))
+		require.Len(t, raw, 3)
+		var subid string
+		var filter Filter
+		require.NoError(t, json.Unmarshal(raw[1], &subid))
+		require.NoError(t, json.Unmarshal(raw[2], &filter))
+
+		mu.Lock()
+		connections++
+		first := connections == 1
+		if !first {
+			resumedFilters = append(resumedFilters, filter)
+		}
+		mu.Unlock()
+
+		if first {
+			websocket.JSON.Send(conn, []any{"EVENT", subid, older})
+			websocket.JSON.Send(conn, []any{"EOSE", subid})
+			time.Sleep(100 * time.Millisecond)
+			conn.Close() // drop the client
+			return
+		}
+
+		// the relay sends the newest event it had sent before again
+		websocket.JSON.Send(conn, []any{"EVENT", subid, older})
+		websocket.JSON.Send(conn, []any{"EVENT", subid, newer})
+		io.ReadAll(conn)
+	})
+	defer ws.Close()
+
+	states := make(chan ConnectionState, 10)
+	rl := NewRelay(context.Background(), ws.URL,
+		WithReconnect(ReconnectPolicy{MinBackoff: 10 * time.Millisecond}),
+		WithConnectionStateHandler(func(state ConnectionState, err error) { states <- state }),
+	)
+	require.NoError(t, rl.Connect(context.Background()))
+	defer rl.Close()
+
+	sub, err := rl.Subscribe(context.Background(), Filters{{Kinds: []int{KindTextNote}, Limit: 10}})
+	require.NoError(t, err)
+
+	var received []string
+	timeout := time.After(3 * time.Second)
+	for len(received) < 2 {
+		select {
+		case evt, more := <-sub.Events:
+			require.True(t, more, "subscription closed")
+			received = append(received, evt.Content)
+		case <-timeout:
+			t.Fatalf("timeout, got %v", received)
+		}
+	}
+	select {
+	case evt := <-sub.Events:
+		t.Fatalf("unexpected event %v", evt)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, []string{"older", "newer"}, received)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, resumedFilters, 1)
+	require.NotNil(t, resumedFilters[0].Since)
+	assert.Equal(t, older.CreatedAt, *resumedFilters[0].Since)
+	assert.Equal(t, 0, resumedFilters[0].Limit)
+
+	assert.Equal(t, StateConnected, <-states)
+	assert.Equal(t, StateDisconnected, <-states)
+	assert.Equal(t, StateReconnecting, <-states)
+	assert.Equal(t, StateConnected, <-states)
+}
+
+func TestReconnectResumesBeforeEOSE(t *testing.T) {
+	priv, _ := makeKeyPair(t)
+	older := Event{Kind: KindTextNote, Content: "older", CreatedAt: Timestamp(1672068534)}
+	require.NoError(t, older.Sign(priv))
+	newer := Event{Kind: KindTextNote, Content: "newer", CreatedAt: Timestamp(1672068600)}
+	require.NoError(t, newer.Sign(priv))
+
+	var mu sync.Mutex // guards connections and resumedFilters
+	var connections int
+	var resumedFilters []Filter
+	ws := newWebsocketServer(func(conn *websocket.Conn) {
+		var raw []stdjson.RawMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &raw))
+		require.Len(t, raw, 3)
+		var subid string
+		var filter Filter
+		require.NoError(t, json.Unmarshal(raw[1], &subid))
+		require.NoError(t, json.Unmarshal(raw[2], &filter))
+
+		mu.Lock()
+		connections++
+		first := connections == 1
+		if !first {
+			resumedFilters = append(resumedFilters, filter)
+		}
+		mu.Unlock()
+
+		// stored events come newest first, the client is dropped before the older one
+		websocket.JSON.Send(conn, []any{"EVENT", subid, newer})
+		if first {
+			time.Sleep(100 * time.Millisecond)
+			conn.Close()
+			return
+		}
+		websocket.JSON.Send(conn, []any{"EVENT", subid, older})
+		websocket.JSON.Send(conn, []any{"EOSE", subid})
+		io.ReadAll(conn)
+	})
+	defer ws.Close()
+
+	rl := NewRelay(context.Background(), ws.URL, WithReconnect(ReconnectPolicy{MinBackoff: 10 * time.Millisecond}))
+	require.NoError(t, rl.Connect(context.Background()))
+	defer rl.Close()
+
+	sub, err := rl.Subscribe(context.Background(), Filters{{Kinds: []int{KindTextNote}, Limit: 10}})
+	require.NoError(t, err)
+
+	var received []string
+	timeout := time.After(3 * time.Second)
+	for len(received) < 2 {
+		select {
+		case evt, more := <-sub.Events:
+			require.True(t, more, "subscription closed")
+			received = append(received, evt.Content)
+		case <-timeout:
+			t.Fatalf("timeout, got %v", received)
+		}
+	}
+	select {
+	case <-sub.EndOfStoredEvents:
+	case <-timeout:
+		t.Fatal("no EOSE")
+	}
+	select {
+	case evt := <-sub.Events:
+		t.Fatalf("unexpected event %v", evt)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, []string{"newer", "older"}, received)
+
+	// the stored events weren't all received, so the original filter is sent again
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, resumedFilters, 1)
+	assert.Nil(t, resumedFilters[0].Since)
+	assert.Equal(t, 10, resumedFilters[0].Limit)
+}
+
+func TestReconnectGivesUp(t *testing.T) {
+	ws := newWebsocketServer(func(conn *websocket.Conn) {
+		conn.Close()
+	})
+
+	closed := make(chan error, 1)
+	rl := NewRelay(context.Background(), ws.URL,
+		WithReconnect(ReconnectPolicy{MinBackoff: 10 * time.Millisecond, MaxAttempts: 2}),
+		WithConnectionStateHandler(func(state ConnectionState, err error) {
+			if state == StateClosed {
+				closed <- err
+			}
+		}),
+	)
+	require.NoError(t, rl.Connect(context.Background()))
+	sub := rl.PrepareSubscription(context.Background(), Filters{{Kinds: []int{KindTextNote}}})
+	ws.Close() // no coming back
+
+	select {
+	case err := <-closed:
+		assert.ErrorContains(t, err, "gave up reconnecting after 2 attempts")
+	case <-time.After(3 * time.Second):
+		t.Fatal("relay didn't give up")
+	}
+	<-sub.Context.Done()
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	policy := ReconnectPolicy(WithReconnect(ReconnectPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}))
+	for attempt, full := range []time.Duration{1, 2, 4, 8, 10, 10} {
+		wait := policy.backoff(attempt)
+		assert.LessOrEqual(t, wait, full*time.Second)
+		assert.GreaterOrEqual(t, wait, full*time.Second/2)
+	}
+}
@@ -3,75 +3,100 @@
 package nostr
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-    "strings"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/ethereum/go-ethereum/crypto"
-	//"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // CheckSignature checks if the event signature is valid for the given event.
 // It won't look at the ID field, instead it will recompute the id from the entire event body.
+// The scheme is the one declared by the event or detected from the shape of its pubkey and
+// signature, see Event.Scheme.
 // If the signature is invalid bool will be false and err will be set.
 func (evt Event) CheckSignature() (bool, error) {
+	switch evt.Scheme() {
+	case SchemeEIP191:
+		return evt.checkSignatureEIP191()
+	case SchemeSchnorr:
+		return evt.checkSignatureSchnorr()
+	case SchemeEIP712:
+		return evt.CheckSignature_eip712()
+	default:
+		return false, fmt.Errorf("unknown signature scheme for pubkey '%s'", evt.PubKey)
+	}
+}
+
+func (evt *Event) checkSignatureSchnorr() (bool, error) {
 	// read and check pubkey
-    address := "0x" + evt.PubKey
+	pk, err := hex.DecodeString(evt.PubKey)
+	if err != nil {
+		return false, fmt.Errorf("event pubkey '%s' is invalid hex: %w", evt.PubKey, err)
+	}
+	pubkey, err := schnorr.ParsePubKey(pk)
+	if err != nil {
+		return false, fmt.Errorf("event has invalid pubkey '%s': %w", evt.PubKey, err)
+	}
 
 	// read signature
-    //sig, err := hexutil.Decode(evt.Sig)
-    sig, err := hex.DecodeString(evt.Sig)
+	s, err := hex.DecodeString(evt.Sig)
 	if err != nil {
 		return false, fmt.Errorf("signature '%s' is invalid hex: %w", evt.Sig, err)
 	}
-	if sig[64] >= 27 {
-		sig[64] -= 27
-	}
-
-	// check signature
-    message := evt.Serialize()
-    prefixedMessage := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
-	hash := crypto.Keccak256Hash([]byte(prefixedMessage))
-
-    pubKey, err := crypto.SigToPub(hash.Bytes(), sig)
+	sig, err := schnorr.ParseSignature(s)
 	if err != nil {
-        return false, fmt.Errorf("failed to recover public key: %w", err)
+		return false, fmt.Errorf("failed to parse signature: %w", err)
 	}
 
-	recoveredAddr := crypto.PubkeyToAddress(*pubKey).Hex()
-
-	return (recoveredAddr == address), nil
+	// check signature
+	hash := sha256.Sum256(evt.Serialize())
+	return sig.Verify(hash[:], pubkey), nil
 }
 
 // Sign signs an event with a given privateKey.
 // It sets the event's ID, PubKey, and Sig fields.
+// The scheme is DefaultSignatureScheme unless WithScheme is given.
 // Returns an error if the private key is invalid or if signing fails.
-func (evt *Event) Sign(secretKey string) error {
-    s, err := crypto.HexToECDSA(secretKey)
-	if err != nil {
-		return fmt.Errorf("Sign called with invalid secret key '%s': %w", secretKey, err)
-	}
+func (evt *Event) Sign(secretKey string, opts ...SignOption) error {
+	o := newSignOptions(opts)
 
 	if evt.Tags == nil {
 		evt.Tags = make(Tags, 0)
 	}
 
-	evt.PubKey = crypto.PubkeyToAddress(s.PublicKey).Hex()
-    evt.PubKey = strings.TrimPrefix(crypto.PubkeyToAddress(s.PublicKey).Hex(), "0x")
-
-
-    message := evt.Serialize()
-	prefixedMessage := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
-	h:= crypto.Keccak256Hash([]byte(prefixedMessage))
-
-    sig, err := crypto.Sign(h.Bytes(), s)
-	if err != nil {
-        return fmt.Errorf("failed to sign: %w", err)
+	switch o.scheme {
+	case SchemeEIP191:
+		s, err := crypto.HexToECDSA(secretKey)
+		if err != nil {
+			return fmt.Errorf("Sign called with invalid secret key '%s': %w", secretKey, err)
+		}
+		return evt.signEIP191(s)
+
+	case SchemeSchnorr:
+		s, err := hex.DecodeString(secretKey)
+		if err != nil || len(s) != 32 {
+			return fmt.Errorf("Sign called with invalid secret key '%s'", secretKey)
+		}
+		sk, pk := btcec.PrivKeyFromBytes(s)
+		evt.PubKey = hex.EncodeToString(schnorr.SerializePubKey(pk))
+
+		h := sha256.Sum256(evt.Serialize())
+		sig, err := schnorr.Sign(sk, h[:])
+		if err != nil {
+			return fmt.Errorf("failed to sign: %w", err)
+		}
+
+		evt.ID = hex.EncodeToString(h[:])
+		evt.Sig = hex.EncodeToString(sig.Serialize())
+		return nil
+
+	case SchemeEIP712:
+		return evt.Sign_eip712(secretKey)
+
+	default:
+		return fmt.Errorf("unsupported signature scheme %s", o.scheme)
 	}
-
-	evt.ID = hex.EncodeToString(h.Bytes())
-    //evt.Sig = hexutil.Encode(sig)
-	evt.Sig = hex.EncodeToString(sig)
-
-	return nil
 }
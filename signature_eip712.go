@@ -1,10 +1,10 @@
-//go:build !libsecp256k1
-
 package nostr
 
 import (
 	"encoding/hex"
 	"fmt"
+	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -12,224 +12,248 @@ import (
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
-// NostrTypedData represents the EIP-712 typed data structure for Nostr events
-type NostrTypedData struct {
-	Types       apitypes.Types           `json:"types"`
-	PrimaryType string                   `json:"primaryType"`
-	Domain      apitypes.TypedDataDomain `json:"domain"`
-	Message     map[string]interface{}   `json:"message"`
+// eip712PrimaryType is the name of the EIP-712 struct type of nostr events
+const eip712PrimaryType = "NostrEvent"
+
+// eip712EventType describes a nostr event as an EIP-712 struct.
+// The id is not part of it since it is the digest of the typed data itself.
+var eip712EventType = []apitypes.Type{
+	{Name: "pubkey", Type: "string"},
+	{Name: "created_at", Type: "uint64"},
+	{Name: "kind", Type: "uint32"},
+	{Name: "tags", Type: "string[][]"},
+	{Name: "content", Type: "string"},
 }
 
-// NewNostrTypedData creates a new EIP-712 typed data structure for a Nostr event
-func NewNostrTypedData(evt *Event) *NostrTypedData {
-	// Define the types for EIP-712
-	types := apitypes.Types{
-		"EIP712Domain": []apitypes.Type{
-			{Name: "name", Type: "string"},
-			{Name: "version", Type: "string"},
-			{Name: "chainId", Type: "uint256"},
-		},
-		"NostrEvent": []apitypes.Type{
-			{Name: "id", Type: "string"},
-			{Name: "pubkey", Type: "string"},
-			{Name: "created_at", Type: "uint64"},
-			{Name: "kind", Type: "uint32"},
-			{Name: "tags", Type: "string[]"},
-			{Name: "content", Type: "string"},
-		},
-	}
+// DefaultEIP712Domain is the domain used to sign events when none is given
+var DefaultEIP712Domain = NewEIP712Domain("Nostr", "1", 1, "")
 
-	// Create the domain data
+// NewEIP712Domain creates an EIP-712 domain, a zero chainID or an empty verifyingContract
+// leaves the field out of the domain
+func NewEIP712Domain(name, version string, chainID int64, verifyingContract string) apitypes.TypedDataDomain {
 	domain := apitypes.TypedDataDomain{
-		Name:    "Nostr",
-		Version: "1",
-		ChainId: math.NewHexOrDecimal256(1), // Mainnet
+		Name:              name,
+		Version:           version,
+		VerifyingContract: verifyingContract,
 	}
-
-	// Create the message data
-	message := map[string]interface{}{
-		"id":         evt.ID,
-		"pubkey":     evt.PubKey,
-		"created_at": evt.CreatedAt,
-		"kind":       evt.Kind,
-		"tags":       evt.Tags,
-		"content":    evt.Content,
+	if chainID != 0 {
+		domain.ChainId = math.NewHexOrDecimal256(chainID)
 	}
+	return domain
+}
 
-	return &NostrTypedData{
-		Types:       types,
-		PrimaryType: "NostrEvent",
-		Domain:      domain,
-		Message:     message,
+// EIP712SchemeTag makes the scheme tag declaring that an event is signed with EIP-712 in the
+// given domain: ["scheme", "eip712", name, version, chainId, verifyingContract]
+func EIP712SchemeTag(domain apitypes.TypedDataDomain) Tag {
+	chainID := ""
+	if domain.ChainId != nil {
+		chainID = (*big.Int)(domain.ChainId).String()
 	}
+	return Tag{SchemeTagName, SchemeEIP712.String(), domain.Name, domain.Version, chainID, domain.VerifyingContract}
 }
 
-// CheckSignature checks if the event signature is valid using EIP-712
-func (evt Event) CheckSignature_eip712() (bool, error) {
-	// Create typed data
-	typedData := NewNostrTypedData(&evt)
-
-	// Get the domain separator
-	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
-	if err != nil {
-		return false, fmt.Errorf("failed to hash domain: %w", err)
+// EIP712Domain returns the domain declared by the scheme tag of an event signed with EIP-712
+func (evt *Event) EIP712Domain() (apitypes.TypedDataDomain, error) {
+	tag := evt.Tags.Find(SchemeTagName)
+	if tag == nil || ParseSignatureScheme(tag[1]) != SchemeEIP712 {
+		return apitypes.TypedDataDomain{}, fmt.Errorf("event doesn't declare the eip712 scheme")
+	}
+	field := func(i int) string {
+		if i < len(tag) {
+			return tag[i]
+		}
+		return ""
 	}
 
-	// Get the message hash
-	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
-	if err != nil {
-		return false, fmt.Errorf("failed to hash message: %w", err)
+	domain := apitypes.TypedDataDomain{
+		Name:              field(2),
+		Version:           field(3),
+		VerifyingContract: field(5),
+	}
+	if chainID := field(4); chainID != "" {
+		n, ok := math.ParseBig256(chainID)
+		if !ok {
+			return domain, fmt.Errorf("invalid eip712 chain id '%s'", chainID)
+		}
+		domain.ChainId = (*math.HexOrDecimal256)(n)
 	}
+	return domain, nil
+}
 
-	// Get the final hash
-	hash := crypto.Keccak256(
-		[]byte("\x19\x01"),
-		domainSeparator,
-		messageHash,
-	)
+// SetEIP712Domain declares that the event is signed with EIP-712 in the given domain,
+// replacing the scheme tag it may already have
+func (evt *Event) SetEIP712Domain(domain apitypes.TypedDataDomain) {
+	tag := EIP712SchemeTag(domain)
+	for i, t := range evt.Tags {
+		if len(t) >= 2 && t[0] == SchemeTagName {
+			evt.Tags[i] = tag
+			return
+		}
+	}
+	evt.Tags = append(evt.Tags, tag)
+}
 
-	// Decode signature
-	sig, err := hex.DecodeString(evt.Sig)
-	if err != nil {
-		return false, fmt.Errorf("signature '%s' is invalid hex: %w", evt.Sig, err)
+// eip712DomainOf picks the domain an event is hashed in: the given one, else the one declared
+// by the event, else DefaultEIP712Domain
+func (evt *Event) eip712DomainOf(domain []apitypes.TypedDataDomain) (apitypes.TypedDataDomain, error) {
+	if len(domain) > 0 {
+		return domain[0], nil
+	}
+	if evt.Scheme() == SchemeEIP712 {
+		return evt.EIP712Domain()
 	}
+	return DefaultEIP712Domain, nil
+}
+
+// NostrTypedData represents the EIP-712 typed data structure for Nostr events.
+// Its JSON encoding is the payload expected by eth_signTypedData_v4.
+type NostrTypedData struct {
+	apitypes.TypedData
+}
 
-	// Recover public key
-	pubKey, err := crypto.SigToPub(hash, sig)
+// NewNostrTypedData creates a new EIP-712 typed data structure for a Nostr event.
+// Unless a domain is given the one declared by the event is used, or DefaultEIP712Domain if it
+// declares none. It fails if the declared domain is malformed.
+func NewNostrTypedData(evt *Event, domain ...apitypes.TypedDataDomain) (*NostrTypedData, error) {
+	d, err := evt.eip712DomainOf(domain)
 	if err != nil {
-		return false, fmt.Errorf("failed to recover public key: %w", err)
+		return nil, err
 	}
 
-	// Get address from public key
-	recoveredAddr := crypto.PubkeyToAddress(*pubKey).Hex()
-	recoveredAddr = strings.TrimPrefix(recoveredAddr, "0x")
+	// tags are passed the way they come out of JSON so that wallets hash the same values
+	tags := make([]interface{}, len(evt.Tags))
+	for i, tag := range evt.Tags {
+		items := make([]interface{}, len(tag))
+		for j, item := range tag {
+			items[j] = item
+		}
+		tags[i] = items
+	}
 
-	// Compare addresses
-	return (recoveredAddr == evt.PubKey), nil
+	return &NostrTypedData{
+		TypedData: apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain":    eip712DomainType(d),
+				eip712PrimaryType: eip712EventType,
+			},
+			PrimaryType: eip712PrimaryType,
+			Domain:      d,
+			Message: apitypes.TypedDataMessage{
+				"pubkey":     evt.PubKey,
+				"created_at": strconv.FormatInt(int64(evt.CreatedAt), 10),
+				"kind":       strconv.Itoa(evt.Kind),
+				"tags":       tags,
+				"content":    evt.Content,
+			},
+		},
+	}, nil
 }
 
-// Sign signs an event using EIP-712
-func (evt *Event) Sign_eip712(secretKey string) error {
-	// Create typed data
-	typedData := NewNostrTypedData(evt)
+// eip712DomainType lists the fields present in the domain, in the order defined by EIP-712
+func eip712DomainType(domain apitypes.TypedDataDomain) []apitypes.Type {
+	var fields []apitypes.Type
+	if domain.Name != "" {
+		fields = append(fields, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		fields = append(fields, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		fields = append(fields, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		fields = append(fields, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if domain.Salt != "" {
+		fields = append(fields, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
 
-	// Get the domain separator
-	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+// Hash computes the EIP-712 digest keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(event))
+func (typedData *NostrTypedData) Hash() ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData.TypedData)
 	if err != nil {
-		return fmt.Errorf("failed to hash domain: %w", err)
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
 	}
+	return hash, nil
+}
 
-	// Get the message hash
-	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+// GetID_eip712 computes the event id under EIP-712, which is the typed data digest.
+// Unless a domain is given the one declared by the event is used, see Event.EIP712Domain.
+func (evt *Event) GetID_eip712(domain ...apitypes.TypedDataDomain) (string, error) {
+	typedData, err := NewNostrTypedData(evt, domain...)
 	if err != nil {
-		return fmt.Errorf("failed to hash message: %w", err)
+		return "", err
 	}
-
-	// Get the final hash
-	hash := crypto.Keccak256(
-		[]byte("\x19\x01"),
-		domainSeparator,
-		messageHash,
-	)
-
-	// Convert private key
-	s, err := crypto.HexToECDSA(secretKey)
+	hash, err := typedData.Hash()
 	if err != nil {
-		return fmt.Errorf("invalid secret key '%s': %w", secretKey, err)
+		return "", err
 	}
+	return hex.EncodeToString(hash), nil
+}
 
-	// Sign the hash
-	sig, err := crypto.Sign(hash, s)
+// CheckSignature_eip712 checks if the event signature is valid using EIP-712.
+// Unless a domain is given the one declared by the event is used, or DefaultEIP712Domain.
+// Like CheckSignature it won't look at the ID field.
+func (evt Event) CheckSignature_eip712(domain ...apitypes.TypedDataDomain) (bool, error) {
+	typedData, err := NewNostrTypedData(&evt, domain...)
 	if err != nil {
-		return fmt.Errorf("failed to sign: %w", err)
+		return false, err
+	}
+	hash, err := typedData.Hash()
+	if err != nil {
+		return false, err
 	}
 
-	// Set the public key
-	evt.PubKey = strings.TrimPrefix(crypto.PubkeyToAddress(s.PublicKey).Hex(), "0x")
-
-	// Set the signature
-	evt.Sig = hex.EncodeToString(sig)
-
-	// Set the ID (hash of the event)
-	evt.ID = hex.EncodeToString(hash)
+	pubKey, err := recoverSigner(hash, evt.Sig)
+	if err != nil {
+		return false, err
+	}
 
-	return nil
+	recoveredAddr := strings.TrimPrefix(crypto.PubkeyToAddress(*pubKey).Hex(), "0x")
+	return recoveredAddr == evt.PubKey, nil
 }
 
-// HashStruct hashes a struct according to EIP-712
-func (typedData *NostrTypedData) HashStruct(primaryType string, data map[string]interface{}) ([]byte, error) {
-	encodedData, err := typedData.EncodeData(primaryType, data, 1)
+// Sign_eip712 signs an event using EIP-712 in the given domain, or else in the one the event
+// declares, or else in DefaultEIP712Domain.
+// It declares the domain with a scheme tag (see SetEIP712Domain) so the event can be verified
+// by CheckID and CheckSignature, and sets the event's ID, PubKey, and Sig fields.
+func (evt *Event) Sign_eip712(secretKey string, domain ...apitypes.TypedDataDomain) error {
+	s, err := crypto.HexToECDSA(secretKey)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("invalid secret key '%s': %w", secretKey, err)
 	}
-	return crypto.Keccak256(encodedData), nil
-}
 
-// EncodeData encodes the data according to EIP-712
-func (typedData *NostrTypedData) EncodeData(primaryType string, data map[string]interface{}, depth int) ([]byte, error) {
-	if depth > 2 {
-		return nil, fmt.Errorf("depth too high")
+	d, err := evt.eip712DomainOf(domain)
+	if err != nil {
+		return err
 	}
 
-	// Get the type definition
-	types, ok := typedData.Types[primaryType]
-	if !ok {
-		return nil, fmt.Errorf("type %s not found", primaryType)
+	if evt.Tags == nil {
+		evt.Tags = make(Tags, 0)
 	}
 
-	// Encode the type hash
-	typeHash := crypto.Keccak256([]byte(typedData.EncodeType(primaryType)))
-
-	// Encode the data
-	var encodedData []byte
-	encodedData = append(encodedData, typeHash...)
-
-	for _, field := range types {
-		value, ok := data[field.Name]
-		if !ok {
-			return nil, fmt.Errorf("field %s not found in data", field.Name)
-		}
+	// the scheme tag and the pubkey are part of the signed message, so they must be set first
+	evt.SetEIP712Domain(d)
+	evt.PubKey = strings.TrimPrefix(crypto.PubkeyToAddress(s.PublicKey).Hex(), "0x")
 
-		encodedValue, err := typedData.EncodeValue(field.Type, value, depth+1)
-		if err != nil {
-			return nil, err
-		}
-		encodedData = append(encodedData, encodedValue...)
+	typedData, err := NewNostrTypedData(evt, d)
+	if err != nil {
+		return err
 	}
-
-	return encodedData, nil
-}
-
-// EncodeType encodes a type according to EIP-712
-func (typedData *NostrTypedData) EncodeType(primaryType string) string {
-	types := typedData.Types[primaryType]
-	var result string
-	result += primaryType + "("
-	for i, field := range types {
-		if i > 0 {
-			result += ","
-		}
-		result += field.Type + " " + field.Name
+	hash, err := typedData.Hash()
+	if err != nil {
+		return err
 	}
-	result += ")"
-	return result
-}
 
-// EncodeValue encodes a value according to EIP-712
-func (typedData *NostrTypedData) EncodeValue(fieldType string, value interface{}, depth int) ([]byte, error) {
-	switch fieldType {
-	case "string":
-		return crypto.Keccak256([]byte(value.(string))), nil
-	case "uint32", "uint64":
-		return crypto.Keccak256([]byte(fmt.Sprintf("%d", value))), nil
-	case "string[]":
-		// For tags, we'll encode them as a JSON string and hash it
-		jsonStr, err := json.Marshal(value)
-		if err != nil {
-			return nil, err
-		}
-		return crypto.Keccak256(jsonStr), nil
-	default:
-		return nil, fmt.Errorf("unsupported type: %s", fieldType)
+	sig, err := crypto.Sign(hash, s)
+	if err != nil {
+		return fmt.Errorf("failed to sign: %w", err)
 	}
+
+	evt.ID = hex.EncodeToString(hash)
+	evt.Sig = hex.EncodeToString(sig)
+
+	return nil
 }
@@ -0,0 +1,226 @@
+package nostr
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+)
+
+const eip712TestKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func newEIP712TestEvent() Event {
+	return Event{
+		CreatedAt: 1712345678,
+		Kind:      30300,
+		Tags:      Tags{{"sid", "0x1234"}, {"parent", "a", "b"}, {}},
+		Content:   "hello",
+	}
+}
+
+// hashEIP712Manually encodes an event following the EIP-712 spec step by step
+func hashEIP712Manually(evt Event, name, version string, chainID int64) []byte {
+	uint256 := func(n int64) []byte { return common.LeftPadBytes(big.NewInt(n).Bytes(), 32) }
+	keccakString := func(s string) []byte { return crypto.Keccak256([]byte(s)) }
+
+	domainSeparator := crypto.Keccak256(
+		keccakString("EIP712Domain(string name,string version,uint256 chainId)"),
+		keccakString(name),
+		keccakString(version),
+		uint256(chainID),
+	)
+
+	var tagHashes []byte
+	for _, tag := range evt.Tags {
+		var itemHashes []byte
+		for _, item := range tag {
+			itemHashes = append(itemHashes, keccakString(item)...)
+		}
+		tagHashes = append(tagHashes, crypto.Keccak256(itemHashes)...)
+	}
+	structHash := crypto.Keccak256(
+		keccakString("NostrEvent(string pubkey,uint64 created_at,uint32 kind,string[][] tags,string content)"),
+		keccakString(evt.PubKey),
+		uint256(int64(evt.CreatedAt)),
+		uint256(int64(evt.Kind)),
+		crypto.Keccak256(tagHashes),
+		keccakString(evt.Content),
+	)
+
+	return crypto.Keccak256([]byte("\x19\x01"), domainSeparator, structHash)
+}
+
+func TestEIP712Encoding(t *testing.T) {
+	evt := newEIP712TestEvent()
+	evt.PubKey = "2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+
+	typedData, err := NewNostrTypedData(&evt)
+	assert.NoError(t, err)
+	hash, err := typedData.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hashEIP712Manually(evt, "Nostr", "1", 1), hash)
+
+	domain := NewEIP712Domain("DeSci", "2", 137, "")
+	typedData, err = NewNostrTypedData(&evt, domain)
+	assert.NoError(t, err)
+	hash, err = typedData.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hashEIP712Manually(evt, "DeSci", "2", 137), hash)
+
+	// verifyingContract is part of the domain when set
+	withContract := NewEIP712Domain("DeSci", "2", 137, "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	typedData, err = NewNostrTypedData(&evt, withContract)
+	assert.NoError(t, err)
+	assert.Len(t, typedData.Types["EIP712Domain"], 4)
+	other, err := typedData.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	// a malformed declared domain isn't replaced by the default one
+	evt.SetEIP712Domain(domain)
+	evt.Tags.Find("scheme")[4] = "x"
+	_, err = NewNostrTypedData(&evt)
+	assert.Error(t, err)
+	_, err = evt.CheckSignature_eip712()
+	assert.Error(t, err)
+}
+
+func TestEIP712SignAndCheck(t *testing.T) {
+	evt := newEIP712TestEvent()
+	assert.NoError(t, evt.Sign_eip712(eip712TestKey))
+	assert.Equal(t, "2c7536E3605D9C16a7a3D7b1898e529396a65c23", evt.PubKey)
+
+	id, err := evt.GetID_eip712()
+	assert.NoError(t, err)
+	assert.Equal(t, id, evt.ID)
+
+	ok, err := evt.CheckSignature_eip712()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// a different domain or a tampered event doesn't verify
+	ok, err = evt.CheckSignature_eip712(NewEIP712Domain("Nostr", "1", 5, ""))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	evt.Tags[1][2] = "c"
+	ok, err = evt.CheckSignature_eip712()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	domain := NewEIP712Domain("DeSci", "1", 137, "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	evt = newEIP712TestEvent()
+	assert.NoError(t, evt.Sign_eip712(eip712TestKey, domain))
+	ok, err = evt.CheckSignature_eip712(domain)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestEIP712WalletSignature(t *testing.T) {
+	evt := newEIP712TestEvent()
+	evt.PubKey = "2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	domain := NewEIP712Domain("Nostr", "1", 1, "")
+
+	// a wallet receives the typed data as JSON, signs its digest and returns v as 27/28
+	typedData, err := NewNostrTypedData(&evt, domain)
+	assert.NoError(t, err)
+	payload, err := json.Marshal(typedData)
+	assert.NoError(t, err)
+	var received apitypes.TypedData
+	assert.NoError(t, json.Unmarshal(payload, &received))
+	digest, _, err := apitypes.TypedDataAndHash(received)
+	assert.NoError(t, err)
+
+	sk, _ := crypto.HexToECDSA(eip712TestKey)
+	sig, err := crypto.Sign(digest, sk)
+	assert.NoError(t, err)
+	sig[64] += 27
+	evt.Sig = hex.EncodeToString(sig)
+
+	ok, err := evt.CheckSignature_eip712(domain)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	evt.Sig = evt.Sig[:128]
+	_, err = evt.CheckSignature_eip712(domain)
+	assert.Error(t, err)
+}
+
+func TestEIP712DeclaredScheme(t *testing.T) {
+	domain := NewEIP712Domain("DeSci", "1", 137, "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+
+	evt := newEIP712TestEvent()
+	assert.NoError(t, evt.Sign_eip712(eip712TestKey, domain))
+	assert.Equal(t, Tag{"scheme", "eip712", "DeSci", "1", "137", "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}, evt.Tags.Find("scheme"))
+	assert.Equal(t, SchemeEIP712, evt.Scheme())
+
+	declared, err := evt.EIP712Domain()
+	assert.NoError(t, err)
+	assert.Equal(t, domain, declared)
+
+	// the id and the signature are checked in the declared domain
+	assert.Equal(t, evt.ID, evt.GetID())
+	assert.True(t, evt.CheckID())
+	ok, err := evt.CheckSignature()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// signing again replaces the scheme tag
+	assert.NoError(t, evt.Sign_eip712(eip712TestKey))
+	assert.Len(t, evt.Tags.GetAll([]string{"scheme"}), 1)
+	assert.Equal(t, "137", evt.Tags.Find("scheme")[4])
+
+	// the domain is part of the signed message
+	evt.Tags.Find("scheme")[4] = "1"
+	assert.False(t, evt.CheckID())
+	ok, _ = evt.CheckSignature()
+	assert.False(t, ok)
+
+	evt.Tags.Find("scheme")[4] = "x"
+	assert.Equal(t, "", evt.GetID())
+	_, err = evt.CheckSignature()
+	assert.Error(t, err)
+
+	// Sign can pick the scheme too
+	evt = newEIP712TestEvent()
+	assert.NoError(t, evt.Sign(eip712TestKey, WithScheme(SchemeEIP712)))
+	assert.Equal(t, EIP712SchemeTag(DefaultEIP712Domain), evt.Tags.Find("scheme"))
+	assert.True(t, evt.CheckID())
+	ok, err = evt.CheckSignature()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestEIP712DeclaredWalletSignature(t *testing.T) {
+	domain := NewEIP712Domain("DeSci", "1", 137, "")
+
+	// the event declares its domain before being handed to the wallet
+	evt := newEIP712TestEvent()
+	evt.PubKey = "2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	evt.SetEIP712Domain(domain)
+
+	typedData, err := NewNostrTypedData(&evt)
+	assert.NoError(t, err)
+	payload, err := json.Marshal(typedData)
+	assert.NoError(t, err)
+	var received apitypes.TypedData
+	assert.NoError(t, json.Unmarshal(payload, &received))
+	digest, _, err := apitypes.TypedDataAndHash(received)
+	assert.NoError(t, err)
+
+	sk, _ := crypto.HexToECDSA(eip712TestKey)
+	sig, err := crypto.Sign(digest, sk)
+	assert.NoError(t, err)
+	sig[64] += 27
+	evt.Sig = hex.EncodeToString(sig)
+	evt.ID = evt.GetID()
+
+	assert.Equal(t, hex.EncodeToString(digest), evt.ID)
+	ok, err := evt.CheckSignature()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
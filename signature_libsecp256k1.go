@@ -31,10 +31,26 @@ import (
 	"fmt"
 	"unsafe"
 
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// CheckSignature checks if the event signature is valid for the given event.
+// The scheme is the one declared by the event or detected from the shape of its pubkey and
+// signature, see Event.Scheme.
 func (evt Event) CheckSignature() (bool, error) {
+	switch evt.Scheme() {
+	case SchemeEIP191:
+		return evt.checkSignatureEIP191()
+	case SchemeSchnorr:
+		return evt.checkSignatureSchnorr()
+	case SchemeEIP712:
+		return evt.CheckSignature_eip712()
+	default:
+		return false, fmt.Errorf("unknown signature scheme for pubkey '%s'", evt.PubKey)
+	}
+}
+
+func (evt *Event) checkSignatureSchnorr() (bool, error) {
 	var pk [32]byte
 	_, err := hex.Decode(pk[:], []byte(evt.PubKey))
 	if err != nil {
@@ -58,16 +74,37 @@ func (evt Event) CheckSignature() (bool, error) {
 	return res == 1, nil
 }
 
-func (evt *Event) Sign(secretKey string, signOpts ...schnorr.SignOption) error {
-	sk, err := hex.DecodeString(secretKey)
-	if err != nil {
-		return fmt.Errorf("Sign called with invalid secret key '%s': %w", secretKey, err)
-	}
+// Sign signs an event with a given privateKey.
+// The scheme is DefaultSignatureScheme unless WithScheme is given.
+func (evt *Event) Sign(secretKey string, opts ...SignOption) error {
+	o := newSignOptions(opts)
 
 	if evt.Tags == nil {
 		evt.Tags = make(Tags, 0)
 	}
 
+	switch o.scheme {
+	case SchemeEIP191:
+		s, err := crypto.HexToECDSA(secretKey)
+		if err != nil {
+			return fmt.Errorf("Sign called with invalid secret key '%s': %w", secretKey, err)
+		}
+		return evt.signEIP191(s)
+	case SchemeSchnorr:
+		return evt.signSchnorr(secretKey)
+	case SchemeEIP712:
+		return evt.Sign_eip712(secretKey)
+	default:
+		return fmt.Errorf("unsupported signature scheme %s", o.scheme)
+	}
+}
+
+func (evt *Event) signSchnorr(secretKey string) error {
+	sk, err := hex.DecodeString(secretKey)
+	if err != nil || len(sk) != 32 {
+		return fmt.Errorf("Sign called with invalid secret key '%s'", secretKey)
+	}
+
 	var keypair C.secp256k1_keypair
 	if C.secp256k1_keypair_create(globalSecp256k1Context, &keypair, (*C.uchar)(unsafe.Pointer(&sk[0]))) != 1 {
 		return errors.New("failed to parse private key")
@@ -0,0 +1,212 @@
+package nostr
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignatureScheme identifies how an event is hashed and signed
+type SignatureScheme int
+
+const (
+	// SchemeUnknown is reported for events whose pubkey and signature match no scheme
+	SchemeUnknown SignatureScheme = iota
+	// SchemeEIP191 signs keccak256("\x19Ethereum Signed Message:\n" ‖ len ‖ serialized event)
+	// with a 65 byte recoverable signature, the pubkey is a 20 byte address
+	SchemeEIP191
+	// SchemeSchnorr is the standard nostr BIP-340 scheme, it signs sha256(serialized event)
+	// with a 64 byte signature, the pubkey is a 32 byte x-only key
+	SchemeSchnorr
+	// SchemeEIP712 signs the typed data digest of the event (see NostrTypedData) with a 65 byte
+	// recoverable signature, the pubkey is a 20 byte address. Since that is the same shape as
+	// EIP-191 these events must declare it with a scheme tag, see Event.EIP712Domain
+	SchemeEIP712
+)
+
+// SchemeTagName is the tag events use to declare their signature scheme, as in
+// ["scheme", "eip712", ...]. Events without it are detected from the shape of their keys.
+const SchemeTagName = "scheme"
+
+func (s SignatureScheme) String() string {
+	switch s {
+	case SchemeEIP191:
+		return "eip191"
+	case SchemeSchnorr:
+		return "schnorr"
+	case SchemeEIP712:
+		return "eip712"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSignatureScheme returns the scheme named s as in a scheme tag, or SchemeUnknown
+func ParseSignatureScheme(s string) SignatureScheme {
+	switch s {
+	case "eip191":
+		return SchemeEIP191
+	case "schnorr":
+		return SchemeSchnorr
+	case "eip712":
+		return SchemeEIP712
+	default:
+		return SchemeUnknown
+	}
+}
+
+// DefaultSignatureScheme is the scheme used by Sign when no WithScheme option is given
+var DefaultSignatureScheme = SchemeEIP191
+
+// Scheme returns the signature scheme declared by the scheme tag of the event. Without one it is
+// detected from the shape of its pubkey and, when the event is signed, of its signature:
+// a 20 byte address with a 65 byte signature is EIP-191, a 32 byte key with a 64 byte signature
+// is Schnorr.
+func (evt *Event) Scheme() SignatureScheme {
+	if tag := evt.Tags.Find(SchemeTagName); tag != nil {
+		return ParseSignatureScheme(tag[1])
+	}
+
+	switch len(evt.PubKey) {
+	case 40:
+		if evt.Sig == "" || len(evt.Sig) == 130 {
+			return SchemeEIP191
+		}
+	case 64:
+		if evt.Sig == "" || len(evt.Sig) == 128 {
+			return SchemeSchnorr
+		}
+	}
+	return SchemeUnknown
+}
+
+// hash computes the digest that is both the id and the signed message of the event.
+// Events that are neither Schnorr nor EIP-712 events are hashed with EIP-191.
+func (evt *Event) hash() ([32]byte, error) {
+	switch evt.Scheme() {
+	case SchemeSchnorr:
+		return sha256.Sum256(evt.Serialize()), nil
+	case SchemeEIP712:
+		var h [32]byte
+		typedData, err := NewNostrTypedData(evt)
+		if err != nil {
+			return h, err
+		}
+		digest, err := typedData.Hash()
+		copy(h[:], digest)
+		return h, err
+	default:
+		return eip191Hash(evt.Serialize()), nil
+	}
+}
+
+func eip191Hash(message []byte) [32]byte {
+	prefixedMessage := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
+	return crypto.Keccak256Hash([]byte(prefixedMessage))
+}
+
+// SignOption changes how Sign signs an event
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	scheme SignatureScheme
+}
+
+// WithScheme selects the signature scheme used by Sign
+func WithScheme(scheme SignatureScheme) SignOption {
+	return func(o *signOptions) {
+		o.scheme = scheme
+	}
+}
+
+func newSignOptions(opts []SignOption) signOptions {
+	o := signOptions{scheme: DefaultSignatureScheme}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// checkSignatureEIP191 verifies an EIP-191 signature by recovering the signer address
+func (evt *Event) checkSignatureEIP191() (bool, error) {
+	h := eip191Hash(evt.Serialize())
+	pubKey, err := recoverSigner(h[:], evt.Sig)
+	if err != nil {
+		return false, err
+	}
+
+	recoveredAddr := strings.TrimPrefix(crypto.PubkeyToAddress(*pubKey).Hex(), "0x")
+	return recoveredAddr == evt.PubKey, nil
+}
+
+// recoverSigner recovers the public key that made a 65 byte recoverable signature of digest
+func recoverSigner(digest []byte, sigHex string) (*ecdsa.PublicKey, error) {
+	sig, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return nil, fmt.Errorf("signature '%s' is invalid hex: %w", sigHex, err)
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("signature must be 65 bytes, got %d", len(sig))
+	}
+	// wallets produce v as 27/28
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key: %w", err)
+	}
+	return pubKey, nil
+}
+
+// RecoverPublicKey returns the compressed secp256k1 public key that signed the event, as hex.
+// EIP-191 and EIP-712 events only carry the address of their signer, so the key is recovered
+// from the signature. Schnorr events carry the x-only key, which BIP-340 defines as the point
+// with an even y.
+// It fails if the signature isn't valid for the event.
+func (evt *Event) RecoverPublicKey() (string, error) {
+	switch evt.Scheme() {
+	case SchemeSchnorr:
+		if ok, err := evt.CheckSignature(); !ok {
+			return "", fmt.Errorf("invalid signature: %v", err)
+		}
+		return "02" + evt.PubKey, nil
+
+	case SchemeEIP191, SchemeEIP712:
+		h, err := evt.hash()
+		if err != nil {
+			return "", err
+		}
+		pubKey, err := recoverSigner(h[:], evt.Sig)
+		if err != nil {
+			return "", err
+		}
+		if addr := strings.TrimPrefix(crypto.PubkeyToAddress(*pubKey).Hex(), "0x"); addr != evt.PubKey {
+			return "", fmt.Errorf("signature was made by %s, not %s", addr, evt.PubKey)
+		}
+		return hex.EncodeToString(crypto.CompressPubkey(pubKey)), nil
+
+	default:
+		return "", fmt.Errorf("unknown signature scheme for pubkey '%s'", evt.PubKey)
+	}
+}
+
+// signEIP191 sets the event's ID, PubKey, and Sig fields using EIP-191
+func (evt *Event) signEIP191(sk *ecdsa.PrivateKey) error {
+	evt.PubKey = strings.TrimPrefix(crypto.PubkeyToAddress(sk.PublicKey).Hex(), "0x")
+
+	h := eip191Hash(evt.Serialize())
+	sig, err := crypto.Sign(h[:], sk)
+	if err != nil {
+		return fmt.Errorf("failed to sign: %w", err)
+	}
+
+	evt.ID = hex.EncodeToString(h[:])
+	evt.Sig = hex.EncodeToString(sig)
+	return nil
+}
@@ -0,0 +1,76 @@
+package nostr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventScheme(t *testing.T) {
+	for _, tc := range []struct {
+		pubkey   string
+		sig      string
+		expected SignatureScheme
+	}{
+		{strings.Repeat("a", 40), "", SchemeEIP191},
+		{strings.Repeat("a", 40), strings.Repeat("b", 130), SchemeEIP191},
+		{strings.Repeat("a", 64), "", SchemeSchnorr},
+		{strings.Repeat("a", 64), strings.Repeat("b", 128), SchemeSchnorr},
+		{strings.Repeat("a", 40), strings.Repeat("b", 128), SchemeUnknown},
+		{strings.Repeat("a", 64), strings.Repeat("b", 130), SchemeUnknown},
+		{strings.Repeat("a", 66), "", SchemeUnknown},
+	} {
+		evt := Event{PubKey: tc.pubkey, Sig: tc.sig}
+		assert.Equal(t, tc.expected, evt.Scheme(), "pubkey %d sig %d", len(tc.pubkey), len(tc.sig))
+	}
+}
+
+func TestSignBothSchemes(t *testing.T) {
+	sk := GeneratePrivateKey()
+
+	for _, scheme := range []SignatureScheme{SchemeEIP191, SchemeSchnorr} {
+		evt := Event{Kind: KindTextNote, CreatedAt: 1712345678, Content: "hello " + scheme.String()}
+		assert.NoError(t, evt.Sign(sk, WithScheme(scheme)))
+		assert.Equal(t, scheme, evt.Scheme())
+		assert.True(t, evt.CheckID())
+
+		ok, err := evt.CheckSignature()
+		assert.NoError(t, err)
+		assert.True(t, ok, "%s signature should verify", scheme)
+
+		evt.Content += "!"
+		ok, _ = evt.CheckSignature()
+		assert.False(t, ok, "tampered %s event should not verify", scheme)
+		assert.False(t, evt.CheckID())
+	}
+
+	// EIP-191 is the default
+	evt := Event{Kind: KindTextNote, CreatedAt: 1712345678}
+	assert.NoError(t, evt.Sign(sk))
+	assert.Equal(t, SchemeEIP191, evt.Scheme())
+
+	evt.PubKey = "abcd"
+	_, err := evt.CheckSignature()
+	assert.Error(t, err)
+	assert.Error(t, evt.Sign(sk, WithScheme(SchemeUnknown)))
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	sk := GeneratePrivateKey()
+	xonly, _ := GetPublicKey(sk)
+
+	for _, scheme := range []SignatureScheme{SchemeEIP191, SchemeSchnorr, SchemeEIP712} {
+		evt := Event{Kind: KindTextNote, CreatedAt: 1712345678, Content: "hello " + scheme.String()}
+		assert.NoError(t, evt.Sign(sk, WithScheme(scheme)))
+
+		key, err := evt.RecoverPublicKey()
+		assert.NoError(t, err, scheme.String())
+		assert.Len(t, key, 66)
+		assert.Equal(t, xonly, key[2:], "%s key should match", scheme)
+
+		evt.Content += "!"
+		_, err = evt.RecoverPublicKey()
+		assert.Error(t, err, "tampered %s event should not recover", scheme)
+	}
+}
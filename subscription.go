@@ -41,6 +41,9 @@ type Subscription struct {
 	// if it returns true that event will not be processed further.
 	checkDuplicateReplaceable func(rk ReplaceableKey, ts Timestamp) bool
 
+	// if it is not nil, the subscription will be resumed from there after a reconnection (see WithReconnect)
+	resume *resumePoint
+
 	match  func(*Event) bool // this will be either Filters.Match or Filters.MatchIgnoringTimestampConstraints
 	live   atomic.Bool
 	eosed  atomic.Bool
@@ -90,6 +93,96 @@ func (sub *Subscription) start() {
 	sub.mu.Unlock()
 }
 
+// resumePoint keeps track of the events received by a subscription, so it can be resumed after a
+// reconnection without losing or emitting them twice.
+//
+// Relays send stored events in any order, so until the EOSE nothing tells how far they got: the
+// subscription is resumed with its original filters and all the events received are skipped.
+// After the EOSE it is resumed from the newest event received.
+type resumePoint struct {
+	mu     sync.Mutex
+	eosed  bool
+	stored map[string]Timestamp // the events received before the EOSE
+	since  Timestamp
+	ids    map[string]struct{} // the events received with created_at == since
+}
+
+// see records an event and returns false if it had been received already.
+func (rp *resumePoint) see(evt *Event) bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if !rp.eosed {
+		if _, seen := rp.stored[evt.ID]; seen {
+			return false
+		}
+		if rp.stored == nil {
+			rp.stored = make(map[string]Timestamp)
+		}
+		rp.stored[evt.ID] = evt.CreatedAt
+		return true
+	}
+
+	switch {
+	case evt.CreatedAt > rp.since || rp.ids == nil:
+		rp.since = max(rp.since, evt.CreatedAt)
+		rp.ids = map[string]struct{}{evt.ID: {}}
+	case evt.CreatedAt == rp.since:
+		if _, seen := rp.ids[evt.ID]; seen {
+			return false
+		}
+		rp.ids[evt.ID] = struct{}{}
+	}
+	return true
+}
+
+// eose marks the end of the stored events, from then on the subscription can be resumed from the
+// newest event received.
+func (rp *resumePoint) eose() {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if rp.eosed {
+		return
+	}
+	rp.eosed = true
+	for id, createdAt := range rp.stored {
+		switch {
+		case createdAt > rp.since || rp.ids == nil:
+			rp.since = createdAt
+			rp.ids = map[string]struct{}{id: {}}
+		case createdAt == rp.since:
+			rp.ids[id] = struct{}{}
+		}
+	}
+	rp.stored = nil
+}
+
+// filters returns a copy of filters asking only for the events from the newest one received on,
+// or the filters as they are before the EOSE. Since the relay will send events received already
+// again they must be checked with see().
+func (rp *resumePoint) filters(filters Filters) Filters {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if !rp.eosed || rp.ids == nil {
+		return filters
+	}
+
+	resumed := make(Filters, len(filters))
+	for i, filter := range filters {
+		if filter.Since == nil || *filter.Since < rp.since {
+			since := rp.since
+			filter.Since = &since
+		}
+		// we want everything we missed, not just the latest
+		filter.Limit = 0
+		filter.LimitZero = false
+		resumed[i] = filter
+	}
+	return resumed
+}
+
 // GetID returns the subscription ID.
 func (sub *Subscription) GetID() string { return sub.id }
 
@@ -119,6 +212,9 @@ func (sub *Subscription) dispatchEvent(evt *Event) {
 
 func (sub *Subscription) dispatchEose() {
 	if sub.eosed.CompareAndSwap(false, true) {
+		if sub.resume != nil {
+			sub.resume.eose()
+		}
 		sub.match = sub.Filters.MatchIgnoringTimestampConstraints
 		go func() {
 			sub.storedwg.Wait()
@@ -4,7 +4,6 @@ import (
 	jsonutils "encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr/cip"
@@ -26,8 +25,16 @@ func calculateSubspaceID(subspaceName, ops, rules string) string {
 	return cip.CalculateSubspaceID(subspaceName, ops, rules)
 }
 
-// NewSubspaceCreateEvent creates a new subspace creation event
-func NewSubspaceCreateEvent(subspaceName, ops, rules, description, imageURL string) *SubspaceCreateEvent {
+// NewSubspaceCreateEvent creates a new subspace creation event, well formed ops are stored in
+// their canonical form. It fails if the rules are malformed.
+func NewSubspaceCreateEvent(subspaceName, ops, rules, description, imageURL string) (*SubspaceCreateEvent, error) {
+	if err := cip.ValidateRules(rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	if canonical, err := cip.CanonicalOps(ops); err == nil {
+		ops = canonical
+	}
+
 	// Calculate subspace ID
 	sid := calculateSubspaceID(subspaceName, ops, rules)
 
@@ -46,7 +53,7 @@ func NewSubspaceCreateEvent(subspaceName, ops, rules, description, imageURL stri
 
 	// Set tags
 	evt.Tags = Tags{
-		Tag{"d", cip.OpSubspaceCreate},
+		Tag{"d", cip.ObjectIdentifier(sid, "")},
 		Tag{"sid", sid},
 		Tag{"subspace_name", subspaceName},
 		Tag{"ops", ops},
@@ -63,7 +70,7 @@ func NewSubspaceCreateEvent(subspaceName, ops, rules, description, imageURL stri
 	contentBytes, _ := jsonutils.Marshal(content)
 	evt.Content = string(contentBytes)
 
-	return evt
+	return evt, nil
 }
 
 // ValidateSubspaceCreateEvent validates a SubspaceCreateEvent
@@ -97,11 +104,15 @@ func ValidateSubspaceCreateEvent(evt *SubspaceCreateEvent) error {
 		}
 	}
 
-	// 3. Verify sid matches the calculated hash
+	// 3. Verify sid matches the calculated hash, subspaces created before ops were
+	// canonicalized hashed them as written
 	calculatedSID := calculateSubspaceID(evt.SubspaceName, evt.Ops, evt.Rules)
-	if evt.SubspaceID != calculatedSID {
+	if evt.SubspaceID != calculatedSID && evt.SubspaceID != cip.LegacySubspaceID(evt.SubspaceName, evt.Ops, evt.Rules) {
 		return fmt.Errorf("invalid subspace ID: expected %s, got %s", calculatedSID, evt.SubspaceID)
 	}
+	if err := checkIdentifier(evt.Tags.GetD(), evt.SubspaceID); err != nil {
+		return err
+	}
 
 	// 4. Verify content is valid JSON with required fields
 	var content struct {
@@ -115,18 +126,31 @@ func ValidateSubspaceCreateEvent(evt *SubspaceCreateEvent) error {
 		return fmt.Errorf("missing description in content")
 	}
 
-	// 5. Verify ops format
-	// ops should be in format "key1=value1,key2=value2,..."
-	opsParts := strings.Split(evt.Ops, ",")
-	for _, part := range opsParts {
-		if !strings.Contains(part, "=") {
-			return fmt.Errorf("invalid ops format: %s", evt.Ops)
-		}
+	// 5. Verify rules are well formed
+	if err := cip.ValidateRules(evt.Rules); err != nil {
+		return fmt.Errorf("invalid rules: %v", err)
+	}
+
+	// 6. Verify ops are well formed and match the registered kinds
+	if _, err := evt.OpsSpec(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// OpsSpec parses the operations enabled in the subspace and checks them against the registered kinds
+func (evt *SubspaceCreateEvent) OpsSpec() (cip.OpsSpec, error) {
+	spec, err := cip.ParseOpsSpec(evt.Ops)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ops: %v", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid ops: %v", err)
+	}
+	return spec, nil
+}
+
 // ParseSubspaceCreateEvent parses a raw Event into a SubspaceCreateEvent
 func ParseSubspaceCreateEvent(evt Event) (*SubspaceCreateEvent, error) {
 	// Create new SubspaceCreateEvent
@@ -187,7 +211,7 @@ func NewSubspaceJoinEvent(subspaceID string) *SubspaceJoinEvent {
 	}
 
 	evt.Tags = Tags{
-		Tag{"d", cip.OpSubspaceJoin},
+		Tag{"d", cip.ObjectIdentifier(subspaceID, "")},
 		Tag{"sid", subspaceID},
 	}
 
@@ -227,10 +251,22 @@ func ValidateSubspaceJoinEvent(evt *SubspaceJoinEvent) error {
 	if err := cip.ValidateSubspaceID(evt.SubspaceID); err != nil {
 		return err
 	}
+	if err := checkIdentifier(evt.Tags.GetD(), evt.SubspaceID); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// checkIdentifier verifies that a d tag in the object form addresses the given subspace,
+// legacy and unique d tags are accepted as they are
+func checkIdentifier(d, subspaceID string) error {
+	if form, sid, _ := cip.ParseIdentifier(d); form == cip.IdentifierObject && sid != subspaceID {
+		return fmt.Errorf("d tag %s addresses another subspace", d)
+	}
+	return nil
+}
+
 // ParseSubspaceJoinEvent parses a raw Event into a SubspaceJoinEvent
 func ParseSubspaceJoinEvent(evt Event) (*SubspaceJoinEvent, error) {
 	joinEvt := &SubspaceJoinEvent{
@@ -260,6 +296,8 @@ type SubspaceOpEventPtr interface {
 	GetSubspaceID() string
 	GetOperation() string
 	GetAuthTag() cip.AuthTag
+	GetClock() *cip.VLC
+	Validate() error
 }
 
 // SubspaceOpEvent represents a subspace operation event
@@ -269,11 +307,62 @@ type SubspaceOpEvent struct {
 	Operation  string
 	AuthTag    cip.AuthTag
 	Parents    []string
+	Clock      *cip.VLC
 }
 
 func (e *SubspaceOpEvent) GetSubspaceID() string   { return e.SubspaceID }
 func (e *SubspaceOpEvent) GetOperation() string    { return e.Operation }
 func (e *SubspaceOpEvent) GetAuthTag() cip.AuthTag { return e.AuthTag }
+func (e *SubspaceOpEvent) GetClock() *cip.VLC      { return e.Clock }
+
+// Validator checks the fields shared by every subspace operation and returns the validator
+// so that typed events can add their own checks before calling Err
+func (e *SubspaceOpEvent) Validator() *cip.Validator {
+	v := cip.NewValidator(e.Kind)
+
+	if v.Required("sid", e.SubspaceID) {
+		v.CheckErr("sid", cip.ValidateSubspaceID(e.SubspaceID))
+	}
+
+	operation, exists := cip.GetOpFromKind(e.Kind)
+	switch {
+	case !exists:
+		v.Fail("kind", "unknown kind %d", e.Kind)
+	case e.Operation != operation:
+		v.Fail("kind", "kind %d is %s, not %s", e.Kind, operation, e.Operation)
+	}
+	if op := e.Tags.Find("op"); op != nil && exists && op[1] != operation {
+		v.Fail("op", "op tag %s does not match kind %d", op[1], e.Kind)
+	}
+
+	for _, parent := range e.Parents {
+		v.Hash("parent", parent)
+	}
+
+	v.CheckErr("d", checkIdentifier(e.Tags.GetD(), e.SubspaceID))
+	if e.ObjectID() != "" && !cip.IsReplaceableOp(e.Operation) {
+		// relays would only keep the latest event of an append-only operation
+		v.Fail("d", "%s is append-only and cannot address an object", e.Operation)
+	}
+
+	return v
+}
+
+// ObjectValidator is Validator for replaceable operations, it also checks that a d tag in the
+// object form addresses the given object
+func (e *SubspaceOpEvent) ObjectValidator(objectID string) *cip.Validator {
+	v := e.Validator()
+	if id := e.ObjectID(); id != "" && id != objectID {
+		v.Fail("d", "addresses object %q, not %q", id, objectID)
+	}
+	return v
+}
+
+// Validate checks the fields shared by every subspace operation, typed events override it
+// to check their own fields as well
+func (e *SubspaceOpEvent) Validate() error {
+	return e.Validator().Err()
+}
 
 // NewSubspaceOpEvent creates a new subspace operation event
 func NewSubspaceOpEvent(subspaceID string, kind int) (*SubspaceOpEvent, error) {
@@ -291,7 +380,7 @@ func NewSubspaceOpEvent(subspaceID string, kind int) (*SubspaceOpEvent, error) {
 	}
 
 	evt.Tags = Tags{
-		Tag{"d", "subspace_op"},
+		Tag{"d", cip.UniqueIdentifier()},
 		Tag{"sid", subspaceID},
 		Tag{"op", operation},
 	}
@@ -299,6 +388,29 @@ func NewSubspaceOpEvent(subspaceID string, kind int) (*SubspaceOpEvent, error) {
 	return evt, nil
 }
 
+// ObjectID returns the object addressed by the d tag, or "" for append-only operations and
+// events that still carry the legacy d tag
+func (e *SubspaceOpEvent) ObjectID() string {
+	form, sid, objectID := cip.ParseIdentifier(e.Tags.GetD())
+	if form != cip.IdentifierObject || sid != e.SubspaceID {
+		return ""
+	}
+	return objectID
+}
+
+// SetObjectID makes the operation replaceable by addressing it with the subspace and object id,
+// the typed events of replaceable operations call it when their object id is set
+func (e *SubspaceOpEvent) SetObjectID(objectID string) {
+	d := cip.ObjectIdentifier(e.SubspaceID, objectID)
+	for i, tag := range e.Tags {
+		if len(tag) >= 2 && tag[0] == "d" {
+			e.Tags[i] = Tag{"d", d}
+			return
+		}
+	}
+	e.Tags = append(e.Tags, Tag{"d", d})
+}
+
 // SetAuth sets the auth tag for the operation
 func (e *SubspaceOpEvent) SetAuth(action cip.Action, key uint32, exp uint64) {
 	e.AuthTag = cip.NewAuthTag(action, key, exp)
@@ -319,4 +431,28 @@ func (e *SubspaceOpEvent) SetParents(parentHashSet []string) {
 		parents = append(parents, validParents...)
 		e.Tags = append(e.Tags, parents)
 	}
-}
\ No newline at end of file
+}
+
+// SetClock sets the vector clock carried by the operation, a nil clock removes it
+func (e *SubspaceOpEvent) SetClock(clock *cip.VLC) {
+	e.Tags = e.Tags.FilterOut([]string{"vlc"})
+	if clock == nil {
+		e.Clock = nil
+		return
+	}
+	e.Clock = clock.Clone()
+	e.Tags = append(e.Tags, Tag{"vlc", e.Clock.String()})
+}
+
+// CheckClock verifies that the operation clock happens after the clocks of all its parents
+func (e *SubspaceOpEvent) CheckClock(parentClocks ...*cip.VLC) error {
+	if e.Clock == nil {
+		return errors.New("missing vlc tag")
+	}
+	for _, parent := range parentClocks {
+		if order := e.Clock.Compare(parent); order != cip.OrderAfter {
+			return fmt.Errorf("clock %s is %s parent clock %s", e.Clock, order, parent)
+		}
+	}
+	return nil
+}
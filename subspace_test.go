@@ -9,13 +9,14 @@ import (
 
 func TestSubspaceCreateEvent(t *testing.T) {
 	// Test creating a subspace with basic operations
-	createEvent := NewSubspaceCreateEvent(
+	createEvent, err := NewSubspaceCreateEvent(
 		"test-subspace",
 		cip.DefaultSubspaceOps,
 		"energy>1000",
 		"Test Subspace",
 		"https://example.com/image.png",
 	)
+	assert.NoError(t, err)
 
 	// Verify event kind
 	assert.Equal(t, cip.KindSubspaceCreate, createEvent.Kind)
@@ -46,7 +47,7 @@ func TestSubspaceCreateEvent(t *testing.T) {
 	assert.Equal(t, calculatedSID, createEvent.SubspaceID)
 
 	// Test validation
-	err := ValidateSubspaceCreateEvent(createEvent)
+	err = ValidateSubspaceCreateEvent(createEvent)
 	assert.NoError(t, err)
 
 	// Test parsing
@@ -60,13 +61,14 @@ func TestSubspaceCreateEvent(t *testing.T) {
 
 func TestSubspaceJoinEvent(t *testing.T) {
 	// Create a subspace first to get a valid sid
-	createEvent := NewSubspaceCreateEvent(
+	createEvent, err := NewSubspaceCreateEvent(
 		"test-subspace",
 		cip.DefaultSubspaceOps,
 		"energy>1000",
 		"Test Subspace",
 		"https://example.com/image.png",
 	)
+	assert.NoError(t, err)
 
 	// Test creating a join event
 	joinEvent := NewSubspaceJoinEvent(createEvent.SubspaceID)
@@ -94,11 +96,131 @@ func TestSubspaceJoinEvent(t *testing.T) {
 	}
 
 	// Test validation
-	err := ValidateSubspaceJoinEvent(joinEvent)
+	err = ValidateSubspaceJoinEvent(joinEvent)
 	assert.NoError(t, err)
 
 	// Test parsing
 	parsedEvent, err := ParseSubspaceJoinEvent(joinEvent.Event)
 	assert.NoError(t, err)
 	assert.Equal(t, joinEvent.SubspaceID, parsedEvent.SubspaceID)
-}
\ No newline at end of file
+}
+func TestSubspaceOpEventClock(t *testing.T) {
+	evt, err := NewSubspaceOpEvent("0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", cip.KindGovernancePost)
+	assert.NoError(t, err)
+
+	// Operations without a clock cannot be checked
+	assert.Error(t, evt.CheckClock())
+
+	parent := cip.NewVLC()
+	parent.Increment(30300)
+
+	clock := parent.Clone()
+	clock.Increment(30300)
+	evt.SetClock(clock)
+	assert.Equal(t, "30300:2", evt.Tags.Find("vlc")[1])
+
+	// Setting the clock again replaces the tag
+	clock.Increment(30301)
+	evt.SetClock(clock)
+	assert.Equal(t, 1, len(evt.Tags.GetAll([]string{"vlc"})))
+	assert.Equal(t, "30300:2,30301:1", evt.Tags.Find("vlc")[1])
+
+	assert.NoError(t, evt.CheckClock(parent))
+	assert.Error(t, evt.CheckClock(clock))
+
+	concurrent := cip.NewVLC()
+	concurrent.Set(30302, 1)
+	assert.Error(t, evt.CheckClock(concurrent))
+
+	// A nil clock removes it
+	evt.SetClock(nil)
+	assert.Nil(t, evt.Clock)
+	assert.Nil(t, evt.Tags.Find("vlc"))
+	assert.Error(t, evt.CheckClock())
+}
+
+func TestSubspaceCreateEventInvalidRules(t *testing.T) {
+	for _, rules := range []string{"energy>>1000", "energy=1000", "min_stake=5"} {
+		_, err := NewSubspaceCreateEvent("test-subspace", cip.DefaultSubspaceOps, rules, "Test Subspace", "")
+		assert.Error(t, err, rules)
+	}
+
+	// events built by hand are still checked
+	createEvent, err := NewSubspaceCreateEvent("test-subspace", cip.DefaultSubspaceOps, "", "Test Subspace", "")
+	assert.NoError(t, err)
+	createEvent.Rules = "energy>>1000"
+	createEvent.SubspaceID = calculateSubspaceID(createEvent.SubspaceName, createEvent.Ops, createEvent.Rules)
+	createEvent.Tags = Tags{
+		Tag{"d", cip.ObjectIdentifier(createEvent.SubspaceID, "")},
+		Tag{"sid", createEvent.SubspaceID},
+		Tag{"subspace_name", createEvent.SubspaceName},
+		Tag{"ops", createEvent.Ops},
+		Tag{"rules", createEvent.Rules},
+	}
+	assert.Error(t, ValidateSubspaceCreateEvent(createEvent))
+
+	_, err = ParseSubspaceCreateEvent(createEvent.Event)
+	assert.Error(t, err)
+}
+
+func TestSubspaceIdentifiers(t *testing.T) {
+	createEvent, err := NewSubspaceCreateEvent("test-subspace", cip.DefaultSubspaceOps, "", "Test Subspace", "")
+	assert.NoError(t, err)
+	joinEvent := NewSubspaceJoinEvent(createEvent.SubspaceID)
+
+	// creates and joins are addressed by subspace, so they no longer collide across subspaces
+	assert.Equal(t, createEvent.SubspaceID, createEvent.Tags.GetD())
+	assert.Equal(t, createEvent.SubspaceID, joinEvent.Tags.GetD())
+
+	// legacy d tags are still accepted
+	createEvent.Tags[0] = Tag{"d", cip.OpSubspaceCreate}
+	_, err = ParseSubspaceCreateEvent(createEvent.Event)
+	assert.NoError(t, err)
+	joinEvent.Tags[0] = Tag{"d", cip.OpSubspaceJoin}
+	_, err = ParseSubspaceJoinEvent(joinEvent.Event)
+	assert.NoError(t, err)
+
+	joinEvent.Tags[0] = Tag{"d", "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"}
+	_, err = ParseSubspaceJoinEvent(joinEvent.Event)
+	assert.Error(t, err)
+
+	op, _ := NewSubspaceOpEvent(createEvent.SubspaceID, cip.KindCommonGraphProject)
+	op.SetObjectID("proj_001")
+	op.SetObjectID("proj_002")
+	assert.Equal(t, 1, len(op.Tags.GetAll([]string{"d"})))
+	assert.Equal(t, "proj_002", op.ObjectID())
+	assert.NoError(t, op.Validate())
+
+	// append-only operations keep their unique d tag
+	vote, _ := NewSubspaceOpEvent(createEvent.SubspaceID, cip.KindGovernanceVote)
+	assert.NoError(t, vote.Validate())
+	vote.SetObjectID("prop_001")
+	assert.Error(t, vote.Validate())
+}
+
+func TestSubspaceCreateEventOps(t *testing.T) {
+	a, err := NewSubspaceCreateEvent("test", "vote=30302,post=30300", "", "Test Subspace", "")
+	assert.NoError(t, err)
+	b, err := NewSubspaceCreateEvent("test", "post=30300,vote=30302", "", "Test Subspace", "")
+	assert.NoError(t, err)
+	assert.Equal(t, a.SubspaceID, b.SubspaceID)
+	assert.Equal(t, "post=30300,vote=30302", a.Ops)
+	assert.Equal(t, "post=30300,vote=30302", a.Tags.Find("ops")[1])
+
+	spec, err := a.OpsSpec()
+	assert.NoError(t, err)
+	assert.True(t, spec.Allows(cip.KindGovernanceVote))
+
+	// subspaces created before canonicalization keep their id
+	legacy, err := NewSubspaceCreateEvent("test", "post=30300", "", "Test Subspace", "")
+	assert.NoError(t, err)
+	legacy.Ops = "vote=30302,post=30300"
+	legacy.SubspaceID = cip.LegacySubspaceID(legacy.SubspaceName, legacy.Ops, legacy.Rules)
+	legacy.Tags[0] = Tag{"d", cip.OpSubspaceCreate}
+	assert.NoError(t, ValidateSubspaceCreateEvent(legacy))
+
+	// ops must match the registered kinds
+	wrong, err := NewSubspaceCreateEvent("test", "post=30302", "", "Test Subspace", "")
+	assert.NoError(t, err)
+	assert.Error(t, ValidateSubspaceCreateEvent(wrong))
+}